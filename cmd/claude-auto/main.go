@@ -1,20 +1,32 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/nohdol/claude-auto/internal/core"
 	"github.com/nohdol/claude-auto/internal/docs"
+	"github.com/nohdol/claude-auto/internal/forge"
 	"github.com/nohdol/claude-auto/internal/generators"
 	"github.com/nohdol/claude-auto/internal/git"
+	"github.com/nohdol/claude-auto/internal/metrics"
+	"github.com/nohdol/claude-auto/internal/refactor"
+	"github.com/nohdol/claude-auto/internal/report"
 	"github.com/nohdol/claude-auto/internal/tasks"
+	"github.com/nohdol/claude-auto/internal/templates"
+	"github.com/nohdol/claude-auto/internal/validation"
 	"github.com/nohdol/claude-auto/pkg/types"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
@@ -35,6 +47,28 @@ var (
 	deployTarget string
 	verbose      bool
 	outputDir    string
+	presetName   string
+	undoRunID    string
+	undoTaskID   string
+	listRuns     bool
+	dirtyMode    string
+	openPR       bool
+	sinceRef     string
+	stagedOnly   bool
+	noCache      bool
+	noStatic     bool
+	packageName  string
+	reportFormat string
+	reportFile   string
+	failOn       string
+	minSeverity  string
+	issueTypes   []string
+	maxIssues    int
+	reproCommand string
+	traceFile    string
+	maxAttempts  int
+	refactorReq  refactor.Request
+	maxSteps     int
 )
 
 var rootCmd = &cobra.Command{
@@ -72,17 +106,21 @@ var improveCmd = &cobra.Command{
 var fixCmd = &cobra.Command{
 	Use:   "fix [path] [issue]",
 	Short: "Fix specific issues in a project",
-	Long:  `Fix specific bugs or issues in an existing project.`,
+	Long: `Fix a bug in an existing project. With --repro the failing command is run first to
+confirm the bug, and the fix is only committed once it passes and no other check regressed.`,
 	Args:  cobra.MinimumNArgs(1),
 	RunE:  runFix,
 }
 
 var refactorCmd = &cobra.Command{
-	Use:   "refactor [path]",
+	Use:   "refactor <operation> [path]",
 	Short: "Refactor code in a project",
-	Long:  `Refactor code to improve structure, readability, and maintainability.`,
-	Args:  cobra.MaximumNArgs(1),
-	RunE:  runRefactor,
+	Long: `Apply a named refactoring operation step by step. The build, checks and test results,
+and for Go the exported API, are recorded first; each step must leave them unchanged and is
+committed on its own, steps that change behavior are discarded. Run without arguments to list
+the operations.`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: runRefactor,
 }
 
 var addCmd = &cobra.Command{
@@ -93,6 +131,42 @@ var addCmd = &cobra.Command{
 	RunE:  runAdd,
 }
 
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Undo the changes of a run or a single task",
+	Long: `Undo the latest run, the run given by --run, or a single task of it given by --task.
+Unpushed runs are reset, pushed runs are reverted, and uncommitted work from before the run is restored.`,
+	Args: cobra.NoArgs,
+	RunE: runUndo,
+}
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Manage stack presets",
+	Long:  `List, inspect, and apply the built-in stack presets used by "idea --preset".`,
+}
+
+var templatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available stack presets",
+	Args:  cobra.NoArgs,
+	RunE:  runTemplatesList,
+}
+
+var templatesShowCmd = &cobra.Command{
+	Use:   "show [preset]",
+	Short: "Show the stack and files of a preset",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTemplatesShow,
+}
+
+var templatesApplyCmd = &cobra.Command{
+	Use:   "apply [preset] [path]",
+	Short: "Lay down a preset skeleton without running Claude",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE:  runTemplatesApply,
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
@@ -107,6 +181,64 @@ func init() {
 	ideaCmd.Flags().BoolVar(&skipTests, "skip-tests", false, "skip test generation")
 	ideaCmd.Flags().StringVarP(&deployTarget, "deploy", "d", "none", "deployment target")
 	ideaCmd.Flags().StringVarP(&outputDir, "output", "o", "./", "output directory for the project")
+	ideaCmd.Flags().StringVar(&presetName, "preset", "", fmt.Sprintf("stack preset to scaffold (%s)", strings.Join(templates.Names(), ", ")))
+
+	// Analyze command flags
+	analyzeCmd.Flags().StringVar(&sinceRef, "since", "", "analyze only files changed since this commit and their importers")
+	analyzeCmd.Flags().BoolVar(&stagedOnly, "staged", false, "analyze only staged files and their importers")
+	analyzeCmd.Flags().BoolVar(&noCache, "no-cache", false, "analyze every file again instead of reusing cached results")
+	analyzeCmd.Flags().BoolVar(&noStatic, "no-static", false, "skip the local static checks run before Claude")
+	analyzeCmd.MarkFlagsMutuallyExclusive("since", "staged")
+	analyzeCmd.Flags().StringVar(&reportFormat, "format", string(report.FormatText), "report format (text/json/sarif/junit/markdown)")
+	analyzeCmd.Flags().StringVarP(&reportFile, "output", "o", "", "write the report to a file instead of stdout")
+	analyzeCmd.Flags().StringVar(&failOn, "fail-on", "none", "exit with an error when an issue has this severity or higher (critical/high/medium/low/none)")
+
+	// Improve command flags
+	improveCmd.Flags().IntVarP(&workers, "workers", "w", 3, "number of parallel workers")
+	improveCmd.Flags().BoolVarP(&autoApprove, "auto-approve", "y", false, "fix every matching issue without asking")
+	improveCmd.Flags().StringVar(&minSeverity, "severity", "", "fix only issues with this severity or higher (critical/high/medium/low)")
+	improveCmd.Flags().StringSliceVar(&issueTypes, "type", nil, "fix only issues of these types (bug/security/performance/quality)")
+	improveCmd.Flags().IntVar(&maxIssues, "max-issues", 0, "fix at most this many issues, most severe first")
+
+	// Fix command flags
+	fixCmd.Flags().StringVar(&reproCommand, "repro", "", "command that fails while the bug is present, e.g. \"go test ./pkg -run TestX\"")
+	fixCmd.Flags().StringVar(&traceFile, "trace", "", "file with a stack trace of the bug, or - for stdin")
+	fixCmd.Flags().IntVar(&maxAttempts, "max-attempts", 3, "how often Claude may try to fix the bug")
+
+	// Refactor command flags
+	refactorCmd.Flags().StringVar(&refactorReq.File, "file", "", "file to refactor, relative to the project")
+	refactorCmd.Flags().StringVar(&refactorReq.Lines, "lines", "", "line range to extract, e.g. 40-75")
+	refactorCmd.Flags().StringVar(&refactorReq.Symbol, "symbol", "", "function to extract from, or symbol to rename")
+	refactorCmd.Flags().StringVar(&refactorReq.To, "to", "", "new name of the renamed symbol")
+	refactorCmd.Flags().StringVar(&refactorReq.Name, "name", "", "name of the extracted function")
+	refactorCmd.Flags().StringVar(&refactorReq.Dir, "dir", "", "directory to limit the refactoring to, relative to the project")
+	refactorCmd.Flags().IntVar(&maxSteps, "max-steps", 5, "most steps of a planned refactoring")
+	refactorCmd.Flags().BoolVarP(&autoApprove, "auto-approve", "y", false, "apply the plan without confirmation")
+
+	// Commands understanding monorepos can target one workspace package
+	for _, c := range []*cobra.Command{analyzeCmd, improveCmd, addCmd} {
+		c.Flags().StringVar(&packageName, "package", "", "workspace package to target, by name or path")
+	}
+
+	// Commands editing a project set the user's uncommitted work aside first
+	for _, c := range []*cobra.Command{ideaCmd, improveCmd, fixCmd, refactorCmd, addCmd, undoCmd} {
+		c.Flags().StringVar(&dirtyMode, "dirty", string(git.DirtyRefuse), "uncommitted changes in the project (refuse/stash/snapshot)")
+	}
+
+	// Commands working on a feature branch can open a pull request for it
+	for _, c := range []*cobra.Command{ideaCmd, addCmd} {
+		c.Flags().BoolVar(&openPR, "pr", false, "push the feature branch and open a pull request")
+	}
+
+	// Undo command flags
+	undoCmd.Flags().StringVar(&undoRunID, "run", "", "run to undo (default is the latest run)")
+	undoCmd.Flags().StringVar(&undoTaskID, "task", "", "undo only the commits of this task")
+	undoCmd.Flags().BoolVar(&listRuns, "list", false, "list recorded runs")
+	undoCmd.Flags().BoolVarP(&autoApprove, "auto-approve", "y", false, "auto approve without confirmation")
+
+	templatesCmd.AddCommand(templatesListCmd)
+	templatesCmd.AddCommand(templatesShowCmd)
+	templatesCmd.AddCommand(templatesApplyCmd)
 
 	rootCmd.AddCommand(ideaCmd)
 	rootCmd.AddCommand(analyzeCmd)
@@ -114,6 +246,8 @@ func init() {
 	rootCmd.AddCommand(fixCmd)
 	rootCmd.AddCommand(refactorCmd)
 	rootCmd.AddCommand(addCmd)
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(templatesCmd)
 }
 
 func initConfig() {
@@ -151,6 +285,16 @@ func runIdea(cmd *cobra.Command, args []string) error {
 		cfg.Parallel.MaxWorkers = workers
 	}
 
+	// Resolve the stack preset before any Claude call
+	var preset *templates.Preset
+	if presetName != "" {
+		p, exists := templates.Get(presetName)
+		if !exists {
+			return fmt.Errorf("unknown preset %q (available: %s)", presetName, strings.Join(templates.Names(), ", "))
+		}
+		preset = p
+	}
+
 	// Create context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -181,30 +325,6 @@ func runIdea(cmd *cobra.Command, args []string) error {
 		projectDir = filepath.Join(cwd, outputDir)
 	}
 
-	// Check if the directory is empty (except for .git and other hidden files)
-	entries, err := os.ReadDir(projectDir)
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to read directory: %w", err)
-	}
-
-	// Count non-hidden files
-	nonHiddenCount := 0
-	for _, entry := range entries {
-		if !strings.HasPrefix(entry.Name(), ".") {
-			nonHiddenCount++
-		}
-	}
-
-	if nonHiddenCount > 0 {
-		logger.Warn().Str("dir", projectDir).Msg("Directory is not empty")
-		fmt.Printf("⚠️  Directory %s is not empty. Continue anyway? (y/n): ", projectDir)
-		var response string
-		fmt.Scanln(&response)
-		if response != "y" && response != "Y" {
-			return fmt.Errorf("directory not empty: %s", projectDir)
-		}
-	}
-
 	// Create directory if it doesn't exist
 	if err := os.MkdirAll(projectDir, 0755); err != nil {
 		return fmt.Errorf("failed to create project directory: %w", err)
@@ -227,11 +347,53 @@ func runIdea(cmd *cobra.Command, args []string) error {
 		logger,
 	)
 
+	if cfg.Validation.Enabled {
+		parallelExecutor.SetValidator(newValidator(projectDir, cfg, logger), cfg.Validation.MaxFixAttempts)
+	}
+
 	gitManager, err := git.NewGitManager(projectDir, cfg.Git.CommitSize, logger)
 	if err != nil {
 		return fmt.Errorf("failed to initialize Git manager: %w", err)
 	}
-	gitManager.SetAuthor(cfg.Git.AuthorName, cfg.Git.AuthorEmail)
+	if err := configureGit(gitManager, cfg, claudeExecutor); err != nil {
+		return err
+	}
+
+	// Set uncommitted work aside before Claude is asked anything; what is left
+	// in the directory is committed and only needs the user's consent
+	if err := protectWorkingTree(gitManager, "idea"); err != nil {
+		return err
+	}
+
+	// Check if the directory is empty (except for .git and other hidden files)
+	entries, err := os.ReadDir(projectDir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	// Count non-hidden files
+	nonHiddenCount := 0
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), ".") {
+			nonHiddenCount++
+		}
+	}
+
+	if nonHiddenCount > 0 {
+		logger.Warn().Str("dir", projectDir).Msg("Directory is not empty")
+		fmt.Printf("⚠️  Directory %s is not empty. Continue anyway? (y/n): ", projectDir)
+		var response string
+		fmt.Scanln(&response)
+		if response != "y" && response != "Y" {
+			return fmt.Errorf("directory not empty: %s", projectDir)
+		}
+	}
+
+	parallelExecutor.SetWorkDir(projectDir)
+	if cfg.Git.AutoCommit {
+		parallelExecutor.SetGitManager(gitManager, cfg.Git.CommitSize)
+		parallelExecutor.SetWorktrees(cfg.Parallel.Worktrees)
+	}
 
 	docGenerator := docs.NewDocGenerator(
 		filepath.Join(projectDir, cfg.Docs.OutputDir),
@@ -240,10 +402,13 @@ func runIdea(cmd *cobra.Command, args []string) error {
 	)
 
 	ideaProcessor := generators.NewIdeaProcessor(claudeExecutor, taskManager, logger)
+	if preset != nil {
+		ideaProcessor.SetPreset(preset)
+	}
 
 	// Process the idea
 	fmt.Println("\n🤔 Analyzing your idea with AI...")
-	fmt.Println("⏳ Generating project plan...\n")
+	fmt.Print("⏳ Generating project plan...\n\n")
 	processedIdea, err := ideaProcessor.ProcessIdea(ctx, idea)
 	if err != nil {
 		return fmt.Errorf("failed to process idea: %w", err)
@@ -263,7 +428,37 @@ func runIdea(cmd *cobra.Command, args []string) error {
 		}
 		// Show progress message after approval
 		fmt.Println("\n🚀 Starting project generation...")
-		fmt.Println("⏳ This may take a few minutes. Please wait...\n")
+		fmt.Print("⏳ This may take a few minutes. Please wait...\n\n")
+	}
+
+	run := startRun(gitManager, "idea", args, logger)
+	defer finishRun(gitManager, run, logger)
+
+	if err := startFeatureBranch(gitManager, cfg, processedIdea.Name); err != nil {
+		return fmt.Errorf("failed to start feature branch: %w", err)
+	}
+
+	// Lay down the preset skeleton locally before any task runs
+	if preset != nil {
+		fmt.Printf("🧱 Scaffolding %s preset...\n", preset.Name)
+		written, err := templates.Scaffold(projectDir, preset, templates.TemplateData{
+			ProjectName: processedIdea.Name,
+			Description: processedIdea.Description,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to scaffold preset: %w", err)
+		}
+		logger.Info().
+			Str("preset", preset.Name).
+			Int("files", len(written)).
+			Msg("Preset skeleton created")
+
+		if cfg.Git.AutoCommit {
+			message := fmt.Sprintf("chore: scaffold %s preset", preset.Name)
+			if err := gitManager.BatchCommit(written, types.TaskTypeDevOps, message); err != nil {
+				logger.Warn().Err(err).Msg("Failed to commit preset skeleton")
+			}
+		}
 	}
 
 	// Execute tasks
@@ -274,10 +469,28 @@ func runIdea(cmd *cobra.Command, args []string) error {
 		logger.Error().Err(err).Msg("Task execution failed")
 	}
 
+	pushRun(gitManager, cfg, logger)
+
+	if openPR || cfg.Forge.PullRequest {
+		openPullRequest(ctx, gitManager, cfg, run, forge.RunPullRequest(processedIdea, report), logger)
+	}
+
 	// Generate documentation
 	if cfg.Docs.Generate {
 		fmt.Println("📝 Generating documentation...")
 		progressDoc := createProgressDocument(report, processedIdea)
+
+		// Collect real metrics and compare them with the previous run
+		current := metrics.NewCollector(projectDir, gitManager, logger).Collect()
+		previous, err := docGenerator.LatestMetrics()
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to load previous metrics")
+		}
+		docs.ApplyMetrics(progressDoc, current, previous)
+		if err := docGenerator.UpdateMetrics(current); err != nil {
+			logger.Error().Err(err).Msg("Failed to store metrics")
+		}
+
 		if err := docGenerator.GenerateProgressReport(progressDoc); err != nil {
 			logger.Error().Err(err).Msg("Failed to generate progress report")
 		}
@@ -448,9 +661,6 @@ func createProgressDocument(report *types.ExecutionReport, idea *types.Processed
 		CompletedTasks:  completedTasks,
 		InProgressTasks: inProgressTasks,
 		Progress:        progress,
-		LinesOfCode:     0, // Would need actual counting
-		CommitCount:     0, // Would need Git integration
-		TestCoverage:    0, // Would need test results
 		APIKeys:         apiKeys,
 		NextSteps: []string{
 			"Run tests",
@@ -469,6 +679,10 @@ func displaySummary(report *types.ExecutionReport, projectDir string, logger zer
 	fmt.Printf("  - Failed: %d\n", report.FailedTasks)
 	fmt.Printf("  - Duration: %s\n", report.Duration)
 
+	if report.Validation != nil {
+		displayValidation(report.Validation)
+	}
+
 	fmt.Println("\n🚀 Next steps:")
 	fmt.Printf("  1. cd %s\n", projectDir)
 	fmt.Println("  2. Review generated code")
@@ -477,241 +691,961 @@ func displaySummary(report *types.ExecutionReport, projectDir string, logger zer
 	fmt.Println("  5. Deploy to production")
 }
 
-func runAnalyze(cmd *cobra.Command, args []string) error {
-	// Get project path
-	projectPath := "./"
-	if len(args) > 0 {
-		projectPath = args[0]
+func displayValidation(result *types.ValidationResult) {
+	if result.Passed {
+		fmt.Println("\n✅ Validation gates passed:")
+	} else {
+		fmt.Println("\n❌ Validation gates failed:")
 	}
+	for _, check := range result.Checks {
+		status := "✅"
+		if !check.Passed {
+			status = "❌"
+		} else if check.AutoFixed {
+			status = "🔧"
+		}
+		fmt.Printf("  %s %s\n", status, check.Name)
+	}
+}
 
-	// Setup logger
-	logger := zerolog.New(os.Stderr).With().Timestamp().Logger()
-
-	// Create context
-	ctx := context.Background()
+// newValidator creates the validation gates for a project directory
+func newValidator(projectDir string, cfg *core.Config, logger zerolog.Logger) *validation.Validator {
+	timeout, err := time.ParseDuration(cfg.Validation.Timeout)
+	if err != nil {
+		timeout = 5 * time.Minute
+	}
+	return validation.NewValidator(projectDir, timeout, logger)
+}
 
-	// Initialize components
-	claudeExecutor := core.NewClaudeExecutor(logger)
-	defer claudeExecutor.Cleanup()
+// startFeatureBranch moves a run onto its claude-auto/<feature> branch
+// when git.branch_strategy is feature
+func startFeatureBranch(gitManager *git.GitManager, cfg *core.Config, feature string) error {
+	if cfg.Git.BranchStrategy != "feature" {
+		return nil
+	}
 
-	analyzer := generators.NewProjectAnalyzer(claudeExecutor, logger)
+	// A branch needs a commit to start from
+	if err := gitManager.EnsureInitialCommit(); err != nil {
+		return err
+	}
 
-	// Analyze project
-	logger.Info().Str("path", projectPath).Msg("Analyzing project...")
-	info, err := analyzer.AnalyzeProject(ctx, projectPath)
-	if err != nil {
-		return fmt.Errorf("analysis failed: %w", err)
+	branch := git.FeatureBranch(feature)
+	if current, err := gitManager.GetCurrentBranch(); err == nil && current == branch {
+		return nil
 	}
 
-	// Display results
-	fmt.Println("\n📊 Project Analysis Report")
-	fmt.Println("=" + strings.Repeat("=", 50))
-	fmt.Printf("Project Type: %s\n", info.Type)
-	fmt.Printf("Language: %s\n", info.Language)
-	fmt.Printf("Framework: %s\n", info.Framework)
-
-	if len(info.Issues) > 0 {
-		fmt.Println("\n🐛 Issues Found:")
-		for _, issue := range info.Issues {
-			emoji := "⚠️"
-			if issue.Severity == "critical" {
-				emoji = "🔴"
-			} else if issue.Severity == "high" {
-				emoji = "🟠"
-			}
-			fmt.Printf("%s [%s] %s\n", emoji, issue.Type, issue.Description)
-			if issue.Suggestion != "" {
-				fmt.Printf("   💡 %s\n", issue.Suggestion)
-			}
+	if gitManager.HasBranch(branch) {
+		if err := gitManager.CheckoutBranch(branch); err != nil {
+			return err
 		}
+	} else if err := gitManager.CreateBranch(branch, ""); err != nil {
+		return err
 	}
 
-	if len(info.Improvements) > 0 {
-		fmt.Println("\n💡 Suggested Improvements:")
-		for _, improvement := range info.Improvements {
-			fmt.Printf("  - %s\n", improvement)
-		}
+	fmt.Printf("🌿 Working on branch %s\n", branch)
+	return nil
+}
+
+// configureGit applies the identity, signing, trailer, commit message and push settings.
+// Without a Claude executor commit messages stay template messages.
+func configureGit(gitManager *git.GitManager, cfg *core.Config, claudeExecutor *core.ClaudeExecutor) error {
+	gitManager.SetAuthor(cfg.Git.AuthorName, cfg.Git.AuthorEmail)
+	gitManager.SetCommitter(cfg.Git.CommitterName, cfg.Git.CommitterEmail)
+	gitManager.SetModel(cfg.Claude.Model)
+
+	if err := gitManager.SetTrailers(cfg.Git.Trailers); err != nil {
+		return err
+	}
+
+	err := gitManager.SetSigning(git.SigningConfig{
+		Format:        git.SigningFormat(cfg.Git.SigningFormat),
+		KeyPath:       cfg.Git.SigningKey,
+		PassphraseEnv: cfg.Git.SigningKeyPassphraseEnv,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set up commit signing: %w", err)
+	}
+
+	if git.CommitMessageMode(cfg.Git.CommitMessage) == git.CommitMessageAI && claudeExecutor != nil {
+		gitManager.SetMessageWriter(git.NewClaudeMessageWriter(claudeExecutor, gitManager.GetProjectDir()))
 	}
 
+	gitManager.SetPushConfig(git.PushConfig{
+		Strategy:            git.PushStrategy(cfg.Git.PushStrategy),
+		Remote:              cfg.Git.RemoteName,
+		ProtectedBranches:   cfg.Git.ProtectedBranches,
+		Retries:             cfg.Git.PushRetries,
+		SSHKeyPath:          cfg.Git.SSHKeyPath,
+		SSHKeyPassphraseEnv: cfg.Git.SSHKeyPassphraseEnv,
+		TokenEnv:            cfg.Git.TokenEnv,
+	})
 	return nil
 }
 
-func runImprove(cmd *cobra.Command, args []string) error {
-	// Get project path
-	projectPath := "./"
-	if len(args) > 0 {
-		projectPath = args[0]
+// pushRun pushes the commits of a run when git.push_strategy is batch
+func pushRun(gitManager *git.GitManager, cfg *core.Config, logger zerolog.Logger) {
+	if git.PushStrategy(cfg.Git.PushStrategy) != git.PushBatch || !gitManager.HasRemote(cfg.Git.RemoteName) {
+		return
 	}
 
-	// Setup logger
-	logger := zerolog.New(os.Stderr).With().Timestamp().Logger()
+	fmt.Println("⬆️  Pushing commits...")
+	if err := gitManager.PushCurrentBranch(); err != nil {
+		logger.Warn().Err(err).Msg("Failed to push commits")
+		fmt.Printf("⚠️  Push failed: %v\n", err)
+	}
+}
 
-	// First analyze the project
-	ctx := context.Background()
-	claudeExecutor := core.NewClaudeExecutor(logger)
-	defer claudeExecutor.Cleanup()
+// openPullRequest pushes the feature branch of a run and opens a pull request
+// into the branch the run started from
+func openPullRequest(ctx context.Context, gitManager *git.GitManager, cfg *core.Config, run *git.RunRecord, pr *forge.PullRequest, logger zerolog.Logger) {
+	if cfg.Git.BranchStrategy != "feature" {
+		fmt.Println("⚠️  Pull requests need git.branch_strategy: feature, skipping")
+		return
+	}
 
-	analyzer := generators.NewProjectAnalyzer(claudeExecutor, logger)
-	info, err := analyzer.AnalyzeProject(ctx, projectPath)
+	head, err := gitManager.GetCurrentBranch()
 	if err != nil {
-		return fmt.Errorf("analysis failed: %w", err)
+		logger.Warn().Err(err).Msg("Failed to get current branch")
+		return
+	}
+	base := cfg.Git.DefaultBranch
+	if run != nil && run.StartBranch != "" && run.StartBranch != head {
+		base = run.StartBranch
+	}
+	if head == base {
+		fmt.Printf("⚠️  Already on %s, skipping pull request\n", base)
+		return
 	}
 
-	// Ask for confirmation
-	fmt.Printf("\n🔧 Found %d issues to fix. Proceed with improvements? (y/n): ", len(info.Issues))
-	var response string
-	fmt.Scanln(&response)
-	if response != "y" && response != "Y" {
-		return nil
+	remoteURL, err := gitManager.RemoteURL(cfg.Git.RemoteName)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Cannot open pull request without a remote")
+		fmt.Printf("⚠️  Pull request skipped: %v\n", err)
+		return
 	}
 
-	// Create improvement tasks
-	taskManager := tasks.NewTaskManager(logger)
+	f, err := forge.New(forge.Config{
+		Provider: forge.Provider(cfg.Forge.Provider),
+		APIURL:   cfg.Forge.APIURL,
+		Token:    forge.TokenFromEnv(cfg.Forge.TokenEnv, cfg.Git.TokenEnv),
+	}, remoteURL)
+	if errors.Is(err, forge.ErrNoToken) {
+		fmt.Printf("⚠️  Pull request skipped: set the API token in $%s\n", firstNonEmpty(cfg.Forge.TokenEnv, cfg.Git.TokenEnv))
+		return
+	}
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to set up forge")
+		fmt.Printf("⚠️  Pull request skipped: %v\n", err)
+		return
+	}
 
-	for i, issue := range info.Issues {
-		prompt := fmt.Sprintf(`Fix the following issue:
-Type: %s
-Severity: %s
-File: %s
-Description: %s
-Suggestion: %s
+	if err := gitManager.Push(cfg.Git.RemoteName, head); err != nil {
+		logger.Warn().Err(err).Msg("Failed to push feature branch")
+		fmt.Printf("⚠️  Pull request skipped, push failed: %v\n", err)
+		return
+	}
 
-Please provide the fixed code.`, issue.Type, issue.Severity, issue.File, issue.Description, issue.Suggestion)
+	existing, err := f.FindPullRequest(ctx, head, base)
+	if err != nil {
+		logger.Warn().Err(err).Str("forge", f.Name()).Msg("Failed to look up pull requests")
+	}
+	if existing != nil {
+		fmt.Printf("🔗 Pull request #%d already open, updated with the new commits: %s\n", existing.Number, existing.URL)
+		return
+	}
 
-		task := taskManager.CreateTask(
-			types.TaskTypeTesting, // Using Testing for fixes
-			i,
-			prompt,
-		)
-		_ = task
+	pr.Head = head
+	pr.Base = base
+	pr.Draft = cfg.Forge.Draft
+	pr.Labels = append(pr.Labels, cfg.Forge.Labels...)
+	if run != nil {
+		pr.Body += fmt.Sprintf("\n---\nclaude-auto run `%s` (undo with `claude-auto undo --run %s`)\n", run.ID, run.ID)
 	}
 
-	logger.Info().Int("issues", len(info.Issues)).Msg("Improvement tasks created")
-	fmt.Println("✅ Improvements applied successfully!")
+	info, err := f.CreatePullRequest(ctx, pr)
+	if err != nil {
+		logger.Warn().Err(err).Str("forge", f.Name()).Msg("Failed to open pull request")
+		if info == nil {
+			fmt.Printf("⚠️  Failed to open pull request: %v\n", err)
+			return
+		}
+	}
+	fmt.Printf("🔗 Opened pull request #%d: %s\n", info.Number, info.URL)
+}
 
-	return nil
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
 }
 
-func runFix(cmd *cobra.Command, args []string) error {
-	projectPath := "./"
-	issue := ""
+// startRun records the start of a run so "claude-auto undo" can return to it
+func startRun(gitManager *git.GitManager, command string, args []string, logger zerolog.Logger) *git.RunRecord {
+	run, err := gitManager.StartRun(command, args)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to record run, undo is unavailable")
+		return nil
+	}
+	fmt.Printf("🔖 Run %s recorded (revert with: claude-auto undo --run %s)\n", run.ID, run.ID)
+	return run
+}
 
-	if len(args) > 0 {
-		projectPath = args[0]
+// finishRun records the commits a run made
+func finishRun(gitManager *git.GitManager, run *git.RunRecord, logger zerolog.Logger) {
+	if run == nil {
+		return
 	}
-	if len(args) > 1 {
-		issue = strings.Join(args[1:], " ")
+	if err := gitManager.FinishRun(run); err != nil {
+		logger.Warn().Err(err).Str("run_id", run.ID).Msg("Failed to record run commits")
 	}
+}
 
-	if issue == "" {
-		return fmt.Errorf("please specify the issue to fix")
+// startProjectRun opens the repository of a project, sets uncommitted work aside
+// and records the start of a run
+func startProjectRun(projectPath string, cfg *core.Config, claudeExecutor *core.ClaudeExecutor, command string, args []string, logger zerolog.Logger) (*git.GitManager, *git.RunRecord, error) {
+	gitManager, err := git.NewGitManager(projectPath, cfg.Git.CommitSize, logger)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Git manager initialization failed, undo is unavailable")
+		return nil, nil, nil
+	}
+	if err := configureGit(gitManager, cfg, claudeExecutor); err != nil {
+		return nil, nil, err
 	}
 
-	logger := zerolog.New(os.Stderr).With().Timestamp().Logger()
-	ctx := context.Background()
-
-	claudeExecutor := core.NewClaudeExecutor(logger)
-	defer claudeExecutor.Cleanup()
-
-	// Create fix prompt
-	prompt := fmt.Sprintf(`Fix the following issue in the project at %s:
-%s
+	if err := protectWorkingTree(gitManager, command); err != nil {
+		return nil, nil, err
+	}
 
-Provide the complete solution with code changes.`, projectPath, issue)
+	return gitManager, startRun(gitManager, command, args, logger), nil
+}
 
-	options := &core.ClaudeOptions{
-		Role: "bug-fixer",
+// protectWorkingTree sets the user's uncommitted work aside according to --dirty
+// so it never ends up in the commits of a run
+func protectWorkingTree(gitManager *git.GitManager, command string) error {
+	mode, err := git.ParseDirtyMode(dirtyMode)
+	if err != nil {
+		return err
 	}
 
-	response, err := claudeExecutor.Execute(ctx, prompt, options)
+	work, err := gitManager.ProtectWorkingTree(mode, command)
+	if errors.Is(err, git.ErrDirtyWorktree) {
+		return fmt.Errorf("%w\n  commit or stash your changes, or rerun with --dirty=stash or --dirty=snapshot", err)
+	}
 	if err != nil {
-		return fmt.Errorf("fix failed: %w", err)
+		return fmt.Errorf("failed to set uncommitted changes aside: %w", err)
 	}
 
-	fmt.Println("\n🔧 Fix Applied:")
-	fmt.Println(response.Output)
-
+	if work != nil && work.Stash != "" {
+		fmt.Printf("📦 Stashed %d uncommitted files (restore with: git stash pop)\n", len(work.Files))
+	}
+	if work != nil && work.Branch != "" {
+		fmt.Printf("📸 Saved %d uncommitted files on branch %s\n", len(work.Files), work.Branch)
+	}
 	return nil
 }
 
-func runRefactor(cmd *cobra.Command, args []string) error {
+func runAnalyze(cmd *cobra.Command, args []string) error {
+	// Get project path
 	projectPath := "./"
 	if len(args) > 0 {
 		projectPath = args[0]
 	}
 
+	format, err := report.ParseFormat(reportFormat)
+	if err != nil {
+		return err
+	}
+	threshold, err := report.ParseSeverity(failOn)
+	if err != nil {
+		return fmt.Errorf("invalid --fail-on: %w", err)
+	}
+
+	// Setup logger
 	logger := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	// Create context
 	ctx := context.Background()
 
+	// Initialize components
 	claudeExecutor := core.NewClaudeExecutor(logger)
 	defer claudeExecutor.Cleanup()
 
-	prompt := fmt.Sprintf(`Refactor the code in %s to improve:
-1. Code structure and organization
-2. Readability and maintainability
-3. Performance optimization
-4. Remove code duplication
-5. Apply SOLID principles
-
-Provide the refactored code with explanations.`, projectPath)
+	analyzer := newProjectAnalyzer(claudeExecutor, logger)
+	analyzer.SetCache(!noCache)
+	analyzer.SetCacheRoot(projectPath)
+	if noStatic {
+		analyzer.SetStaticChecks(false)
+	}
 
-	options := &core.ClaudeOptions{
-		Role: "code-refactorer",
+	// Target one workspace package
+	analyzePath, packageDir, err := targetPackage(analyzer, projectPath)
+	if err != nil {
+		return err
 	}
 
-	response, err := claudeExecutor.Execute(ctx, prompt, options)
+	// Analyze project, or only what changed
+	logger.Info().Str("path", analyzePath).Msg("Analyzing project...")
+	var info *generators.ProjectInfo
+	if sinceRef != "" || stagedOnly {
+		var changed []string
+		if changed, err = changedFiles(projectPath, logger); err != nil {
+			return err
+		}
+		info, err = analyzer.AnalyzeChanges(ctx, analyzePath, filesInPackage(changed, packageDir))
+	} else {
+		info, err = analyzer.AnalyzeProject(ctx, analyzePath)
+	}
 	if err != nil {
-		return fmt.Errorf("refactor failed: %w", err)
+		return fmt.Errorf("analysis failed: %w", err)
 	}
+	repoRelative(info, projectPath, packageDir)
 
-	fmt.Println("\n♻️ Refactoring Suggestions:")
-	fmt.Println(response.Output)
+	// Render the report
+	out := os.Stdout
+	if reportFile != "" {
+		file, err := os.Create(reportFile)
+		if err != nil {
+			return fmt.Errorf("failed to create report: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	tool := report.Tool{Name: "claude-auto", Version: version, URI: "https://github.com/nohdol/claude-auto"}
+	if err := report.Render(out, format, info, tool); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if reportFile != "" {
+		fmt.Fprintf(os.Stderr, "📄 %s report written to %s\n", format, reportFile)
+	}
+
+	// Gate CI on the most severe issues
+	if failing := report.AtLeast(info.Issues, threshold); len(failing) > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d issue(s) at or above %s severity", len(failing), threshold)
+	}
 
 	return nil
 }
 
-func runAdd(cmd *cobra.Command, args []string) error {
-	// Get feature name and description
-	featureName := args[0]
-	featureDescription := strings.Join(args[1:], " ")
+// changedFiles lists the files of a project changed since --since, or staged with --staged
+func changedFiles(projectPath string, logger zerolog.Logger) ([]string, error) {
+	if _, err := os.Stat(filepath.Join(projectPath, ".git")); err != nil {
+		return nil, fmt.Errorf("--since and --staged need a git repository at %s", projectPath)
+	}
 
-	// Get project path from current directory
-	projectPath, err := os.Getwd()
+	gitManager, err := git.NewGitManager(projectPath, types.CommitSizeSmall, logger)
 	if err != nil {
-		return fmt.Errorf("failed to get current directory: %w", err)
+		return nil, fmt.Errorf("failed to open repository: %w", err)
 	}
 
-	// Setup logger
-	logger := zerolog.New(os.Stderr).With().Timestamp().Logger()
+	if stagedOnly {
+		return gitManager.StagedFiles()
+	}
+	return gitManager.ChangedFiles(sinceRef)
+}
 
-	// Load configuration
-	cfg, err := core.LoadConfig(configFile)
+// targetPackage returns the directory of the --package workspace package and
+// its path relative to the project, or the project itself
+func targetPackage(analyzer *generators.ProjectAnalyzer, projectPath string) (string, string, error) {
+	if packageName == "" {
+		return projectPath, "", nil
+	}
+	pkg, err := analyzer.FindPackage(projectPath, packageName)
 	if err != nil {
-		cfg = core.GetDefaultConfig()
+		return "", "", err
 	}
+	return pkg.Path, pkg.Dir, nil
+}
 
-	// Create context
-	ctx := context.Background()
+// filesInPackage keeps the files below a package directory, relative to it
+func filesInPackage(files []string, dir string) []string {
+	if dir == "" {
+		return files
+	}
+	in := []string{}
+	for _, file := range files {
+		if rel, ok := strings.CutPrefix(filepath.ToSlash(file), dir+"/"); ok {
+			in = append(in, rel)
+		}
+	}
+	return in
+}
 
-	// Initialize components
-	claudeExecutor := core.NewClaudeExecutor(logger)
+// repoRelative makes the issue files of a package analysis relative to the
+// project and roots the report there, so reports resolve them from the
+// repository root
+func repoRelative(info *generators.ProjectInfo, projectPath, packageDir string) {
+	if packageDir == "" {
+		return
+	}
+	info.Path = projectPath
+	for i := range info.Issues {
+		if file := info.Issues[i].File; file != "" && !filepath.IsAbs(file) {
+			info.Issues[i].File = path.Join(packageDir, filepath.ToSlash(file))
+		}
+	}
+}
+
+// newProjectAnalyzer creates a project analyzer with the analysis limits of the configuration
+func newProjectAnalyzer(claudeExecutor *core.ClaudeExecutor, logger zerolog.Logger) *generators.ProjectAnalyzer {
+	cfg, err := core.LoadConfig(configFile)
+	if err != nil {
+		cfg = core.GetDefaultConfig()
+	}
+
+	analyzer := generators.NewProjectAnalyzer(claudeExecutor, logger)
+	analyzer.SetMaxWorkers(cfg.Parallel.MaxWorkers)
+	analyzer.SetIndexOptions(generators.IndexOptions{
+		ChunkTokens:  cfg.Analysis.ChunkTokens,
+		MaxChunks:    cfg.Analysis.MaxChunks,
+		MaxFileBytes: cfg.Analysis.MaxFileBytes,
+	})
+	analyzer.SetStaticChecks(cfg.Analysis.StaticChecks)
+	return analyzer
+}
+
+func runImprove(cmd *cobra.Command, args []string) error {
+	// Get project path
+	projectPath := "./"
+	if len(args) > 0 {
+		projectPath = args[0]
+	}
+
+	// Setup logger
+	logger := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	cfg, err := core.LoadConfig(configFile)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to load config, using defaults")
+		cfg = core.GetDefaultConfig()
+	}
+	if workers > 0 {
+		cfg.Parallel.MaxWorkers = workers
+	}
+
+	filter := generators.IssueFilter{
+		Severity:  strings.ToLower(strings.TrimSpace(minSeverity)),
+		MaxIssues: maxIssues,
+	}
+	for _, issueType := range issueTypes {
+		filter.Types = append(filter.Types, strings.ToLower(strings.TrimSpace(issueType)))
+	}
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project path: %w", err)
+	}
+
+	ctx := context.Background()
+	claudeExecutor := core.NewClaudeExecutor(logger)
+	defer claudeExecutor.Cleanup()
+
+	// Refuse or set aside uncommitted work before spending time on the analysis
+	gitManager, run, err := startProjectRun(absPath, cfg, claudeExecutor, "improve", args, logger)
+	if err != nil {
+		return err
+	}
+	if gitManager == nil {
+		return fmt.Errorf("improve needs a git repository to commit its fixes")
+	}
+	defer finishRun(gitManager, run, logger)
+
+	// First analyze the project
+	analyzer := newProjectAnalyzer(claudeExecutor, logger)
+	analyzer.SetCacheRoot(projectPath)
+	analyzePath, packageDir, err := targetPackage(analyzer, projectPath)
+	if err != nil {
+		return err
+	}
+	info, err := analyzer.AnalyzeProject(ctx, analyzePath)
+	if err != nil {
+		return fmt.Errorf("analysis failed: %w", err)
+	}
+
+	selected, filtered := filter.Filter(info.Issues)
+	fmt.Printf("\n🔍 Found %d issues, %d match the filters\n", len(info.Issues), len(selected))
+	if len(selected) == 0 {
+		return nil
+	}
+
+	// Let the user pick the issues to fix
+	var deselected []generators.Issue
+	if !autoApprove {
+		selected, deselected = selectIssues(selected)
+		if len(selected) == 0 {
+			fmt.Println("No issues selected")
+			return nil
+		}
+	}
+
+	// Fix tasks run in parallel, one per file; each fix must keep the build and
+	// tests passing, is committed on its own and is discarded when it fails
+	// Without worktrees fixes share the project directory, where one fix would be
+	// validated against the unfinished edits of another, so they run one at a time
+	// Worktrees are merged back through commits, so they need auto_commit
+	worktrees := cfg.Parallel.Worktrees && cfg.Git.AutoCommit
+	maxWorkers := cfg.Parallel.MaxWorkers
+	if cfg.Validation.Enabled && !worktrees {
+		maxWorkers = 1
+	}
+
+	taskManager := tasks.NewTaskManager(logger)
+	parallelExecutor := tasks.NewParallelExecutor(taskManager, claudeExecutor, maxWorkers, logger)
+	if cfg.Validation.Enabled {
+		validateDir, err := filepath.Abs(analyzePath)
+		if err != nil {
+			return fmt.Errorf("failed to resolve package path: %w", err)
+		}
+		parallelExecutor.SetValidator(newValidator(validateDir, cfg, logger), cfg.Validation.MaxFixAttempts)
+	}
+	parallelExecutor.SetWorkDir(absPath)
+	parallelExecutor.SetGitManager(gitManager, cfg.Git.CommitSize)
+	parallelExecutor.SetAutoCommit(cfg.Git.AutoCommit)
+	parallelExecutor.SetWorktrees(worktrees)
+	parallelExecutor.SetDiscardFailed(true)
+
+	fixer := generators.NewIssueFixer(taskManager, logger)
+	fixer.SetPackage(packageDir)
+	fixer.Skip(filtered, "filtered out")
+	fixer.Skip(deselected, "not selected")
+	fixer.CreateTasks(selected)
+
+	fmt.Printf("\n🔧 Fixing %d issues...\n", len(selected))
+	if _, err := parallelExecutor.ExecuteTasks(ctx); err != nil {
+		return fmt.Errorf("failed to run fix tasks: %w", err)
+	}
+
+	displayFixResults(fixer.Results())
+	return nil
+}
+
+// selectIssues lists the issues and asks which to fix, returning the chosen
+// issues and the rest
+func selectIssues(issues []generators.Issue) (selected, rest []generators.Issue) {
+	fmt.Println()
+	for i, issue := range issues {
+		fmt.Printf("  %2d. [%s, %s] %s", i+1, issue.Severity, issue.Type, issue.Description)
+		if location := issue.Location(); location != "" {
+			fmt.Printf(" (%s)", location)
+		}
+		fmt.Println()
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print("\nIssues to fix (all, none, or numbers like 1,3-5) [all]: ")
+		line, _ := reader.ReadString('\n')
+		chosen, err := parseSelection(line, len(issues))
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			continue
+		}
+		for i, issue := range issues {
+			if chosen[i] {
+				selected = append(selected, issue)
+			} else {
+				rest = append(rest, issue)
+			}
+		}
+		return selected, rest
+	}
+}
+
+// parseSelection parses a selection of 1-based item numbers and ranges
+func parseSelection(input string, count int) (map[int]bool, error) {
+	chosen := make(map[int]bool)
+	input = strings.ToLower(strings.TrimSpace(input))
+	switch input {
+	case "", "all", "a", "y":
+		for i := 0; i < count; i++ {
+			chosen[i] = true
+		}
+		return chosen, nil
+	case "none", "n":
+		return chosen, nil
+	}
+
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection %q", part)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(strings.TrimSpace(to)); err != nil {
+				return nil, fmt.Errorf("invalid selection %q", part)
+			}
+		}
+		if first < 1 || last > count || first > last {
+			return nil, fmt.Errorf("selection %q is outside 1-%d", part, count)
+		}
+		for i := first; i <= last; i++ {
+			chosen[i-1] = true
+		}
+	}
+	return chosen, nil
+}
+
+// displayFixResults prints what happened to each issue improve considered
+func displayFixResults(results []generators.IssueFix) {
+	counts := make(map[generators.FixStatus]int)
+	for _, result := range results {
+		counts[result.Status]++
+	}
+
+	fmt.Printf("\n📊 Fix Results: %d fixed, %d failed, %d skipped\n",
+		counts[generators.FixStatusFixed], counts[generators.FixStatusFailed], counts[generators.FixStatusSkipped])
+
+	sections := []struct {
+		status generators.FixStatus
+		title  string
+	}{
+		{generators.FixStatusFixed, "✅ Fixed:"},
+		{generators.FixStatusFailed, "❌ Failed:"},
+		{generators.FixStatusSkipped, "⏭️  Skipped:"},
+	}
+	for _, section := range sections {
+		if counts[section.status] == 0 {
+			continue
+		}
+		fmt.Printf("\n%s\n", section.title)
+		for _, result := range results {
+			if result.Status != section.status {
+				continue
+			}
+			fmt.Printf("  - [%s] %s", result.Issue.Severity, result.Issue.Description)
+			if location := result.Issue.Location(); location != "" {
+				fmt.Printf(" (%s)", location)
+			}
+			if result.Reason != "" {
+				fmt.Printf(": %s", result.Reason)
+			}
+			fmt.Println()
+		}
+	}
+}
+
+func runFix(cmd *cobra.Command, args []string) error {
+	projectPath := "./"
+	issue := ""
+
+	if len(args) > 0 {
+		projectPath = args[0]
+	}
+	if len(args) > 1 {
+		issue = strings.Join(args[1:], " ")
+	}
+
+	report := &generators.BugReport{
+		Description: issue,
+		Repro:       strings.TrimSpace(reproCommand),
+	}
+	if traceFile != "" {
+		trace, err := readTrace(traceFile)
+		if err != nil {
+			return err
+		}
+		report.StackTrace = trace
+	}
+	if report.Description == "" && report.Repro == "" && report.StackTrace == "" {
+		return fmt.Errorf("please specify the issue to fix, a --repro command or a --trace")
+	}
+
+	logger := zerolog.New(os.Stderr).With().Timestamp().Logger()
+	ctx := context.Background()
+
+	cfg, err := core.LoadConfig(configFile)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to load config, using defaults")
+		cfg = core.GetDefaultConfig()
+	}
+
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project path: %w", err)
+	}
+
+	claudeExecutor := core.NewClaudeExecutor(logger)
+	defer claudeExecutor.Cleanup()
+
+	gitManager, run, err := startProjectRun(absPath, cfg, claudeExecutor, "fix", args, logger)
+	if err != nil {
+		return err
+	}
+	if gitManager == nil {
+		return fmt.Errorf("fix needs a git repository to verify and commit the fix")
+	}
+	defer finishRun(gitManager, run, logger)
+
+	bugFixer := generators.NewBugFixer(claudeExecutor, newValidator(absPath, cfg, logger), gitManager, logger)
+	bugFixer.SetMaxAttempts(maxAttempts)
+
+	if report.Repro != "" {
+		fmt.Printf("\n🔁 Reproducing: %s\n", report.Repro)
+	}
+	result, err := bugFixer.Fix(ctx, report)
+	if errors.Is(err, generators.ErrNotReproduced) {
+		fmt.Println("✅ The reproduction command already passes, nothing to fix")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("fix failed: %w", err)
+	}
+
+	fmt.Printf("\n🔧 Fix Attempts (checks: %s):\n", strings.Join(result.Checks, ", "))
+	for _, attempt := range result.Attempts {
+		status := "✅ verified"
+		if !attempt.ReproPassed {
+			status = "❌ repro still fails"
+		}
+		if len(attempt.Regressions) > 0 {
+			status += fmt.Sprintf(", ❌ regressed: %s", strings.Join(attempt.Regressions, ", "))
+		}
+		fmt.Printf("  %d. %s\n", attempt.Number, status)
+	}
+
+	if !result.Fixed {
+		fmt.Printf("\n❌ Not fixed after %d attempts; the changes were discarded\n", len(result.Attempts))
+		return fmt.Errorf("fix could not be verified")
+	}
+
+	fmt.Println("\n📝 Summary:")
+	fmt.Println(result.Summary)
+
+	if result.Changes.IsEmpty() {
+		fmt.Println("\n⚠️  The fix passed verification without changing any file")
+		return nil
+	}
+	for _, file := range result.Changes.Files() {
+		fmt.Printf("  ~ %s\n", file)
+	}
+
+	if cfg.Git.AutoCommit {
+		if err := gitManager.CommitWithTrailers(result.Changes.Files(), types.TaskTypeFix, fixCommitMessage(report)); err != nil {
+			return fmt.Errorf("failed to commit fix: %w", err)
+		}
+		fmt.Println("\n✅ Fix committed to Git")
+		pushRun(gitManager, cfg, logger)
+	}
+
+	return nil
+}
+
+// readTrace reads a stack trace from a file, or from stdin for "-"
+func readTrace(path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read stack trace: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// fixCommitMessage returns the commit message of a verified fix, or "" for
+// the template message when the bug has no description
+func fixCommitMessage(report *generators.BugReport) string {
+	summary, _, _ := strings.Cut(strings.TrimSpace(report.Description), "\n")
+	if summary == "" {
+		return ""
+	}
+	if runes := []rune(summary); len(runes) > 72 {
+		summary = string(runes[:72])
+	}
+	message := "fix: " + summary
+	if report.Repro != "" {
+		message += "\n\nVerified with: " + report.Repro
+	}
+	return message
+}
+
+func runRefactor(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		fmt.Println("♻️ Refactoring operations:")
+		for _, op := range refactor.List() {
+			fmt.Printf("  %-18s %s\n", op.Name, op.Description)
+		}
+		fmt.Println("\nUsage: claude-auto refactor <operation> [path]")
+		return nil
+	}
+
+	req := refactorReq
+	req.Operation = args[0]
+	op, err := req.Validate()
+	if err != nil {
+		return err
+	}
+
+	projectPath := "./"
+	if len(args) > 1 {
+		projectPath = args[1]
+	}
+
+	logger := zerolog.New(os.Stderr).With().Timestamp().Logger()
+	ctx := context.Background()
+
+	cfg, err := core.LoadConfig(configFile)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to load config, using defaults")
+		cfg = core.GetDefaultConfig()
+	}
+
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project path: %w", err)
+	}
+	for _, target := range []string{req.File, req.Dir} {
+		if target == "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(absPath, target)); err != nil {
+			return fmt.Errorf("%s not found in %s", target, absPath)
+		}
+	}
+
+	claudeExecutor := core.NewClaudeExecutor(logger)
+	defer claudeExecutor.Cleanup()
+
+	gitManager, run, err := startProjectRun(absPath, cfg, claudeExecutor, "refactor", args, logger)
+	if err != nil {
+		return err
+	}
+	if gitManager == nil {
+		return fmt.Errorf("refactor needs a git repository to commit each step")
+	}
+	defer finishRun(gitManager, run, logger)
+
+	refactorer := refactor.NewRefactorer(claudeExecutor, newValidator(absPath, cfg, logger), gitManager, logger)
+	refactorer.SetMaxSteps(maxSteps)
+
+	if op.Planned {
+		fmt.Printf("\n🗺️  Planning %s...\n", op.Name)
+	}
+	steps, err := refactorer.Plan(ctx, &req)
+	if err != nil {
+		return fmt.Errorf("refactor failed: %w", err)
+	}
+
+	fmt.Printf("\n♻️ Refactoring plan (%s):\n", op.Name)
+	for i, step := range steps {
+		fmt.Printf("  %d. %s\n", i+1, step.Title)
+		if step.Description != "" && step.Description != step.Title {
+			fmt.Printf("     %s\n", step.Description)
+		}
+	}
+
+	if !autoApprove {
+		fmt.Print("\nProceed? (y/n): ")
+		var response string
+		fmt.Scanln(&response)
+		if response != "y" && response != "Y" {
+			return nil
+		}
+	}
+
+	fmt.Println("\n🔍 Recording behavior before refactoring...")
+	result, err := refactorer.Apply(ctx, &req, steps)
+	if result != nil {
+		displayRefactorResult(result)
+	}
+	if err != nil {
+		return fmt.Errorf("refactor failed: %w", err)
+	}
+
+	if result.Applied() > 0 {
+		pushRun(gitManager, cfg, logger)
+	}
+	return nil
+}
+
+// displayRefactorResult shows what happened to each refactoring step
+func displayRefactorResult(result *refactor.Result) {
+	fmt.Println("\n📋 Refactoring Steps:")
+	for i, step := range result.Steps {
+		switch {
+		case step.Applied:
+			fmt.Printf("  ✅ %d. %s (committed, %d files)\n", i+1, step.Step.Title, len(step.Files))
+			for _, symbol := range step.APIAdded {
+				fmt.Printf("       + %s\n", symbol)
+			}
+		case len(step.Changes) > 0:
+			fmt.Printf("  ❌ %d. %s (discarded: %s)\n", i+1, step.Step.Title, step.Reason)
+			for _, change := range step.Changes {
+				fmt.Printf("       - %s\n", change)
+			}
+		default:
+			fmt.Printf("  ⏭️  %d. %s (%s)\n", i+1, step.Step.Title, step.Reason)
+		}
+	}
+	fmt.Printf("\n♻️ %d of %d steps applied\n", result.Applied(), len(result.Steps))
+}
+
+func runAdd(cmd *cobra.Command, args []string) error {
+	// Get feature name and description
+	featureName := args[0]
+	featureDescription := strings.Join(args[1:], " ")
+
+	// Get project path from current directory
+	projectPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	// Setup logger
+	logger := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	// Load configuration
+	cfg, err := core.LoadConfig(configFile)
+	if err != nil {
+		cfg = core.GetDefaultConfig()
+	}
+
+	// Create context
+	ctx := context.Background()
+
+	// Initialize components
+	claudeExecutor := core.NewClaudeExecutor(logger)
 	defer claudeExecutor.Cleanup()
 
 	taskManager := tasks.NewTaskManager(logger)
 	featureGenerator := generators.NewFeatureGenerator(claudeExecutor, taskManager, logger)
+	featureGenerator.SetPackage(packageName)
 
 	// Initialize Git manager if needed
 	gitManager, err := git.NewGitManager(projectPath, cfg.Git.CommitSize, logger)
 	if err != nil {
 		logger.Warn().Err(err).Msg("Git manager initialization failed, continuing without Git integration")
 	} else {
+		if err := configureGit(gitManager, cfg, claudeExecutor); err != nil {
+			return err
+		}
 		featureGenerator.SetGitManager(gitManager)
 	}
 
 	// Display feature plan
 	fmt.Printf("\n🚀 Adding new feature: %s\n", featureName)
 	fmt.Printf("📝 Description: %s\n", featureDescription)
-	fmt.Printf("📁 Project: %s\n\n", projectPath)
+	fmt.Printf("📁 Project: %s\n", projectPath)
+	if packageName != "" {
+		fmt.Printf("📦 Package: %s\n", packageName)
+	}
+	fmt.Println()
 
 	// Ask for confirmation
 	if !autoApprove {
@@ -723,6 +1657,20 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	var run *git.RunRecord
+	if gitManager != nil {
+		if err := protectWorkingTree(gitManager, "add"); err != nil {
+			return err
+		}
+
+		run = startRun(gitManager, "add", args, logger)
+		defer finishRun(gitManager, run, logger)
+
+		if err := startFeatureBranch(gitManager, cfg, featureName); err != nil {
+			return fmt.Errorf("failed to start feature branch: %w", err)
+		}
+	}
+
 	// Add the feature
 	logger.Info().Msg("Generating feature...")
 	result, err := featureGenerator.AddFeature(ctx, projectPath, featureName, featureDescription)
@@ -747,6 +1695,13 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if len(result.FilesDeleted) > 0 {
+		fmt.Println("\n🗑️  Files Deleted:")
+		for _, file := range result.FilesDeleted {
+			fmt.Printf("  - %s\n", file)
+		}
+	}
+
 	if len(result.TestsCreated) > 0 {
 		fmt.Println("\n🧪 Tests Created:")
 		for _, test := range result.TestsCreated {
@@ -755,24 +1710,44 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	}
 
 	// Save documentation
+	var docFiles []string
 	if result.Documentation != "" {
 		docPath := filepath.Join(projectPath, "docs", fmt.Sprintf("feature-%s.md", featureName))
 		if err := os.MkdirAll(filepath.Dir(docPath), 0755); err == nil {
 			if err := os.WriteFile(docPath, []byte(result.Documentation), 0644); err == nil {
 				fmt.Printf("\n📚 Documentation saved to: %s\n", docPath)
+				docFiles = append(docFiles, docPath)
 			}
 		}
 	}
 
 	// Commit changes if Git is available
 	if gitManager != nil && cfg.Git.AutoCommit {
-		allFiles := append(result.FilesCreated, result.FilesModified...)
-		if len(allFiles) > 0 {
-			if err := gitManager.SmartCommit(allFiles, types.TaskTypeFrontend); err != nil {
-				logger.Warn().Err(err).Msg("Failed to commit changes")
-			} else {
-				fmt.Println("\n✅ Changes committed to Git")
+		// One commit per task, typed after the task that made the changes
+		committed := false
+		for _, task := range result.Tasks {
+			if task.Changes.IsEmpty() {
+				continue
+			}
+			trailer := git.Trailer{Key: tasks.TrailerTaskID, Value: task.ID}
+			if err := gitManager.CommitWithTrailers(task.Changes.Files(), task.Type, "", trailer); err != nil {
+				logger.Warn().Err(err).Str("task", task.ID).Msg("Failed to commit changes")
+				continue
 			}
+			committed = true
+		}
+
+		if err := gitManager.SmartCommit(docFiles, types.TaskTypeDocumentation); err != nil {
+			logger.Warn().Err(err).Msg("Failed to commit documentation")
+		}
+
+		if committed {
+			fmt.Println("\n✅ Changes committed to Git")
+		}
+		pushRun(gitManager, cfg, logger)
+
+		if openPR || cfg.Forge.PullRequest {
+			openPullRequest(ctx, gitManager, cfg, run, forge.FeaturePullRequest(featureName, featureDescription, result), logger)
 		}
 	}
 
@@ -785,6 +1760,168 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runUndo(cmd *cobra.Command, args []string) error {
+	projectPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	logger := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	cfg, err := core.LoadConfig(configFile)
+	if err != nil {
+		cfg = core.GetDefaultConfig()
+	}
+
+	gitManager, err := git.NewGitManager(projectPath, cfg.Git.CommitSize, logger)
+	if err != nil {
+		return fmt.Errorf("failed to initialize Git manager: %w", err)
+	}
+	if err := configureGit(gitManager, cfg, nil); err != nil {
+		return err
+	}
+
+	if listRuns {
+		runs, err := gitManager.ListRuns()
+		if err != nil {
+			return err
+		}
+		displayRuns(runs)
+		return nil
+	}
+
+	run, err := gitManager.LoadRun(undoRunID)
+	if err != nil {
+		return err
+	}
+
+	if undoTaskID != "" {
+		fmt.Printf("\n↩️  Undoing task %s of run %s (%s, %d commits)\n", undoTaskID, run.ID, run.Command, len(run.Tasks[undoTaskID]))
+	} else {
+		fmt.Printf("\n↩️  Undoing run %s (%s, %d commits)\n", run.ID, run.Command, len(run.Commits))
+	}
+
+	if !autoApprove {
+		fmt.Print("Proceed? (y/n): ")
+		var response string
+		fmt.Scanln(&response)
+		if response != "y" && response != "Y" {
+			return nil
+		}
+	}
+
+	// Undoing resets the working tree, so the user's changes are set aside first
+	if err := protectWorkingTree(gitManager, "undo"); err != nil {
+		return err
+	}
+
+	if undoTaskID != "" {
+		if err := gitManager.UndoTask(run, undoTaskID); err != nil {
+			return fmt.Errorf("failed to undo task: %w", err)
+		}
+		fmt.Printf("\n✅ Task %s undone\n", undoTaskID)
+		return nil
+	}
+
+	if err := gitManager.UndoRun(run); err != nil {
+		return fmt.Errorf("failed to undo run: %w", err)
+	}
+	fmt.Printf("\n✅ Run %s undone\n", run.ID)
+	return nil
+}
+
+func displayRuns(runs []*git.RunRecord) {
+	if len(runs) == 0 {
+		fmt.Println("No runs recorded")
+		return
+	}
+
+	fmt.Println("\n🔖 Recorded Runs:")
+	for _, run := range runs {
+		status := ""
+		if run.UndoneAt != nil {
+			status = " (undone)"
+		} else if run.FinishedAt == nil {
+			status = " (unfinished)"
+		}
+		fmt.Printf("  %s  %-8s %3d commits  %s%s\n",
+			run.ID, run.Command, len(run.Commits), run.StartedAt.Format("2006-01-02 15:04"), status)
+		taskIDs := make([]string, 0, len(run.Tasks))
+		for taskID := range run.Tasks {
+			taskIDs = append(taskIDs, taskID)
+		}
+		sort.Strings(taskIDs)
+		for _, taskID := range taskIDs {
+			fmt.Printf("      - %s (%d commits)\n", taskID, len(run.Tasks[taskID]))
+		}
+	}
+}
+
+func runTemplatesList(cmd *cobra.Command, args []string) error {
+	fmt.Println("\n🧱 Available Presets:")
+	for _, p := range templates.List() {
+		fmt.Printf("  - %-24s %s\n", p.Name, p.Description)
+	}
+	return nil
+}
+
+func runTemplatesShow(cmd *cobra.Command, args []string) error {
+	preset, exists := templates.Get(args[0])
+	if !exists {
+		return fmt.Errorf("unknown preset %q (available: %s)", args[0], strings.Join(templates.Names(), ", "))
+	}
+
+	fmt.Printf("\n🧱 Preset: %s\n", preset.Name)
+	fmt.Printf("  Description: %s\n", preset.Description)
+	fmt.Printf("  Type: %s\n", preset.Type)
+	if preset.HasFrontend {
+		fmt.Printf("  Frontend: %s + %s\n",
+			preset.Architecture.Frontend.Framework,
+			preset.Architecture.Frontend.Styling)
+	}
+	if preset.HasBackend {
+		fmt.Printf("  Backend: %s\n", preset.Architecture.Backend.Framework)
+	}
+	if preset.HasDatabase {
+		fmt.Printf("  Database: %s\n", preset.Architecture.Backend.Database)
+	}
+
+	fmt.Println("\n📄 Files:")
+	for _, file := range preset.Files {
+		fmt.Printf("  + %s\n", file.Path)
+	}
+	return nil
+}
+
+func runTemplatesApply(cmd *cobra.Command, args []string) error {
+	preset, exists := templates.Get(args[0])
+	if !exists {
+		return fmt.Errorf("unknown preset %q (available: %s)", args[0], strings.Join(templates.Names(), ", "))
+	}
+
+	projectDir := "./"
+	if len(args) > 1 {
+		projectDir = args[1]
+	}
+	absDir, err := filepath.Abs(projectDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+
+	written, err := templates.Scaffold(absDir, preset, templates.TemplateData{
+		ProjectName: filepath.Base(absDir),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scaffold preset: %w", err)
+	}
+
+	fmt.Printf("\n✅ %s skeleton created in %s\n", preset.Name, absDir)
+	for _, file := range written {
+		fmt.Printf("  + %s\n", file)
+	}
+	return nil
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
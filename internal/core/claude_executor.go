@@ -20,6 +20,7 @@ type ClaudeOptions struct {
 	MaxTokens       int
 	SystemPrompt    string
 	AdditionalFlags []string
+	WorkDir         string // Directory Claude runs in; the current directory when empty
 }
 
 // ClaudeResponse represents the response from Claude
@@ -56,9 +57,14 @@ func NewClaudeExecutor(logger zerolog.Logger) *ClaudeExecutor {
 	}
 }
 
-// Execute executes a Claude command with the given prompt
+// Execute executes a Claude command with the given prompt. A run exiting
+// non-zero is an error; the response still holds its output.
 func (ce *ClaudeExecutor) Execute(ctx context.Context, prompt string, options *ClaudeOptions) (*ClaudeResponse, error) {
-	return ce.executeWithRetry(ctx, prompt, options)
+	response, err := ce.executeWithRetry(ctx, prompt, options)
+	if err == nil {
+		err = response.Err()
+	}
+	return response, err
 }
 
 // ExecuteWithRole executes a Claude command with a specific role
@@ -66,7 +72,15 @@ func (ce *ClaudeExecutor) ExecuteWithRole(ctx context.Context, prompt string, ro
 	options := &ClaudeOptions{
 		Role: role,
 	}
-	return ce.executeWithRetry(ctx, prompt, options)
+	return ce.Execute(ctx, prompt, options)
+}
+
+// Err returns the error of a run that exited non-zero, nil when it succeeded
+func (r *ClaudeResponse) Err() error {
+	if r == nil || r.Error == nil {
+		return nil
+	}
+	return fmt.Errorf("claude exited with code %d: %w", r.ExitCode, r.Error)
 }
 
 // executeWithRetry executes with automatic retry on failure
@@ -135,6 +149,9 @@ func (ce *ClaudeExecutor) executeOnce(ctx context.Context, prompt string, option
 	// Build command
 	args := ce.buildArgs(prompt, options)
 	cmd := exec.CommandContext(ctx, "claude", args...)
+	if options != nil && options.WorkDir != "" {
+		cmd.Dir = options.WorkDir
+	}
 
 	// Set up pipes
 	var stdout, stderr bytes.Buffer
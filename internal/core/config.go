@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/nohdol/claude-auto/pkg/types"
 	"github.com/spf13/viper"
@@ -11,10 +12,13 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	Claude   ClaudeConfig   `mapstructure:"claude"`
-	Parallel ParallelConfig `mapstructure:"parallel"`
-	Git      GitConfig      `mapstructure:"git"`
-	Docs     DocsConfig     `mapstructure:"documentation"`
+	Claude     ClaudeConfig     `mapstructure:"claude"`
+	Parallel   ParallelConfig   `mapstructure:"parallel"`
+	Git        GitConfig        `mapstructure:"git"`
+	Docs       DocsConfig       `mapstructure:"documentation"`
+	Validation ValidationConfig `mapstructure:"validation"`
+	Forge      ForgeConfig      `mapstructure:"forge"`
+	Analysis   AnalysisConfig   `mapstructure:"analysis"`
 }
 
 // ClaudeConfig represents Claude-related configuration
@@ -27,20 +31,36 @@ type ClaudeConfig struct {
 
 // ParallelConfig represents parallel execution configuration
 type ParallelConfig struct {
-	MaxWorkers   int    `mapstructure:"max_workers"`
-	TaskTimeout  string `mapstructure:"task_timeout"`
-	BatchSize    int    `mapstructure:"batch_size"`
+	MaxWorkers  int    `mapstructure:"max_workers"`
+	TaskTimeout string `mapstructure:"task_timeout"`
+	BatchSize   int    `mapstructure:"batch_size"`
+	Worktrees   bool   `mapstructure:"worktrees"`
 }
 
 // GitConfig represents Git-related configuration
 type GitConfig struct {
-	AutoCommit    bool             `mapstructure:"auto_commit"`
-	CommitSize    types.CommitSize `mapstructure:"commit_size"`
-	PushStrategy  string           `mapstructure:"push_strategy"`
-	AuthorName    string           `mapstructure:"author_name"`
-	AuthorEmail   string           `mapstructure:"author_email"`
-	RemoteName    string           `mapstructure:"remote_name"`
-	DefaultBranch string           `mapstructure:"default_branch"`
+	AutoCommit     bool             `mapstructure:"auto_commit"`
+	CommitSize     types.CommitSize `mapstructure:"commit_size"`
+	PushStrategy   string           `mapstructure:"push_strategy"`
+	BranchStrategy string           `mapstructure:"branch_strategy"`
+	CommitMessage  string           `mapstructure:"commit_message"`
+	AuthorName     string           `mapstructure:"author_name"`
+	AuthorEmail    string           `mapstructure:"author_email"`
+	RemoteName     string           `mapstructure:"remote_name"`
+	DefaultBranch  string           `mapstructure:"default_branch"`
+	// Push settings
+	ProtectedBranches   []string `mapstructure:"protected_branches"`
+	PushRetries         int      `mapstructure:"push_retries"`
+	SSHKeyPath          string   `mapstructure:"ssh_key_path"`
+	SSHKeyPassphraseEnv string   `mapstructure:"ssh_key_passphrase_env"`
+	TokenEnv            string   `mapstructure:"token_env"`
+	// Identity and signing; an empty committer is the user.name of the git configuration
+	CommitterName           string   `mapstructure:"committer_name"`
+	CommitterEmail          string   `mapstructure:"committer_email"`
+	SigningFormat           string   `mapstructure:"signing_format"`
+	SigningKey              string   `mapstructure:"signing_key"`
+	SigningKeyPassphraseEnv string   `mapstructure:"signing_key_passphrase_env"`
+	Trailers                []string `mapstructure:"trailers"`
 }
 
 // DocsConfig represents documentation configuration
@@ -50,6 +70,35 @@ type DocsConfig struct {
 	Generate  bool   `mapstructure:"generate"`
 }
 
+// ValidationConfig represents post-task verification gate configuration
+type ValidationConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`
+	MaxFixAttempts int    `mapstructure:"max_fix_attempts"`
+	Timeout        string `mapstructure:"timeout"`
+}
+
+// ForgeConfig represents pull request configuration
+type ForgeConfig struct {
+	Provider string `mapstructure:"provider"`
+	// APIURL overrides the API base URL derived from the remote
+	APIURL string `mapstructure:"api_url"`
+	// TokenEnv names the environment variable holding the API token; git.token_env is used when unset
+	TokenEnv    string   `mapstructure:"token_env"`
+	PullRequest bool     `mapstructure:"pull_request"`
+	Draft       bool     `mapstructure:"draft"`
+	Labels      []string `mapstructure:"labels"`
+}
+
+// AnalysisConfig represents project analysis configuration
+type AnalysisConfig struct {
+	// ChunkTokens is the token budget of the files analyzed by one Claude call
+	ChunkTokens  int   `mapstructure:"chunk_tokens"`
+	MaxChunks    int   `mapstructure:"max_chunks"`
+	MaxFileBytes int64 `mapstructure:"max_file_bytes"`
+	// StaticChecks runs linters and local scanners before Claude reviews the project
+	StaticChecks bool `mapstructure:"static_checks"`
+}
+
 // LoadConfig loads configuration from file and environment
 func LoadConfig(configPath string) (*Config, error) {
 	v := viper.New()
@@ -105,20 +154,53 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("parallel.max_workers", 3)
 	v.SetDefault("parallel.task_timeout", "10m")
 	v.SetDefault("parallel.batch_size", 5)
+	v.SetDefault("parallel.worktrees", true)
 
 	// Git defaults
 	v.SetDefault("git.auto_commit", true)
 	v.SetDefault("git.commit_size", "small")
 	v.SetDefault("git.push_strategy", "batch")
+	v.SetDefault("git.branch_strategy", "feature")
+	v.SetDefault("git.commit_message", "template")
 	v.SetDefault("git.author_name", "Claude Auto")
 	v.SetDefault("git.author_email", "claude-auto@example.com")
 	v.SetDefault("git.remote_name", "origin")
 	v.SetDefault("git.default_branch", "main")
+	v.SetDefault("git.protected_branches", []string{"main", "master"})
+	v.SetDefault("git.push_retries", 3)
+	v.SetDefault("git.ssh_key_path", "")
+	v.SetDefault("git.ssh_key_passphrase_env", "")
+	v.SetDefault("git.token_env", "GIT_TOKEN")
+	v.SetDefault("git.committer_name", "")
+	v.SetDefault("git.committer_email", "")
+	v.SetDefault("git.signing_format", "")
+	v.SetDefault("git.signing_key", "")
+	v.SetDefault("git.signing_key_passphrase_env", "")
+	v.SetDefault("git.trailers", []string{"task-id", "run-id"})
 
 	// Documentation defaults
 	v.SetDefault("documentation.language", "ko")
 	v.SetDefault("documentation.output_dir", "./docs/progress")
 	v.SetDefault("documentation.generate", true)
+
+	// Validation defaults
+	v.SetDefault("validation.enabled", true)
+	v.SetDefault("validation.max_fix_attempts", 2)
+	v.SetDefault("validation.timeout", "5m")
+
+	// Forge defaults
+	v.SetDefault("forge.provider", "auto")
+	v.SetDefault("forge.api_url", "")
+	v.SetDefault("forge.token_env", "")
+	v.SetDefault("forge.pull_request", false)
+	v.SetDefault("forge.draft", false)
+	v.SetDefault("forge.labels", []string{})
+
+	// Analysis defaults
+	v.SetDefault("analysis.chunk_tokens", 20000)
+	v.SetDefault("analysis.max_chunks", 8)
+	v.SetDefault("analysis.max_file_bytes", 204800)
+	v.SetDefault("analysis.static_checks", true)
 }
 
 // validateConfig validates the configuration
@@ -128,6 +210,11 @@ func validateConfig(cfg *Config) error {
 		return fmt.Errorf("parallel.max_workers must be greater than 0")
 	}
 
+	// Validate fix attempts
+	if cfg.Validation.MaxFixAttempts < 0 {
+		return fmt.Errorf("validation.max_fix_attempts must not be negative")
+	}
+
 	// Validate commit size
 	validCommitSizes := map[types.CommitSize]bool{
 		types.CommitSizeAtomic: true,
@@ -148,6 +235,76 @@ func validateConfig(cfg *Config) error {
 		return fmt.Errorf("invalid git.push_strategy: %s", cfg.Git.PushStrategy)
 	}
 
+	// Validate commit message mode
+	validCommitMessages := map[string]bool{
+		"template": true,
+		"ai":       true,
+	}
+	if !validCommitMessages[cfg.Git.CommitMessage] {
+		return fmt.Errorf("invalid git.commit_message: %s", cfg.Git.CommitMessage)
+	}
+
+	// Validate push retries
+	if cfg.Git.PushRetries < 0 {
+		return fmt.Errorf("git.push_retries must not be negative")
+	}
+
+	// Validate branch strategy
+	validBranchStrategies := map[string]bool{
+		"direct":  true,
+		"feature": true,
+	}
+	if !validBranchStrategies[cfg.Git.BranchStrategy] {
+		return fmt.Errorf("invalid git.branch_strategy: %s", cfg.Git.BranchStrategy)
+	}
+
+	// Validate signing format
+	validSigningFormats := map[string]bool{
+		"":        true,
+		"openpgp": true,
+		"ssh":     true,
+	}
+	if !validSigningFormats[cfg.Git.SigningFormat] {
+		return fmt.Errorf("invalid git.signing_format: %s", cfg.Git.SigningFormat)
+	}
+	if cfg.Git.SigningFormat != "" && cfg.Git.SigningKey == "" {
+		return fmt.Errorf("git.signing_key is required when git.signing_format is set")
+	}
+
+	// Validate trailers
+	validTrailers := map[string]bool{
+		"task-id":        true,
+		"run-id":         true,
+		"model":          true,
+		"co-authored-by": true,
+	}
+	for _, trailer := range cfg.Git.Trailers {
+		if !validTrailers[strings.ToLower(trailer)] {
+			return fmt.Errorf("invalid git.trailers entry: %s", trailer)
+		}
+	}
+
+	// Validate forge provider
+	validProviders := map[string]bool{
+		"auto":   true,
+		"github": true,
+		"gitlab": true,
+	}
+	if !validProviders[cfg.Forge.Provider] {
+		return fmt.Errorf("invalid forge.provider: %s", cfg.Forge.Provider)
+	}
+
+	// Validate analysis limits
+	if cfg.Analysis.ChunkTokens < 1000 {
+		return fmt.Errorf("analysis.chunk_tokens must be at least 1000")
+	}
+	if cfg.Analysis.MaxChunks <= 0 {
+		return fmt.Errorf("analysis.max_chunks must be greater than 0")
+	}
+	if cfg.Analysis.MaxFileBytes <= 0 {
+		return fmt.Errorf("analysis.max_file_bytes must be greater than 0")
+	}
+
 	return nil
 }
 
@@ -160,6 +317,9 @@ func SaveConfig(cfg *Config, path string) error {
 	v.Set("parallel", cfg.Parallel)
 	v.Set("git", cfg.Git)
 	v.Set("documentation", cfg.Docs)
+	v.Set("validation", cfg.Validation)
+	v.Set("forge", cfg.Forge)
+	v.Set("analysis", cfg.Analysis)
 
 	// Ensure directory exists
 	dir := filepath.Dir(path)
@@ -188,20 +348,41 @@ func GetDefaultConfig() *Config {
 			MaxWorkers:  3,
 			TaskTimeout: "10m",
 			BatchSize:   5,
+			Worktrees:   true,
 		},
 		Git: GitConfig{
-			AutoCommit:    true,
-			CommitSize:    types.CommitSizeSmall,
-			PushStrategy:  "batch",
-			AuthorName:    "Claude Auto",
-			AuthorEmail:   "claude-auto@example.com",
-			RemoteName:    "origin",
-			DefaultBranch: "main",
+			AutoCommit:        true,
+			CommitSize:        types.CommitSizeSmall,
+			PushStrategy:      "batch",
+			BranchStrategy:    "feature",
+			CommitMessage:     "template",
+			AuthorName:        "Claude Auto",
+			AuthorEmail:       "claude-auto@example.com",
+			RemoteName:        "origin",
+			DefaultBranch:     "main",
+			ProtectedBranches: []string{"main", "master"},
+			PushRetries:       3,
+			TokenEnv:          "GIT_TOKEN",
+			Trailers:          []string{"task-id", "run-id"},
 		},
 		Docs: DocsConfig{
 			Language:  "ko",
 			OutputDir: "./docs/progress",
 			Generate:  true,
 		},
+		Validation: ValidationConfig{
+			Enabled:        true,
+			MaxFixAttempts: 2,
+			Timeout:        "5m",
+		},
+		Forge: ForgeConfig{
+			Provider: "auto",
+		},
+		Analysis: AnalysisConfig{
+			ChunkTokens:  20000,
+			MaxChunks:    8,
+			MaxFileBytes: 204800,
+			StaticChecks: true,
+		},
 	}
-}
\ No newline at end of file
+}
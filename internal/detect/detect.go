@@ -0,0 +1,258 @@
+package detect
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Kind classifies a detected component
+type Kind string
+
+const (
+	KindLanguage  Kind = "language"
+	KindFramework Kind = "framework"
+	KindBuild     Kind = "build"     // package managers and build tools
+	KindInfra     Kind = "infra"     // containers and infrastructure as code
+	KindWorkspace Kind = "workspace" // monorepo tools
+)
+
+// Component is a language, framework or tool found in a project directory
+type Component struct {
+	Kind       Kind    `json:"kind"`
+	Name       string  `json:"name"`
+	Version    string  `json:"version,omitempty"`
+	Type       string  `json:"type,omitempty"` // web, api, cli, mobile, desktop for frameworks
+	Path       string  `json:"path"`           // directory relative to the root, "." for the root
+	Evidence   string  `json:"evidence"`       // file the component was detected from
+	Confidence float64 `json:"confidence"`     // 0.0 to 1.0
+}
+
+// Detector finds components from the files of one directory
+type Detector struct {
+	Name   string
+	Detect func(dir string) []Component
+}
+
+// minRootConfidence is the confidence a root component needs to be primary
+// over the components of workspace packages
+const minRootConfidence = 0.6
+
+var registry []Detector
+
+// register adds a detector to the registry
+func register(d Detector) {
+	registry = append(registry, d)
+}
+
+// Detectors returns the names of all registered detectors sorted by name
+func Detectors() []string {
+	names := make([]string, 0, len(registry))
+	for _, d := range registry {
+		names = append(names, d.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Package is a member of a workspace
+type Package struct {
+	Name string `json:"name"`
+	Path string `json:"path"` // directory relative to the root
+}
+
+// Detection is the result of detecting a project
+type Detection struct {
+	Root       string
+	Components []Component // sorted by confidence, highest first
+	Packages   []Package   // workspace members, empty for single projects
+}
+
+// Detect runs every detector in the root and in each workspace package
+func Detect(root string) *Detection {
+	detection := &Detection{Root: root}
+	detection.Components = detectDir(root, ".")
+
+	detection.Packages = discoverPackages(root)
+	for _, pkg := range detection.Packages {
+		detection.Components = append(detection.Components, detectDir(filepath.Join(root, pkg.Path), pkg.Path)...)
+	}
+
+	sort.SliceStable(detection.Components, func(i, j int) bool {
+		a, b := detection.Components[i], detection.Components[j]
+		if a.Confidence != b.Confidence {
+			return a.Confidence > b.Confidence
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Name < b.Name
+	})
+	return detection
+}
+
+// detectDir runs the detectors in dir, making paths relative to the root
+func detectDir(dir, rel string) []Component {
+	var components []Component
+	seen := make(map[string]int)
+
+	for _, d := range registry {
+		for _, c := range d.Detect(dir) {
+			c.Path = rel
+			if rel != "." {
+				c.Evidence = filepath.ToSlash(filepath.Join(rel, c.Evidence))
+			}
+			// Keep the most confident detection of a component
+			key := string(c.Kind) + ":" + c.Name
+			if i, ok := seen[key]; ok {
+				if c.Confidence > components[i].Confidence {
+					components[i] = c
+				}
+				continue
+			}
+			seen[key] = len(components)
+			components = append(components, c)
+		}
+	}
+	return components
+}
+
+// Primary returns the main language, framework and project type. Confident
+// components of the root win over those of packages; otherwise the most
+// confident framework is used with the language of its package.
+func (d *Detection) Primary() (projectType, language, framework string) {
+	best := func(kind Kind, path string) *Component {
+		var found *Component
+		for i := range d.Components {
+			c := &d.Components[i]
+			if c.Kind != kind || (path != "" && c.Path != path) {
+				continue
+			}
+			if found == nil || (c.Path == "." && found.Path != "." && c.Confidence >= minRootConfidence) {
+				found = c
+			}
+		}
+		return found
+	}
+
+	lang := best(KindLanguage, "")
+	if c := best(KindFramework, ""); c != nil {
+		framework = c.Name
+		projectType = c.Type
+		if lang != nil && lang.Path != c.Path && (lang.Path != "." || lang.Confidence < minRootConfidence) {
+			if own := best(KindLanguage, c.Path); own != nil {
+				lang = own
+			}
+		}
+	}
+	if lang != nil {
+		language = lang.Name
+	}
+	if projectType == "" {
+		projectType = "unknown"
+	}
+	return projectType, language, framework
+}
+
+// PrimaryIn returns the main language, framework and project type of one directory
+func (d *Detection) PrimaryIn(path string) (projectType, language, framework string) {
+	return (&Detection{Root: d.Root, Components: d.In(path)}).Primary()
+}
+
+// In returns the components detected in one directory
+func (d *Detection) In(path string) []Component {
+	var components []Component
+	for _, c := range d.Components {
+		if c.Path == path {
+			components = append(components, c)
+		}
+	}
+	return components
+}
+
+// frameworkRule maps a dependency to a framework
+type frameworkRule struct {
+	Dependency string // exact name, or a prefix ending in "/" or ":"
+	Name       string
+	Type       string
+	Confidence float64
+}
+
+// matchFrameworks returns the frameworks whose dependency is declared. Rules
+// matched only by development dependencies count for less.
+func matchFrameworks(rules []frameworkRule, deps, devDeps map[string]string, evidence string) []Component {
+	var components []Component
+	for _, rule := range rules {
+		version, ok := lookupDependency(deps, rule.Dependency)
+		confidence := rule.Confidence
+		if !ok {
+			if version, ok = lookupDependency(devDeps, rule.Dependency); !ok {
+				continue
+			}
+			confidence *= 0.6
+		}
+		components = append(components, Component{
+			Kind:       KindFramework,
+			Name:       rule.Name,
+			Version:    version,
+			Type:       rule.Type,
+			Evidence:   evidence,
+			Confidence: confidence,
+		})
+	}
+	return components
+}
+
+// lookupDependency finds a dependency by name, or by prefix for names ending
+// in "/" or, for Maven coordinates, ":"
+func lookupDependency(deps map[string]string, name string) (string, bool) {
+	if !strings.HasSuffix(name, "/") && !strings.HasSuffix(name, ":") {
+		version, ok := deps[name]
+		return version, ok
+	}
+	for dep, version := range deps {
+		if strings.HasPrefix(dep, name) {
+			return version, true
+		}
+	}
+	return "", false
+}
+
+// exists reports whether a file exists in dir
+func exists(dir, name string) bool {
+	_, err := os.Stat(filepath.Join(dir, name))
+	return err == nil
+}
+
+// glob returns the base names of the files in dir matching a pattern
+func glob(dir, pattern string) []string {
+	matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+	names := make([]string, 0, len(matches))
+	for _, match := range matches {
+		names = append(names, filepath.Base(match))
+	}
+	return names
+}
+
+// readJSON decodes a JSON file of dir, reporting whether it exists and parses
+func readJSON(dir, name string, v interface{}) bool {
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	return err == nil && json.Unmarshal(content, v) == nil
+}
+
+// readTOML decodes a TOML file of dir, reporting whether it exists and parses
+func readTOML(dir, name string, v interface{}) bool {
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	return err == nil && toml.Unmarshal(content, v) == nil
+}
+
+// readYAML decodes a YAML file of dir, reporting whether it exists and parses
+func readYAML(dir, name string, v interface{}) bool {
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	return err == nil && yaml.Unmarshal(content, v) == nil
+}
@@ -0,0 +1,283 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles creates files below root, keyed by slash-separated paths
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// find returns the component of a kind and name, or nil
+func find(components []Component, kind Kind, name string) *Component {
+	for i := range components {
+		if components[i].Kind == kind && components[i].Name == name {
+			return &components[i]
+		}
+	}
+	return nil
+}
+
+func TestDetectPrimary(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         map[string]string
+		wantType      string
+		wantLanguage  string
+		wantFramework string
+	}{
+		{
+			name:     "go cli",
+			files:    map[string]string{"go.mod": "module example.com/tool\n\ngo 1.22\n\nrequire (\n\tgithub.com/spf13/cobra v1.8.0 // cli\n)\n"},
+			wantType: "cli", wantLanguage: "go", wantFramework: "cobra",
+		},
+		{
+			name: "typescript web app",
+			files: map[string]string{
+				"package.json":   `{"dependencies": {"next": "14.1.0", "react": "18.2.0"}}`,
+				"tsconfig.json":  "{}",
+				"pnpm-lock.yaml": "",
+			},
+			wantType: "web", wantLanguage: "typescript", wantFramework: "nextjs",
+		},
+		{
+			name:     "poetry api",
+			files:    map[string]string{"pyproject.toml": "[tool.poetry]\nname = \"svc\"\n\n[tool.poetry.dependencies]\npython = \"^3.11\"\nFastAPI = \"^0.110\"\n"},
+			wantType: "api", wantLanguage: "python", wantFramework: "fastapi",
+		},
+		{
+			name:     "django without dependencies",
+			files:    map[string]string{"setup.py": "", "manage.py": ""},
+			wantType: "web", wantLanguage: "python", wantFramework: "django",
+		},
+		{
+			name:     "framework only in dev dependencies",
+			files:    map[string]string{"package.json": `{"devDependencies": {"express": "4.18.0"}}`},
+			wantType: "api", wantLanguage: "javascript", wantFramework: "express",
+		},
+		{
+			name:     "empty",
+			files:    map[string]string{"README.md": "# empty"},
+			wantType: "unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+
+			projectType, language, framework := Detect(root).Primary()
+			if projectType != tt.wantType || language != tt.wantLanguage || framework != tt.wantFramework {
+				t.Errorf("Primary = %q, %q, %q, want %q, %q, %q",
+					projectType, language, framework, tt.wantType, tt.wantLanguage, tt.wantFramework)
+			}
+		})
+	}
+}
+
+func TestDetectComponents(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":             "module example.com/svc\ngo 1.21\nrequire github.com/gin-gonic/gin v1.9.1\n",
+		"Dockerfile":         "FROM golang:1.21 AS build\nFROM --platform=linux/amd64 alpine:3.19\n",
+		"docker-compose.yml": "services:\n  app:\n    build: .\n",
+		"package.json":       `{"devDependencies": {"vue": "3.4.0"}}`,
+	})
+
+	detection := Detect(root)
+
+	gin := find(detection.Components, KindFramework, "gin")
+	if gin == nil || gin.Version != "v1.9.1" || gin.Confidence != 0.9 || gin.Path != "." || gin.Evidence != "go.mod" {
+		t.Errorf("gin = %+v", gin)
+	}
+	if docker := find(detection.Components, KindInfra, "docker"); docker == nil || docker.Version != "alpine:3.19" {
+		t.Errorf("docker = %+v, want the image of the final stage", docker)
+	}
+	if find(detection.Components, KindInfra, "docker-compose") == nil {
+		t.Error("docker-compose not detected")
+	}
+	// Frameworks only used in development count for less
+	if vue := find(detection.Components, KindFramework, "vue"); vue == nil || vue.Confidence >= 0.8 {
+		t.Errorf("vue = %+v, want a lowered confidence", vue)
+	}
+
+	for i := 1; i < len(detection.Components); i++ {
+		if detection.Components[i-1].Confidence < detection.Components[i].Confidence {
+			t.Fatalf("components are not sorted by confidence: %+v", detection.Components)
+		}
+	}
+}
+
+func TestDetectWorkspace(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"package.json":                    `{"name": "monorepo", "private": true, "workspaces": {"packages": ["apps/*", "packages/**", "!packages/legacy"]}}`,
+		"turbo.json":                      "{}",
+		"apps/web/package.json":           `{"name": "@acme/web", "dependencies": {"next": "14.1.0", "react": "18.2.0"}}`,
+		"apps/web/tsconfig.json":          "{}",
+		"apps/api/go.mod":                 "module example.com/api\n\ngo 1.22\n\nrequire github.com/labstack/echo/v4 v4.11.4\n",
+		"apps/docs/README.md":             "no manifest",
+		"packages/ui/button/package.json": `{"name": "@acme/button"}`,
+		"packages/legacy/package.json":    `{"name": "@acme/legacy"}`,
+		"node_modules/dep/package.json":   `{"name": "dep"}`,
+	})
+
+	detection := Detect(root)
+
+	want := []Package{
+		{Name: "example.com/api", Path: "apps/api"},
+		{Name: "@acme/web", Path: "apps/web"},
+		{Name: "@acme/button", Path: "packages/ui/button"},
+	}
+	if !reflect.DeepEqual(detection.Packages, want) {
+		t.Errorf("Packages = %+v\nwant %+v", detection.Packages, want)
+	}
+
+	for _, name := range []string{"npm-workspaces", "turbo"} {
+		if c := find(detection.In("."), KindWorkspace, name); c == nil {
+			t.Errorf("workspace tool %s not detected", name)
+		}
+	}
+	if c := find(detection.Components, KindLanguage, "typescript"); c == nil || c.Path != "apps/web" || c.Evidence != "apps/web/tsconfig.json" {
+		t.Errorf("typescript = %+v, want it in apps/web", c)
+	}
+
+	// The root only declares workspaces, so the most confident framework wins
+	// together with the language of its own package
+	if projectType, language, framework := detection.Primary(); projectType != "web" || language != "typescript" || framework != "nextjs" {
+		t.Errorf("Primary = %q, %q, %q", projectType, language, framework)
+	}
+	if projectType, language, framework := detection.PrimaryIn("apps/api"); projectType != "api" || language != "go" || framework != "echo" {
+		t.Errorf("PrimaryIn(apps/api) = %q, %q, %q", projectType, language, framework)
+	}
+}
+
+func TestDiscoverPackages(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []Package
+	}{
+		{
+			name: "go workspace",
+			files: map[string]string{
+				"go.work":         "go 1.22\n\nuse (\n\t./cmd/tool\n\t./lib\n)\nuse ./extra\n",
+				"cmd/tool/go.mod": "module example.com/tool\n",
+				"lib/go.mod":      "module example.com/lib\n",
+			},
+			want: []Package{{Name: "example.com/tool", Path: "cmd/tool"}, {Name: "example.com/lib", Path: "lib"}},
+		},
+		{
+			name: "pnpm",
+			files: map[string]string{
+				"pnpm-workspace.yaml":       "packages:\n  - 'packages/*'\n  - '../outside'\n",
+				"packages/a/package.json":   `{"name": "a"}`,
+				"packages/b/pyproject.toml": "[project]\nname = \"b-py\"\n",
+			},
+			want: []Package{{Name: "a", Path: "packages/a"}, {Name: "b-py", Path: "packages/b"}},
+		},
+		{
+			name: "cargo",
+			files: map[string]string{
+				"Cargo.toml":             "[workspace]\nmembers = [\"crates/*\"]\n",
+				"crates/core/Cargo.toml": "[package]\nname = \"core-lib\"\n",
+			},
+			want: []Package{{Name: "core-lib", Path: "crates/core"}},
+		},
+		{
+			name: "gradle",
+			files: map[string]string{
+				"settings.gradle":          "rootProject.name = 'app'\ninclude ':server', ':libs:common'\n",
+				"server/build.gradle":      "",
+				"libs/common/build.gradle": "",
+			},
+			want: []Package{{Name: "libs/common", Path: "libs/common"}, {Name: "server", Path: "server"}},
+		},
+		{
+			name: "nx projects",
+			files: map[string]string{
+				"nx.json":                "{}",
+				"apps/shop/project.json": `{"name": "shop"}`,
+				"libs/ui/project.json":   `{"name": "ui"}`,
+				".cache/x/project.json":  `{"name": "hidden"}`,
+			},
+			want: []Package{{Name: "shop", Path: "apps/shop"}, {Name: "ui", Path: "libs/ui"}},
+		},
+		{
+			name:  "single project",
+			files: map[string]string{"package.json": `{"name": "single"}`},
+			want:  []Package{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+
+			got := discoverPackages(root)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("discoverPackages = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGoMod(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"go.mod": `module "example.com/quoted" // comment
+
+go 1.22
+
+require github.com/a/b v1.0.0
+require (
+	github.com/c/d v0.2.0 // indirect
+
+	github.com/e/f v0.3.0
+)
+`})
+
+	mod, ok := parseGoMod(root)
+	if !ok {
+		t.Fatal("parseGoMod did not read go.mod")
+	}
+	want := &goModule{
+		Path:     "example.com/quoted",
+		Go:       "1.22",
+		Requires: map[string]string{"github.com/a/b": "v1.0.0", "github.com/c/d": "v0.2.0", "github.com/e/f": "v0.3.0"},
+	}
+	if !reflect.DeepEqual(mod, want) {
+		t.Errorf("parseGoMod = %+v, want %+v", mod, want)
+	}
+
+	if _, ok := parseGoMod(t.TempDir()); ok {
+		t.Error("parseGoMod succeeded without go.mod")
+	}
+}
+
+func TestDetectors(t *testing.T) {
+	names := Detectors()
+	for _, want := range []string{"docker", "go", "node", "python", "workspace"} {
+		found := false
+		for _, name := range names {
+			found = found || name == want
+		}
+		if !found {
+			t.Errorf("Detectors() = %q, missing %s", names, want)
+		}
+	}
+}
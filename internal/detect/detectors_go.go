@@ -0,0 +1,77 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	register(Detector{Name: "go", Detect: detectGo})
+}
+
+var goFrameworks = []frameworkRule{
+	{"github.com/gin-gonic/gin", "gin", "api", 0.9},
+	{"github.com/gofiber/fiber/", "fiber", "api", 0.9},
+	{"github.com/labstack/echo/", "echo", "api", 0.9},
+	{"github.com/go-chi/chi/", "chi", "api", 0.85},
+	{"github.com/gorilla/mux", "gorilla", "api", 0.8},
+	{"google.golang.org/grpc", "grpc", "api", 0.7},
+	{"github.com/spf13/cobra", "cobra", "cli", 0.85},
+	{"github.com/urfave/cli/", "urfave-cli", "cli", 0.85},
+	{"fyne.io/fyne/", "fyne", "desktop", 0.9},
+	{"github.com/wailsapp/wails/", "wails", "desktop", 0.9},
+}
+
+// goModule is the part of go.mod the detectors read
+type goModule struct {
+	Path     string
+	Go       string
+	Requires map[string]string
+}
+
+func detectGo(dir string) []Component {
+	mod, ok := parseGoMod(dir)
+	if !ok {
+		return nil
+	}
+
+	components := []Component{{Kind: KindLanguage, Name: "go", Version: mod.Go, Evidence: "go.mod", Confidence: 1}}
+	return append(components, matchFrameworks(goFrameworks, mod.Requires, nil, "go.mod")...)
+}
+
+// parseGoMod reads the module path, Go version and requirements of go.mod
+func parseGoMod(dir string) (*goModule, bool) {
+	content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return nil, false
+	}
+
+	mod := &goModule{Requires: make(map[string]string)}
+	inRequire := false
+	for _, line := range strings.Split(string(content), "\n") {
+		if i := strings.Index(line, "//"); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch {
+		case inRequire && fields[0] == ")":
+			inRequire = false
+		case inRequire && len(fields) >= 2:
+			mod.Requires[fields[0]] = fields[1]
+		case fields[0] == "module" && len(fields) >= 2:
+			mod.Path = strings.Trim(fields[1], `"`)
+		case fields[0] == "go" && len(fields) >= 2:
+			mod.Go = fields[1]
+		case fields[0] == "require" && len(fields) >= 2 && fields[1] == "(":
+			inRequire = true
+		case fields[0] == "require" && len(fields) >= 3:
+			mod.Requires[fields[1]] = fields[2]
+		}
+	}
+	return mod, true
+}
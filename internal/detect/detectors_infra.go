@@ -0,0 +1,128 @@
+package detect
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+func init() {
+	register(Detector{Name: "docker", Detect: detectDocker})
+	register(Detector{Name: "terraform", Detect: detectTerraform})
+}
+
+// composeFiles are the file names of Docker Compose
+var composeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+var (
+	// terraformProvider matches provider blocks
+	terraformProvider = regexp.MustCompile(`^provider\s+"([\w-]+)"`)
+	// terraformRequirement matches the entries of a required_providers block
+	terraformRequirement = regexp.MustCompile(`^([\w-]+)\s*=`)
+)
+
+func detectDocker(dir string) []Component {
+	var components []Component
+
+	dockerfiles := glob(dir, "Dockerfile")
+	dockerfiles = append(dockerfiles, glob(dir, "Dockerfile.*")...)
+	dockerfiles = append(dockerfiles, glob(dir, "*.Dockerfile")...)
+	if len(dockerfiles) > 0 {
+		components = append(components, Component{
+			Kind:       KindInfra,
+			Name:       "docker",
+			Version:    dockerBaseImage(filepath.Join(dir, dockerfiles[0])),
+			Evidence:   dockerfiles[0],
+			Confidence: 1,
+		})
+	}
+
+	for _, name := range composeFiles {
+		var compose struct {
+			Services map[string]interface{} `yaml:"services"`
+		}
+		if readYAML(dir, name, &compose) && len(compose.Services) > 0 {
+			components = append(components, Component{Kind: KindInfra, Name: "docker-compose", Evidence: name, Confidence: 0.95})
+			break
+		}
+	}
+	return components
+}
+
+// dockerBaseImage returns the image of the last FROM instruction, the one the
+// final stage is built from
+func dockerBaseImage(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	image := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+		image = fields[1]
+		if strings.HasPrefix(image, "--") && len(fields) > 2 {
+			image = fields[2]
+		}
+	}
+	return image
+}
+
+func detectTerraform(dir string) []Component {
+	files := glob(dir, "*.tf")
+	if len(files) == 0 {
+		return nil
+	}
+	sort.Strings(files)
+
+	// Report the providers in place of a version so the cloud is visible
+	providers := make(map[string]bool)
+	for _, name := range files {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+
+		depth := 0
+		inRequired := false
+		for _, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			if match := terraformProvider.FindStringSubmatch(line); match != nil {
+				providers[match[1]] = true
+			}
+			if strings.HasPrefix(line, "required_providers") {
+				inRequired, depth = true, 0
+			} else if inRequired && depth == 1 {
+				if match := terraformRequirement.FindStringSubmatch(line); match != nil {
+					providers[match[1]] = true
+				}
+			}
+			if inRequired {
+				depth += strings.Count(line, "{") - strings.Count(line, "}")
+				inRequired = depth > 0
+			}
+		}
+	}
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return []Component{{
+		Kind:       KindInfra,
+		Name:       "terraform",
+		Version:    strings.Join(names, ","),
+		Evidence:   files[0],
+		Confidence: 0.95,
+	}}
+}
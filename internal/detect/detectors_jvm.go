@@ -0,0 +1,137 @@
+package detect
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+func init() {
+	register(Detector{Name: "maven", Detect: detectMaven})
+	register(Detector{Name: "gradle", Detect: detectGradle})
+}
+
+// jvmFrameworks match Maven "groupId:artifactId" coordinates or their group prefix
+var jvmFrameworks = []frameworkRule{
+	{"org.springframework.boot:", "spring-boot", "api", 0.95},
+	{"io.quarkus:", "quarkus", "api", 0.95},
+	{"io.micronaut:", "micronaut", "api", 0.95},
+	{"io.ktor:", "ktor", "api", 0.9},
+	{"com.android.application", "android", "mobile", 0.95},
+	{"info.picocli:", "picocli", "cli", 0.8},
+}
+
+// mavenPOM is the part of pom.xml the detectors read
+type mavenPOM struct {
+	ArtifactID string `xml:"artifactId"`
+	Parent     struct {
+		GroupID    string `xml:"groupId"`
+		ArtifactID string `xml:"artifactId"`
+		Version    string `xml:"version"`
+	} `xml:"parent"`
+	Properties struct {
+		JavaVersion string `xml:"java.version"`
+	} `xml:"properties"`
+	Modules      []string        `xml:"modules>module"`
+	Dependencies []mavenArtifact `xml:"dependencies>dependency"`
+	Plugins      []mavenArtifact `xml:"build>plugins>plugin"`
+}
+
+type mavenArtifact struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+}
+
+func detectMaven(dir string) []Component {
+	content, err := os.ReadFile(filepath.Join(dir, "pom.xml"))
+	if err != nil {
+		return nil
+	}
+	var pom mavenPOM
+	if err := xml.Unmarshal(content, &pom); err != nil {
+		return nil
+	}
+
+	deps := make(map[string]string)
+	for _, artifact := range append(pom.Dependencies, pom.Plugins...) {
+		deps[artifact.GroupID+":"+artifact.ArtifactID] = artifact.Version
+	}
+	if pom.Parent.GroupID != "" {
+		deps[pom.Parent.GroupID+":"+pom.Parent.ArtifactID] = pom.Parent.Version
+	}
+
+	language := "java"
+	if _, ok := lookupDependency(deps, "org.jetbrains.kotlin:"); ok || exists(dir, "src/main/kotlin") {
+		language = "kotlin"
+	}
+
+	components := []Component{
+		{Kind: KindLanguage, Name: language, Version: pom.Properties.JavaVersion, Evidence: "pom.xml", Confidence: 0.95},
+		{Kind: KindBuild, Name: "maven", Evidence: "pom.xml", Confidence: 1},
+	}
+	return append(components, matchFrameworks(jvmFrameworks, deps, nil, "pom.xml")...)
+}
+
+var (
+	// gradlePlugin matches id("x"), id 'x' and kotlin("x") plugin declarations
+	gradlePlugin = regexp.MustCompile(`\b(id|kotlin)\s*\(?\s*["']([^"']+)["']`)
+	// gradleDependency matches "group:artifact:version" dependency notations
+	gradleDependency = regexp.MustCompile(`["']([\w.-]+):([\w.-]+)(?::([\w.+-]+))?["']`)
+)
+
+func detectGradle(dir string) []Component {
+	evidence := ""
+	for _, name := range []string{"build.gradle.kts", "build.gradle"} {
+		if exists(dir, name) {
+			evidence = name
+			break
+		}
+	}
+	if evidence == "" {
+		return nil
+	}
+	content, err := os.ReadFile(filepath.Join(dir, evidence))
+	if err != nil {
+		return nil
+	}
+
+	deps := make(map[string]string)
+	for _, match := range gradlePlugin.FindAllStringSubmatch(string(content), -1) {
+		if match[1] == "kotlin" {
+			deps["org.jetbrains.kotlin."+match[2]] = ""
+		} else {
+			deps[match[2]] = ""
+		}
+	}
+	for _, match := range gradleDependency.FindAllStringSubmatch(string(content), -1) {
+		deps[match[1]+":"+match[2]] = match[3]
+	}
+	// Spring Boot applications often only apply the plugin
+	if _, ok := deps["org.springframework.boot"]; ok {
+		deps["org.springframework.boot:plugin"] = ""
+	}
+
+	language := "java"
+	if strings.HasSuffix(evidence, ".kts") || hasKeyPrefix(deps, "org.jetbrains.kotlin") || exists(dir, "src/main/kotlin") {
+		language = "kotlin"
+	}
+
+	components := []Component{
+		{Kind: KindLanguage, Name: language, Evidence: evidence, Confidence: 0.9},
+		{Kind: KindBuild, Name: "gradle", Evidence: evidence, Confidence: 1},
+	}
+	return append(components, matchFrameworks(jvmFrameworks, deps, nil, evidence)...)
+}
+
+// hasKeyPrefix reports whether a dependency starts with prefix
+func hasKeyPrefix(deps map[string]string, prefix string) bool {
+	for dep := range deps {
+		if strings.HasPrefix(dep, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package detect
+
+func init() {
+	register(Detector{Name: "node", Detect: detectNode})
+}
+
+// nodeFrameworks are checked in order; meta-frameworks come before the
+// libraries they build on so both are reported with the right confidence
+var nodeFrameworks = []frameworkRule{
+	{"next", "nextjs", "web", 0.95},
+	{"nuxt", "nuxt", "web", 0.95},
+	{"@remix-run/react", "remix", "web", 0.95},
+	{"@sveltejs/kit", "sveltekit", "web", 0.95},
+	{"astro", "astro", "web", 0.9},
+	{"gatsby", "gatsby", "web", 0.9},
+	{"expo", "expo", "mobile", 0.95},
+	{"react-native", "react-native", "mobile", 0.9},
+	{"electron", "electron", "desktop", 0.9},
+	{"@nestjs/core", "nestjs", "api", 0.95},
+	{"@angular/core", "angular", "web", 0.9},
+	{"vue", "vue", "web", 0.8},
+	{"svelte", "svelte", "web", 0.8},
+	{"solid-js", "solid", "web", 0.8},
+	{"react", "react", "web", 0.7},
+	{"express", "express", "api", 0.8},
+	{"fastify", "fastify", "api", 0.85},
+	{"koa", "koa", "api", 0.8},
+	{"hono", "hono", "api", 0.8},
+	{"commander", "commander", "cli", 0.5},
+	{"yargs", "yargs", "cli", 0.5},
+}
+
+// nodeLockFiles name the package manager of a Node.js project
+var nodeLockFiles = []struct{ file, manager string }{
+	{"pnpm-lock.yaml", "pnpm"},
+	{"yarn.lock", "yarn"},
+	{"bun.lockb", "bun"},
+	{"package-lock.json", "npm"},
+}
+
+// packageJSON is the part of package.json the detectors read
+type packageJSON struct {
+	Name            string            `json:"name"`
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+	Bin             interface{}       `json:"bin"`
+	Workspaces      interface{}       `json:"workspaces"` // list, or {"packages": list}
+}
+
+func detectNode(dir string) []Component {
+	var pkg packageJSON
+	if !readJSON(dir, "package.json", &pkg) {
+		return nil
+	}
+
+	language := Component{Kind: KindLanguage, Name: "javascript", Evidence: "package.json", Confidence: 0.9}
+	if exists(dir, "tsconfig.json") {
+		language = Component{Kind: KindLanguage, Name: "typescript", Evidence: "tsconfig.json", Confidence: 0.95}
+	} else if version, ok := pkg.DevDependencies["typescript"]; ok {
+		language = Component{Kind: KindLanguage, Name: "typescript", Version: version, Evidence: "package.json", Confidence: 0.85}
+	}
+	// A root manifest that only declares workspaces says little about the language
+	if pkg.Workspaces != nil && len(pkg.Dependencies) == 0 {
+		language.Confidence = 0.5
+	}
+	components := []Component{language}
+
+	for _, lock := range nodeLockFiles {
+		if exists(dir, lock.file) {
+			components = append(components, Component{Kind: KindBuild, Name: lock.manager, Evidence: lock.file, Confidence: 0.95})
+			break
+		}
+	}
+
+	components = append(components, matchFrameworks(nodeFrameworks, pkg.Dependencies, pkg.DevDependencies, "package.json")...)
+	if pkg.Bin != nil {
+		components = append(components, Component{Kind: KindFramework, Name: "node-cli", Type: "cli", Evidence: "package.json", Confidence: 0.6})
+	}
+	return components
+}
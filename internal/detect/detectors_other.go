@@ -0,0 +1,154 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+func init() {
+	register(Detector{Name: "cargo", Detect: detectCargo})
+	register(Detector{Name: "ruby", Detect: detectRuby})
+	register(Detector{Name: "composer", Detect: detectComposer})
+	register(Detector{Name: "pubspec", Detect: detectPubspec})
+}
+
+var rustFrameworks = []frameworkRule{
+	{"actix-web", "actix-web", "api", 0.9},
+	{"axum", "axum", "api", 0.9},
+	{"rocket", "rocket", "api", 0.9},
+	{"warp", "warp", "api", 0.85},
+	{"tauri", "tauri", "desktop", 0.95},
+	{"leptos", "leptos", "web", 0.9},
+	{"yew", "yew", "web", 0.9},
+	{"clap", "clap", "cli", 0.7},
+}
+
+var rubyFrameworks = []frameworkRule{
+	{"rails", "rails", "web", 0.95},
+	{"sinatra", "sinatra", "api", 0.9},
+	{"hanami", "hanami", "web", 0.9},
+	{"thor", "thor", "cli", 0.6},
+}
+
+var phpFrameworks = []frameworkRule{
+	{"laravel/framework", "laravel", "web", 0.95},
+	{"symfony/framework-bundle", "symfony", "web", 0.95},
+	{"slim/slim", "slim", "api", 0.9},
+	{"cakephp/cakephp", "cakephp", "web", 0.9},
+}
+
+var dartFrameworks = []frameworkRule{
+	{"flutter", "flutter", "mobile", 0.95},
+	{"shelf", "shelf", "api", 0.8},
+}
+
+// cargoManifest is the part of Cargo.toml the detectors read
+type cargoManifest struct {
+	Package *struct {
+		Name    string `toml:"name"`
+		Edition string `toml:"edition"`
+	} `toml:"package"`
+	Workspace *struct {
+		Members []string `toml:"members"`
+	} `toml:"workspace"`
+	Dependencies    map[string]interface{} `toml:"dependencies"`
+	DevDependencies map[string]interface{} `toml:"dev-dependencies"`
+}
+
+func detectCargo(dir string) []Component {
+	var manifest cargoManifest
+	if !readTOML(dir, "Cargo.toml", &manifest) {
+		return nil
+	}
+
+	edition := ""
+	if manifest.Package != nil {
+		edition = manifest.Package.Edition
+	}
+	components := []Component{
+		{Kind: KindLanguage, Name: "rust", Version: edition, Evidence: "Cargo.toml", Confidence: 1},
+		{Kind: KindBuild, Name: "cargo", Evidence: "Cargo.toml", Confidence: 1},
+	}
+
+	deps := make(map[string]string)
+	for name, spec := range manifest.Dependencies {
+		deps[name] = poetryVersion(spec)
+	}
+	devDeps := make(map[string]string)
+	for name, spec := range manifest.DevDependencies {
+		devDeps[name] = poetryVersion(spec)
+	}
+	return append(components, matchFrameworks(rustFrameworks, deps, devDeps, "Cargo.toml")...)
+}
+
+var (
+	// gemDeclaration matches gem "name", "version" lines of a Gemfile
+	gemDeclaration = regexp.MustCompile(`(?m)^\s*gem\s+["']([^"']+)["'](?:\s*,\s*["']([^"']+)["'])?`)
+	// rubyVersion matches the ruby "version" line of a Gemfile
+	rubyVersion = regexp.MustCompile(`(?m)^\s*ruby\s+["']([^"']+)["']`)
+)
+
+func detectRuby(dir string) []Component {
+	content, err := os.ReadFile(filepath.Join(dir, "Gemfile"))
+	if err != nil {
+		return nil
+	}
+
+	version := ""
+	if match := rubyVersion.FindSubmatch(content); match != nil {
+		version = string(match[1])
+	}
+	components := []Component{
+		{Kind: KindLanguage, Name: "ruby", Version: version, Evidence: "Gemfile", Confidence: 0.95},
+		{Kind: KindBuild, Name: "bundler", Evidence: "Gemfile", Confidence: 0.95},
+	}
+
+	deps := make(map[string]string)
+	for _, match := range gemDeclaration.FindAllStringSubmatch(string(content), -1) {
+		deps[match[1]] = match[2]
+	}
+	return append(components, matchFrameworks(rubyFrameworks, deps, nil, "Gemfile")...)
+}
+
+func detectComposer(dir string) []Component {
+	var manifest struct {
+		Require    map[string]string `json:"require"`
+		RequireDev map[string]string `json:"require-dev"`
+	}
+	if !readJSON(dir, "composer.json", &manifest) {
+		return nil
+	}
+
+	components := []Component{
+		{Kind: KindLanguage, Name: "php", Version: manifest.Require["php"], Evidence: "composer.json", Confidence: 0.95},
+		{Kind: KindBuild, Name: "composer", Evidence: "composer.json", Confidence: 1},
+	}
+	return append(components, matchFrameworks(phpFrameworks, manifest.Require, manifest.RequireDev, "composer.json")...)
+}
+
+func detectPubspec(dir string) []Component {
+	var manifest struct {
+		Environment     map[string]string      `yaml:"environment"`
+		Dependencies    map[string]interface{} `yaml:"dependencies"`
+		DevDependencies map[string]interface{} `yaml:"dev_dependencies"`
+	}
+	if !readYAML(dir, "pubspec.yaml", &manifest) {
+		return nil
+	}
+
+	components := []Component{
+		{Kind: KindLanguage, Name: "dart", Version: manifest.Environment["sdk"], Evidence: "pubspec.yaml", Confidence: 1},
+		{Kind: KindBuild, Name: "pub", Evidence: "pubspec.yaml", Confidence: 1},
+	}
+
+	deps := make(map[string]string)
+	for name, spec := range manifest.Dependencies {
+		deps[name] = poetryVersion(spec)
+	}
+	devDeps := make(map[string]string)
+	for name, spec := range manifest.DevDependencies {
+		devDeps[name] = poetryVersion(spec)
+	}
+	return append(components, matchFrameworks(dartFrameworks, deps, devDeps, "pubspec.yaml")...)
+}
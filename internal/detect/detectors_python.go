@@ -0,0 +1,165 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+func init() {
+	register(Detector{Name: "python", Detect: detectPython})
+}
+
+var pythonFrameworks = []frameworkRule{
+	{"django", "django", "web", 0.95},
+	{"fastapi", "fastapi", "api", 0.95},
+	{"flask", "flask", "api", 0.9},
+	{"starlette", "starlette", "api", 0.8},
+	{"streamlit", "streamlit", "web", 0.9},
+	{"typer", "typer", "cli", 0.8},
+	{"click", "click", "cli", 0.6},
+}
+
+// requirementName matches the distribution name at the start of a PEP 508 requirement
+var requirementName = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)`)
+
+// pyProject is the part of pyproject.toml the detectors read
+type pyProject struct {
+	Project struct {
+		Name                 string              `toml:"name"`
+		RequiresPython       string              `toml:"requires-python"`
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	} `toml:"project"`
+	Tool struct {
+		Poetry *struct {
+			Name            string                 `toml:"name"`
+			Dependencies    map[string]interface{} `toml:"dependencies"`
+			DevDependencies map[string]interface{} `toml:"dev-dependencies"`
+			Group           map[string]struct {
+				Dependencies map[string]interface{} `toml:"dependencies"`
+			} `toml:"group"`
+		} `toml:"poetry"`
+		PDM *struct{} `toml:"pdm"`
+		UV  *struct{} `toml:"uv"`
+	} `toml:"tool"`
+}
+
+func detectPython(dir string) []Component {
+	deps := make(map[string]string)
+	devDeps := make(map[string]string)
+	var components []Component
+	evidence := ""
+
+	var project pyProject
+	if readTOML(dir, "pyproject.toml", &project) {
+		evidence = "pyproject.toml"
+		addRequirements(deps, project.Project.Dependencies)
+		for _, group := range project.Project.OptionalDependencies {
+			addRequirements(devDeps, group)
+		}
+
+		if poetry := project.Tool.Poetry; poetry != nil {
+			components = append(components, Component{Kind: KindBuild, Name: "poetry", Evidence: evidence, Confidence: 0.95})
+			for name, spec := range poetry.Dependencies {
+				deps[normalizeRequirement(name)] = poetryVersion(spec)
+			}
+			for name, spec := range poetry.DevDependencies {
+				devDeps[normalizeRequirement(name)] = poetryVersion(spec)
+			}
+			for _, group := range poetry.Group {
+				for name, spec := range group.Dependencies {
+					devDeps[normalizeRequirement(name)] = poetryVersion(spec)
+				}
+			}
+		}
+		if project.Tool.PDM != nil {
+			components = append(components, Component{Kind: KindBuild, Name: "pdm", Evidence: evidence, Confidence: 0.9})
+		}
+		if project.Tool.UV != nil || exists(dir, "uv.lock") {
+			components = append(components, Component{Kind: KindBuild, Name: "uv", Evidence: evidence, Confidence: 0.9})
+		}
+	}
+
+	if content, err := os.ReadFile(filepath.Join(dir, "requirements.txt")); err == nil {
+		if evidence == "" {
+			evidence = "requirements.txt"
+		}
+		addRequirements(deps, strings.Split(string(content), "\n"))
+		components = append(components, Component{Kind: KindBuild, Name: "pip", Evidence: "requirements.txt", Confidence: 0.9})
+	}
+
+	var pipfile struct {
+		Packages    map[string]interface{} `toml:"packages"`
+		DevPackages map[string]interface{} `toml:"dev-packages"`
+	}
+	if readTOML(dir, "Pipfile", &pipfile) {
+		if evidence == "" {
+			evidence = "Pipfile"
+		}
+		for name, spec := range pipfile.Packages {
+			deps[normalizeRequirement(name)] = poetryVersion(spec)
+		}
+		for name, spec := range pipfile.DevPackages {
+			devDeps[normalizeRequirement(name)] = poetryVersion(spec)
+		}
+		components = append(components, Component{Kind: KindBuild, Name: "pipenv", Evidence: "Pipfile", Confidence: 0.95})
+	}
+
+	if evidence == "" && exists(dir, "setup.py") {
+		evidence = "setup.py"
+	}
+	if evidence == "" {
+		return nil
+	}
+
+	// Django projects have a manage.py even without declared dependencies
+	if _, ok := deps["django"]; !ok && exists(dir, "manage.py") {
+		deps["django"] = ""
+	}
+
+	components = append([]Component{{
+		Kind:       KindLanguage,
+		Name:       "python",
+		Version:    project.Project.RequiresPython,
+		Evidence:   evidence,
+		Confidence: 0.95,
+	}}, components...)
+	return append(components, matchFrameworks(pythonFrameworks, deps, devDeps, evidence)...)
+}
+
+// addRequirements adds PEP 508 requirement lines to deps
+func addRequirements(deps map[string]string, requirements []string) {
+	for _, line := range requirements {
+		line = strings.TrimSpace(strings.SplitN(line, "#", 2)[0])
+		if line == "" || strings.HasPrefix(line, "-") {
+			continue
+		}
+		match := requirementName.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		deps[normalizeRequirement(match[1])] = strings.TrimSpace(line[len(match[0]):])
+	}
+}
+
+// normalizeRequirement normalizes a distribution name as PEP 503 does
+func normalizeRequirement(name string) string {
+	name = strings.ToLower(name)
+	return strings.NewReplacer("_", "-", ".", "-").Replace(name)
+}
+
+// poetryVersion returns the version of a Poetry or Pipfile dependency, which
+// is a string or a table with a version key
+func poetryVersion(spec interface{}) string {
+	switch v := spec.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		if version, ok := v["version"].(string); ok {
+			return version
+		}
+	}
+	return ""
+}
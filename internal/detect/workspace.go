@@ -0,0 +1,300 @@
+package detect
+
+import (
+	"encoding/xml"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+func init() {
+	register(Detector{Name: "workspace", Detect: detectWorkspace})
+}
+
+// maxWorkspaceDepth limits how deep "**" workspace patterns and Nx project
+// discovery descend
+const maxWorkspaceDepth = 4
+
+// packageManifests mark a directory as a package
+var packageManifests = []string{
+	"package.json", "go.mod", "Cargo.toml", "pyproject.toml", "setup.py", "requirements.txt",
+	"pom.xml", "build.gradle", "build.gradle.kts", "composer.json", "Gemfile", "pubspec.yaml", "project.json",
+}
+
+var (
+	// goWorkUse matches the directories of use directives in go.work
+	goWorkUse = regexp.MustCompile(`(?m)^\s*(?:use\s+)?(\.[^\s()]*)\s*$`)
+	// gradleInclude matches the projects of include directives in settings.gradle
+	gradleInclude = regexp.MustCompile(`["']:?([\w.:-]+)["']`)
+)
+
+func detectWorkspace(dir string) []Component {
+	var components []Component
+	add := func(name, evidence string) {
+		components = append(components, Component{Kind: KindWorkspace, Name: name, Evidence: evidence, Confidence: 1})
+	}
+
+	if exists(dir, "pnpm-workspace.yaml") {
+		add("pnpm-workspaces", "pnpm-workspace.yaml")
+	} else if len(npmWorkspaces(dir)) > 0 {
+		add("npm-workspaces", "package.json")
+	}
+	if exists(dir, "go.work") {
+		add("go-workspace", "go.work")
+	}
+	if exists(dir, "nx.json") {
+		add("nx", "nx.json")
+	}
+	if exists(dir, "turbo.json") {
+		add("turbo", "turbo.json")
+	}
+	if exists(dir, "lerna.json") {
+		add("lerna", "lerna.json")
+	}
+	var cargo cargoManifest
+	if readTOML(dir, "Cargo.toml", &cargo) && cargo.Workspace != nil {
+		add("cargo-workspace", "Cargo.toml")
+	}
+	return components
+}
+
+// discoverPackages lists the members of the workspaces declared in root
+func discoverPackages(root string) []Package {
+	var patterns []string
+
+	var pnpm struct {
+		Packages []string `yaml:"packages"`
+	}
+	if readYAML(root, "pnpm-workspace.yaml", &pnpm) {
+		patterns = append(patterns, pnpm.Packages...)
+	}
+	patterns = append(patterns, npmWorkspaces(root)...)
+
+	var lerna struct {
+		Packages []string `json:"packages"`
+	}
+	if readJSON(root, "lerna.json", &lerna) {
+		patterns = append(patterns, lerna.Packages...)
+	}
+
+	if content, err := os.ReadFile(filepath.Join(root, "go.work")); err == nil {
+		for _, match := range goWorkUse.FindAllStringSubmatch(string(content), -1) {
+			patterns = append(patterns, match[1])
+		}
+	}
+
+	var cargo cargoManifest
+	if readTOML(root, "Cargo.toml", &cargo) && cargo.Workspace != nil {
+		patterns = append(patterns, cargo.Workspace.Members...)
+	}
+
+	if content, err := os.ReadFile(filepath.Join(root, "pom.xml")); err == nil {
+		var pom mavenPOM
+		if xml.Unmarshal(content, &pom) == nil {
+			patterns = append(patterns, pom.Modules...)
+		}
+	}
+
+	for _, name := range []string{"settings.gradle.kts", "settings.gradle"} {
+		content, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			if !strings.HasPrefix(strings.TrimSpace(line), "include") {
+				continue
+			}
+			for _, match := range gradleInclude.FindAllStringSubmatch(line, -1) {
+				patterns = append(patterns, strings.ReplaceAll(match[1], ":", "/"))
+			}
+		}
+		break
+	}
+
+	dirs := expandWorkspace(root, patterns)
+
+	// Nx projects are found by their project.json when no package manager lists them
+	if len(dirs) == 0 && exists(root, "nx.json") {
+		dirs = findProjectFiles(root, "project.json")
+	}
+
+	packages := make([]Package, 0, len(dirs))
+	for _, dir := range dirs {
+		packages = append(packages, Package{Name: packageName(filepath.Join(root, dir), dir), Path: dir})
+	}
+	return packages
+}
+
+// npmWorkspaces returns the workspace patterns of package.json, which are a
+// list or an object with a packages list
+func npmWorkspaces(dir string) []string {
+	var pkg packageJSON
+	if !readJSON(dir, "package.json", &pkg) {
+		return nil
+	}
+
+	var list []interface{}
+	switch workspaces := pkg.Workspaces.(type) {
+	case []interface{}:
+		list = workspaces
+	case map[string]interface{}:
+		list, _ = workspaces["packages"].([]interface{})
+	}
+
+	var patterns []string
+	for _, item := range list {
+		if pattern, ok := item.(string); ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// expandWorkspace expands workspace patterns to the package directories they
+// match, relative to root. Patterns starting with "!" exclude directories.
+func expandWorkspace(root string, patterns []string) []string {
+	matched := make(map[string]bool)
+	var excludes []string
+
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if strings.HasPrefix(pattern, "!") {
+			excludes = append(excludes, filepath.ToSlash(filepath.Clean(strings.TrimPrefix(pattern, "!"))))
+			continue
+		}
+		pattern = filepath.ToSlash(filepath.Clean(pattern))
+		if pattern == "." || strings.HasPrefix(pattern, "../") {
+			continue
+		}
+
+		if i := strings.Index(pattern, "**"); i != -1 {
+			for _, dir := range findManifestDirs(root, strings.TrimSuffix(pattern[:i], "/")) {
+				matched[dir] = true
+			}
+			continue
+		}
+
+		matches, _ := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && info.IsDir() && hasManifest(match) {
+				if rel, err := filepath.Rel(root, match); err == nil {
+					matched[filepath.ToSlash(rel)] = true
+				}
+			}
+		}
+	}
+
+	var dirs []string
+	for dir := range matched {
+		excluded := false
+		for _, exclude := range excludes {
+			if ok, _ := filepath.Match(exclude, dir); ok || strings.HasPrefix(dir, exclude+"/") {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// findManifestDirs finds the package directories below base
+func findManifestDirs(root, base string) []string {
+	var dirs []string
+	walkPackages(root, base, func(rel string) {
+		if hasManifest(filepath.Join(root, rel)) {
+			dirs = append(dirs, rel)
+		}
+	})
+	return dirs
+}
+
+// findProjectFiles finds the directories below root containing a file
+func findProjectFiles(root, name string) []string {
+	var dirs []string
+	walkPackages(root, "", func(rel string) {
+		if exists(filepath.Join(root, rel), name) {
+			dirs = append(dirs, rel)
+		}
+	})
+	sort.Strings(dirs)
+	return dirs
+}
+
+// walkPackages calls visit for each directory below root/base, skipping hidden
+// and dependency directories
+func walkPackages(root, base string, visit func(rel string)) {
+	start := filepath.Join(root, filepath.FromSlash(base))
+	filepath.WalkDir(start, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, path)
+		rel = filepath.ToSlash(rel)
+		if path == start {
+			return nil
+		}
+		name := d.Name()
+		if strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "target" || name == "dist" {
+			return filepath.SkipDir
+		}
+		if strings.Count(rel, "/")-strings.Count(base, "/") >= maxWorkspaceDepth {
+			return filepath.SkipDir
+		}
+		visit(rel)
+		return nil
+	})
+}
+
+// hasManifest reports whether dir contains a package manifest
+func hasManifest(dir string) bool {
+	for _, name := range packageManifests {
+		if exists(dir, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// packageName returns the name a package declares in its manifest, or its path
+func packageName(dir, rel string) string {
+	var pkg packageJSON
+	if readJSON(dir, "package.json", &pkg) && pkg.Name != "" {
+		return pkg.Name
+	}
+	var nx struct {
+		Name string `json:"name"`
+	}
+	if readJSON(dir, "project.json", &nx) && nx.Name != "" {
+		return nx.Name
+	}
+	if mod, ok := parseGoMod(dir); ok && mod.Path != "" {
+		return mod.Path
+	}
+	var cargo cargoManifest
+	if readTOML(dir, "Cargo.toml", &cargo) && cargo.Package != nil && cargo.Package.Name != "" {
+		return cargo.Package.Name
+	}
+	var project pyProject
+	if readTOML(dir, "pyproject.toml", &project) {
+		if project.Project.Name != "" {
+			return project.Project.Name
+		}
+		if project.Tool.Poetry != nil && project.Tool.Poetry.Name != "" {
+			return project.Tool.Poetry.Name
+		}
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "pom.xml")); err == nil {
+		var pom mavenPOM
+		if xml.Unmarshal(content, &pom) == nil && pom.ArtifactID != "" {
+			return pom.ArtifactID
+		}
+	}
+	return rel
+}
@@ -2,9 +2,11 @@ package docs
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"text/template"
 	"time"
 
@@ -59,7 +61,17 @@ func (dg *DocGenerator) GenerateProgressReport(doc *types.ProgressDocument) erro
 
 // renderProgressTemplate renders the progress report template
 func (dg *DocGenerator) renderProgressTemplate(doc *types.ProgressDocument) (string, error) {
-	tmpl := template.New("progress")
+	tmpl := template.New("progress").Funcs(template.FuncMap{
+		"signed": func(v interface{}) string {
+			switch n := v.(type) {
+			case int:
+				return fmt.Sprintf("%+d", n)
+			case float64:
+				return fmt.Sprintf("%+.1f", n)
+			}
+			return fmt.Sprint(v)
+		},
+	})
 	tmpl, err := tmpl.Parse(progressTemplate)
 	if err != nil {
 		return "", err
@@ -110,9 +122,31 @@ func (dg *DocGenerator) GenerateREADME(projectName, description string, features
 	return nil
 }
 
-// UpdateMetrics updates project metrics in the documentation
-func (dg *DocGenerator) UpdateMetrics(metrics *ProjectMetrics) error {
-	// For now, we'll just log the metrics
+// metricsHistoryFile is the time series of collected metrics
+const metricsHistoryFile = "metrics.json"
+
+// UpdateMetrics appends the metrics to the stored time series
+func (dg *DocGenerator) UpdateMetrics(metrics *types.ProjectMetrics) error {
+	history, err := dg.LoadMetricsHistory()
+	if err != nil {
+		return err
+	}
+	history = append(history, *metrics)
+
+	if err := os.MkdirAll(dg.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	content, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode metrics: %w", err)
+	}
+
+	historyPath := filepath.Join(dg.outputDir, metricsHistoryFile)
+	if err := os.WriteFile(historyPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+
 	dg.logger.Info().
 		Int("lines_of_code", metrics.LinesOfCode).
 		Int("commit_count", metrics.CommitCount).
@@ -122,12 +156,53 @@ func (dg *DocGenerator) UpdateMetrics(metrics *ProjectMetrics) error {
 	return nil
 }
 
-// ProjectMetrics represents project metrics
-type ProjectMetrics struct {
-	LinesOfCode  int
-	CommitCount  int
-	TestCoverage float64
-	BuildTime    time.Duration
+// LoadMetricsHistory loads the stored metrics time series, oldest first
+func (dg *DocGenerator) LoadMetricsHistory() ([]types.ProjectMetrics, error) {
+	content, err := os.ReadFile(filepath.Join(dg.outputDir, metricsHistoryFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read metrics: %w", err)
+	}
+
+	var history []types.ProjectMetrics
+	if err := json.Unmarshal(content, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse metrics: %w", err)
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].Timestamp.Before(history[j].Timestamp)
+	})
+	return history, nil
+}
+
+// LatestMetrics returns the most recent stored metrics, or nil if none exist
+func (dg *DocGenerator) LatestMetrics() (*types.ProjectMetrics, error) {
+	history, err := dg.LoadMetricsHistory()
+	if err != nil || len(history) == 0 {
+		return nil, err
+	}
+	return &history[len(history)-1], nil
+}
+
+// ApplyMetrics fills the metrics of a progress document and the deltas to the previous run
+func ApplyMetrics(doc *types.ProgressDocument, current, previous *types.ProjectMetrics) {
+	if current == nil {
+		return
+	}
+
+	doc.LinesOfCode = current.LinesOfCode
+	doc.LinesByLanguage = current.LinesByLanguage
+	doc.CommitCount = current.CommitCount
+	doc.TestCoverage = current.TestCoverage
+
+	if previous != nil {
+		doc.HasPrevious = true
+		doc.LinesOfCodeDelta = current.LinesOfCode - previous.LinesOfCode
+		doc.CommitCountDelta = current.CommitCount - previous.CommitCount
+		doc.TestCoverageDelta = current.TestCoverage - previous.TestCoverage
+	}
 }
 
 // Template definitions
@@ -154,9 +229,13 @@ const progressTemplate = `# 프로젝트 진행 상황 보고서
 
 ## 📈 메트릭
 - 전체 진행률: {{printf "%.1f" .Progress}}%
-- 코드 라인: {{.LinesOfCode}}
-- 커밋 수: {{.CommitCount}}
-- 테스트 커버리지: {{printf "%.1f" .TestCoverage}}%
+- 코드 라인: {{.LinesOfCode}}{{if .HasPrevious}} ({{signed .LinesOfCodeDelta}}){{end}}
+- 커밋 수: {{.CommitCount}}{{if .HasPrevious}} ({{signed .CommitCountDelta}}){{end}}
+- 테스트 커버리지: {{printf "%.1f" .TestCoverage}}%{{if .HasPrevious}} ({{signed .TestCoverageDelta}}%p){{end}}
+{{if .LinesByLanguage}}
+### 언어별 코드 라인
+{{range $lang, $lines := .LinesByLanguage}}- {{$lang}}: {{$lines}}
+{{end}}{{end}}
 
 ## 🔑 API 키 상태
 {{range .APIKeys}}
@@ -0,0 +1,98 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// requestTimeout limits a single forge API request
+const requestTimeout = 30 * time.Second
+
+// apiClient sends JSON requests to a forge REST API
+type apiClient struct {
+	baseURL string
+	header  http.Header
+	http    *http.Client
+}
+
+// newAPIClient creates a client sending header with every request
+func newAPIClient(baseURL string, header http.Header) *apiClient {
+	return &apiClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		header:  header,
+		http:    &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// do sends a request with a JSON body and decodes a JSON response into out when set
+func (c *apiClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, apiErrorMessage(data))
+	}
+
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return nil
+}
+
+// apiErrorMessage extracts the message of a forge error response
+func apiErrorMessage(data []byte) string {
+	var response struct {
+		Message interface{} `json:"message"`
+		Error   string      `json:"error"`
+	}
+	if err := json.Unmarshal(data, &response); err == nil {
+		if response.Message != nil {
+			return fmt.Sprint(response.Message)
+		}
+		if response.Error != "" {
+			return response.Error
+		}
+	}
+
+	message := strings.TrimSpace(string(data))
+	if len(message) > 200 {
+		message = message[:200] + "..."
+	}
+	return message
+}
@@ -0,0 +1,150 @@
+package forge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Provider names a forge implementation
+type Provider string
+
+const (
+	ProviderAuto   Provider = "auto" // Detected from the remote host
+	ProviderGitHub Provider = "github"
+	ProviderGitLab Provider = "gitlab"
+)
+
+// ErrNoToken is returned when no access token is available for the forge API
+var ErrNoToken = errors.New("no forge access token")
+
+// Forge opens pull requests on a code hosting service
+type Forge interface {
+	// Name returns the name of the forge
+	Name() string
+	// FindPullRequest returns the open pull request from head into base, or nil
+	FindPullRequest(ctx context.Context, head, base string) (*PullRequestInfo, error)
+	// CreatePullRequest opens a pull request and applies its labels
+	CreatePullRequest(ctx context.Context, pr *PullRequest) (*PullRequestInfo, error)
+}
+
+// PullRequest represents a pull or merge request to open
+type PullRequest struct {
+	Title  string
+	Body   string
+	Head   string
+	Base   string
+	Labels []string
+	Draft  bool
+}
+
+// PullRequestInfo represents an opened pull or merge request
+type PullRequestInfo struct {
+	Number int
+	URL    string
+	// Existing is set when the pull request was already open
+	Existing bool
+}
+
+// Config configures access to a forge
+type Config struct {
+	Provider Provider
+	// APIURL overrides the API base URL derived from the remote
+	APIURL string
+	Token  string
+}
+
+// Repository identifies a repository on a forge
+type Repository struct {
+	Host string
+	// Path is the owner and name, or the full group path on GitLab
+	Path string
+}
+
+// New creates the forge hosting the repository behind a remote URL
+func New(cfg Config, remoteURL string) (Forge, error) {
+	repo, err := ParseRemoteURL(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Token == "" {
+		return nil, ErrNoToken
+	}
+
+	provider := cfg.Provider
+	if provider == "" || provider == ProviderAuto {
+		provider = detectProvider(repo.Host)
+	}
+
+	switch provider {
+	case ProviderGitHub:
+		apiURL := cfg.APIURL
+		if apiURL == "" {
+			apiURL = "https://api.github.com"
+			if repo.Host != "github.com" {
+				apiURL = "https://" + repo.Host + "/api/v3" // GitHub Enterprise
+			}
+		}
+		return NewGitHub(apiURL, repo.Path, cfg.Token)
+
+	case ProviderGitLab:
+		apiURL := cfg.APIURL
+		if apiURL == "" {
+			apiURL = "https://" + repo.Host + "/api/v4"
+		}
+		return NewGitLab(apiURL, repo.Path, cfg.Token), nil
+	}
+
+	return nil, fmt.Errorf("unknown forge provider: %s", provider)
+}
+
+// TokenFromEnv reads the access token from the first set environment variable
+func TokenFromEnv(names ...string) string {
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// ParseRemoteURL extracts the host and repository path from a git remote URL.
+// It accepts https://host/owner/repo.git, ssh://git@host/owner/repo.git and git@host:owner/repo.git.
+func ParseRemoteURL(remote string) (*Repository, error) {
+	var host, path string
+
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote URL %q: %w", remote, err)
+		}
+		host, path = u.Hostname(), u.Path
+	} else if at := strings.Index(remote, "@"); at >= 0 && strings.Contains(remote[at:], ":") {
+		// scp-like syntax: user@host:owner/repo.git
+		host, path, _ = strings.Cut(remote[at+1:], ":")
+	} else {
+		return nil, fmt.Errorf("remote %q is not hosted on a forge", remote)
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || !strings.Contains(path, "/") {
+		return nil, fmt.Errorf("remote %q does not name an owner and repository", remote)
+	}
+
+	return &Repository{Host: host, Path: path}, nil
+}
+
+// detectProvider guesses the forge from its host name
+func detectProvider(host string) Provider {
+	if strings.Contains(host, "gitlab") {
+		return ProviderGitLab
+	}
+	return ProviderGitHub
+}
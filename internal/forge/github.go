@@ -0,0 +1,97 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GitHub opens pull requests through the GitHub REST API
+type GitHub struct {
+	client *apiClient
+	owner  string
+	repo   string
+}
+
+// NewGitHub creates a GitHub forge for an owner/repo path
+func NewGitHub(apiURL, path, token string) (*GitHub, error) {
+	owner, repo, ok := strings.Cut(path, "/")
+	if !ok || strings.Contains(repo, "/") {
+		return nil, fmt.Errorf("invalid GitHub repository %q", path)
+	}
+
+	return &GitHub{
+		client: newAPIClient(apiURL, http.Header{
+			"Authorization":        {"Bearer " + token},
+			"Accept":               {"application/vnd.github+json"},
+			"X-GitHub-Api-Version": {"2022-11-28"},
+		}),
+		owner: owner,
+		repo:  repo,
+	}, nil
+}
+
+// Name returns the name of the forge
+func (g *GitHub) Name() string {
+	return "GitHub"
+}
+
+// githubPull is the part of a GitHub pull request the forge reads
+type githubPull struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+}
+
+// FindPullRequest returns the open pull request from head into base, or nil
+func (g *GitHub) FindPullRequest(ctx context.Context, head, base string) (*PullRequestInfo, error) {
+	query := url.Values{
+		"state": {"open"},
+		"head":  {g.owner + ":" + head},
+		"base":  {base},
+	}
+
+	var pulls []githubPull
+	if err := g.client.do(ctx, http.MethodGet, g.repoPath("pulls")+"?"+query.Encode(), nil, &pulls); err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	if len(pulls) == 0 {
+		return nil, nil
+	}
+
+	return &PullRequestInfo{Number: pulls[0].Number, URL: pulls[0].HTMLURL, Existing: true}, nil
+}
+
+// CreatePullRequest opens a pull request and applies its labels
+func (g *GitHub) CreatePullRequest(ctx context.Context, pr *PullRequest) (*PullRequestInfo, error) {
+	request := map[string]interface{}{
+		"title": pr.Title,
+		"body":  pr.Body,
+		"head":  pr.Head,
+		"base":  pr.Base,
+		"draft": pr.Draft,
+	}
+
+	var pull githubPull
+	if err := g.client.do(ctx, http.MethodPost, g.repoPath("pulls"), request, &pull); err != nil {
+		return nil, fmt.Errorf("failed to create pull request: %w", err)
+	}
+	info := &PullRequestInfo{Number: pull.Number, URL: pull.HTMLURL}
+
+	// Pull requests share labels with issues
+	if len(pr.Labels) > 0 {
+		labels := map[string]interface{}{"labels": pr.Labels}
+		path := g.repoPath(fmt.Sprintf("issues/%d/labels", pull.Number))
+		if err := g.client.do(ctx, http.MethodPost, path, labels, nil); err != nil {
+			return info, fmt.Errorf("failed to label pull request: %w", err)
+		}
+	}
+
+	return info, nil
+}
+
+// repoPath returns the API path of a repository resource
+func (g *GitHub) repoPath(resource string) string {
+	return fmt.Sprintf("/repos/%s/%s/%s", url.PathEscape(g.owner), url.PathEscape(g.repo), resource)
+}
@@ -0,0 +1,174 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGitHub serves the part of the GitHub REST API the forge uses for one
+// repository, keeping its pull requests in memory
+type fakeGitHub struct {
+	t     *testing.T
+	mu    sync.Mutex
+	pulls []map[string]interface{}
+	// labels maps pull request numbers to their labels
+	labels map[string][]string
+	// failLabels makes labeling fail
+	failLabels bool
+}
+
+func newFakeGitHub(t *testing.T) (*fakeGitHub, *GitHub) {
+	t.Helper()
+	fake := &fakeGitHub{t: t, labels: make(map[string][]string)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	g, err := NewGitHub(server.URL, "owner/repo", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fake, g
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Bad credentials"})
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/owner/repo/pulls":
+		query := r.URL.Query()
+		if query.Get("state") != "open" {
+			f.t.Errorf("pull requests listed with state %q", query.Get("state"))
+		}
+		matching := []map[string]interface{}{}
+		for _, pull := range f.pulls {
+			if "owner:"+pull["head"].(string) == query.Get("head") && pull["base"] == query.Get("base") {
+				matching = append(matching, pull)
+			}
+		}
+		json.NewEncoder(w).Encode(matching)
+
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/owner/repo/pulls":
+		var pull map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&pull); err != nil {
+			f.t.Errorf("invalid create request: %v", err)
+		}
+		number := len(f.pulls) + 1
+		pull["number"] = number
+		pull["html_url"] = "https://github.com/owner/repo/pull/" + strconv.Itoa(number)
+		f.pulls = append(f.pulls, pull)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(pull)
+
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/repos/owner/repo/issues/"):
+		if f.failLabels {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]string{"message": "Validation Failed"})
+			return
+		}
+		number := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/repos/owner/repo/issues/"), "/labels")
+		var body struct {
+			Labels []string `json:"labels"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		f.labels[number] = body.Labels
+		json.NewEncoder(w).Encode([]interface{}{})
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Not Found"})
+	}
+}
+
+func TestGitHubCreatePullRequest(t *testing.T) {
+	fake, g := newFakeGitHub(t)
+	ctx := context.Background()
+
+	info, err := g.CreatePullRequest(ctx, &PullRequest{
+		Title:  "feat: login",
+		Body:   "## Summary",
+		Head:   "claude-auto/login",
+		Base:   "main",
+		Labels: []string{"backend", "claude-auto"},
+		Draft:  true,
+	})
+	if err != nil {
+		t.Fatalf("CreatePullRequest: %v", err)
+	}
+	want := &PullRequestInfo{Number: 1, URL: "https://github.com/owner/repo/pull/1"}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("CreatePullRequest = %+v, want %+v", info, want)
+	}
+
+	pull := fake.pulls[0]
+	if pull["title"] != "feat: login" || pull["body"] != "## Summary" || pull["draft"] != true {
+		t.Errorf("pull request sent as %v", pull)
+	}
+	if got := fake.labels["1"]; !reflect.DeepEqual(got, []string{"backend", "claude-auto"}) {
+		t.Errorf("labels = %q", got)
+	}
+}
+
+func TestGitHubFindPullRequest(t *testing.T) {
+	_, g := newFakeGitHub(t)
+	ctx := context.Background()
+
+	existing, err := g.FindPullRequest(ctx, "claude-auto/login", "main")
+	if err != nil || existing != nil {
+		t.Fatalf("FindPullRequest before creating = %+v, %v, want nil", existing, err)
+	}
+
+	if _, err := g.CreatePullRequest(ctx, &PullRequest{Title: "feat: login", Head: "claude-auto/login", Base: "main"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second run pushing to the same branch updates the open pull request
+	existing, err = g.FindPullRequest(ctx, "claude-auto/login", "main")
+	if err != nil {
+		t.Fatalf("FindPullRequest: %v", err)
+	}
+	want := &PullRequestInfo{Number: 1, URL: "https://github.com/owner/repo/pull/1", Existing: true}
+	if !reflect.DeepEqual(existing, want) {
+		t.Errorf("FindPullRequest = %+v, want %+v", existing, want)
+	}
+
+	if other, err := g.FindPullRequest(ctx, "claude-auto/login", "develop"); err != nil || other != nil {
+		t.Errorf("FindPullRequest into another base = %+v, %v, want nil", other, err)
+	}
+}
+
+func TestGitHubErrors(t *testing.T) {
+	fake, g := newFakeGitHub(t)
+	ctx := context.Background()
+
+	// Labeling fails after the pull request was opened, which is still reported
+	fake.failLabels = true
+	info, err := g.CreatePullRequest(ctx, &PullRequest{Title: "feat: x", Head: "h", Base: "main", Labels: []string{"claude-auto"}})
+	if err == nil || !strings.Contains(err.Error(), "Validation Failed") {
+		t.Errorf("CreatePullRequest with failing labels = %v, want the API message", err)
+	}
+	if info == nil || info.Number != 1 {
+		t.Errorf("CreatePullRequest with failing labels = %+v, want the opened pull request", info)
+	}
+
+	unauthorized, err := NewGitHub(g.client.baseURL, "owner/repo", "wrong")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := unauthorized.FindPullRequest(ctx, "h", "main"); err == nil || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("FindPullRequest with a bad token = %v, want the API message", err)
+	}
+}
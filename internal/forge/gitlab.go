@@ -0,0 +1,82 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GitLab opens merge requests through the GitLab REST API
+type GitLab struct {
+	client  *apiClient
+	project string
+}
+
+// NewGitLab creates a GitLab forge for a project path such as group/subgroup/project
+func NewGitLab(apiURL, path, token string) *GitLab {
+	return &GitLab{
+		client:  newAPIClient(apiURL, http.Header{"PRIVATE-TOKEN": {token}}),
+		project: path,
+	}
+}
+
+// Name returns the name of the forge
+func (g *GitLab) Name() string {
+	return "GitLab"
+}
+
+// gitlabMergeRequest is the part of a GitLab merge request the forge reads
+type gitlabMergeRequest struct {
+	IID    int    `json:"iid"`
+	WebURL string `json:"web_url"`
+}
+
+// FindPullRequest returns the open merge request from head into base, or nil
+func (g *GitLab) FindPullRequest(ctx context.Context, head, base string) (*PullRequestInfo, error) {
+	query := url.Values{
+		"state":         {"opened"},
+		"source_branch": {head},
+		"target_branch": {base},
+	}
+
+	var requests []gitlabMergeRequest
+	if err := g.client.do(ctx, http.MethodGet, g.projectPath("merge_requests")+"?"+query.Encode(), nil, &requests); err != nil {
+		return nil, fmt.Errorf("failed to list merge requests: %w", err)
+	}
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	return &PullRequestInfo{Number: requests[0].IID, URL: requests[0].WebURL, Existing: true}, nil
+}
+
+// CreatePullRequest opens a merge request with its labels
+func (g *GitLab) CreatePullRequest(ctx context.Context, pr *PullRequest) (*PullRequestInfo, error) {
+	title := pr.Title
+	if pr.Draft {
+		title = "Draft: " + title
+	}
+
+	request := map[string]interface{}{
+		"title":                title,
+		"description":          pr.Body,
+		"source_branch":        pr.Head,
+		"target_branch":        pr.Base,
+		"labels":               strings.Join(pr.Labels, ","),
+		"remove_source_branch": true,
+	}
+
+	var mr gitlabMergeRequest
+	if err := g.client.do(ctx, http.MethodPost, g.projectPath("merge_requests"), request, &mr); err != nil {
+		return nil, fmt.Errorf("failed to create merge request: %w", err)
+	}
+
+	return &PullRequestInfo{Number: mr.IID, URL: mr.WebURL}, nil
+}
+
+// projectPath returns the API path of a project resource
+func (g *GitLab) projectPath(resource string) string {
+	return fmt.Sprintf("/projects/%s/%s", url.PathEscape(g.project), resource)
+}
@@ -0,0 +1,165 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGitLab serves the part of the GitLab REST API the forge uses for one
+// project, keeping its merge requests in memory
+type fakeGitLab struct {
+	t        *testing.T
+	mu       sync.Mutex
+	requests []map[string]interface{}
+}
+
+// gitlabProjectPath is the escaped API path of the group/subgroup/project test project
+const gitlabProjectPath = "/projects/group%2Fsubgroup%2Fproject/merge_requests"
+
+func newFakeGitLab(t *testing.T) (*fakeGitLab, *GitLab) {
+	t.Helper()
+	fake := &fakeGitLab{t: t}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, NewGitLab(server.URL, "group/subgroup/project", "secret")
+}
+
+func (f *fakeGitLab) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"message": "401 Unauthorized"})
+		return
+	}
+	// Subgroups must stay one escaped path segment
+	if r.URL.EscapedPath() != gitlabProjectPath {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "404 Project Not Found"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		if query.Get("state") != "opened" {
+			f.t.Errorf("merge requests listed with state %q", query.Get("state"))
+		}
+		matching := []map[string]interface{}{}
+		for _, mr := range f.requests {
+			if mr["source_branch"] == query.Get("source_branch") && mr["target_branch"] == query.Get("target_branch") {
+				matching = append(matching, mr)
+			}
+		}
+		json.NewEncoder(w).Encode(matching)
+
+	case http.MethodPost:
+		var mr map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&mr); err != nil {
+			f.t.Errorf("invalid create request: %v", err)
+		}
+		if mr["source_branch"] == mr["target_branch"] {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string][]string{"message": {"source and target branch are the same"}})
+			return
+		}
+		iid := len(f.requests) + 1
+		mr["iid"] = iid
+		mr["web_url"] = "https://gitlab.com/group/subgroup/project/-/merge_requests/" + strconv.Itoa(iid)
+		f.requests = append(f.requests, mr)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(mr)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestGitLabCreatePullRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		draft     bool
+		wantTitle string
+	}{
+		{"ready", false, "feat: login"},
+		{"draft", true, "Draft: feat: login"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, g := newFakeGitLab(t)
+
+			info, err := g.CreatePullRequest(context.Background(), &PullRequest{
+				Title:  "feat: login",
+				Body:   "## Summary",
+				Head:   "claude-auto/login",
+				Base:   "main",
+				Labels: []string{"backend", "claude-auto"},
+				Draft:  tt.draft,
+			})
+			if err != nil {
+				t.Fatalf("CreatePullRequest: %v", err)
+			}
+			want := &PullRequestInfo{Number: 1, URL: "https://gitlab.com/group/subgroup/project/-/merge_requests/1"}
+			if !reflect.DeepEqual(info, want) {
+				t.Errorf("CreatePullRequest = %+v, want %+v", info, want)
+			}
+
+			mr := fake.requests[0]
+			if mr["title"] != tt.wantTitle {
+				t.Errorf("title = %v, want %q", mr["title"], tt.wantTitle)
+			}
+			if mr["description"] != "## Summary" || mr["labels"] != "backend,claude-auto" || mr["remove_source_branch"] != true {
+				t.Errorf("merge request sent as %v", mr)
+			}
+		})
+	}
+}
+
+func TestGitLabFindPullRequest(t *testing.T) {
+	_, g := newFakeGitLab(t)
+	ctx := context.Background()
+
+	existing, err := g.FindPullRequest(ctx, "claude-auto/login", "main")
+	if err != nil || existing != nil {
+		t.Fatalf("FindPullRequest before creating = %+v, %v, want nil", existing, err)
+	}
+
+	if _, err := g.CreatePullRequest(ctx, &PullRequest{Title: "feat: login", Head: "claude-auto/login", Base: "main"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second run pushing to the same branch updates the open merge request
+	existing, err = g.FindPullRequest(ctx, "claude-auto/login", "main")
+	if err != nil {
+		t.Fatalf("FindPullRequest: %v", err)
+	}
+	want := &PullRequestInfo{Number: 1, URL: "https://gitlab.com/group/subgroup/project/-/merge_requests/1", Existing: true}
+	if !reflect.DeepEqual(existing, want) {
+		t.Errorf("FindPullRequest = %+v, want %+v", existing, want)
+	}
+}
+
+func TestGitLabErrors(t *testing.T) {
+	_, g := newFakeGitLab(t)
+	ctx := context.Background()
+
+	// GitLab reports validation errors as a list of messages
+	_, err := g.CreatePullRequest(ctx, &PullRequest{Title: "feat: x", Head: "main", Base: "main"})
+	if err == nil || !strings.Contains(err.Error(), "source and target branch are the same") {
+		t.Errorf("CreatePullRequest into its own branch = %v, want the API message", err)
+	}
+
+	unauthorized := NewGitLab(g.client.baseURL, "group/subgroup/project", "wrong")
+	if _, err := unauthorized.FindPullRequest(ctx, "h", "main"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("FindPullRequest with a bad token = %v, want the API message", err)
+	}
+}
@@ -0,0 +1,127 @@
+package forge
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nohdol/claude-auto/internal/generators"
+	"github.com/nohdol/claude-auto/pkg/types"
+)
+
+// maxListedFiles limits each file list in a pull request body
+const maxListedFiles = 50
+
+// LabelClaudeAuto marks every pull request opened by claude-auto
+const LabelClaudeAuto = "claude-auto"
+
+// taskLabels maps task types to pull request labels
+var taskLabels = map[types.TaskType]string{
+	types.TaskTypeFrontend:      "frontend",
+	types.TaskTypeBackend:       "backend",
+	types.TaskTypeDatabase:      "database",
+	types.TaskTypeTesting:       "tests",
+	types.TaskTypeDocumentation: "documentation",
+	types.TaskTypeDevOps:        "devops",
+}
+
+// FeaturePullRequest describes the pull request for a feature added with "add"
+func FeaturePullRequest(name, description string, result *generators.FeatureResult) *PullRequest {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Summary\n\n%s\n", description)
+
+	writeTasks(&b, result.Tasks)
+	writeFiles(&b, "Files Created", result.FilesCreated)
+	writeFiles(&b, "Files Modified", result.FilesModified)
+	writeFiles(&b, "Files Deleted", result.FilesDeleted)
+	writeFiles(&b, "Tests Created", result.TestsCreated)
+
+	return &PullRequest{
+		Title:  fmt.Sprintf("feat: %s", name),
+		Body:   b.String(),
+		Labels: Labels(result.Tasks),
+	}
+}
+
+// RunPullRequest describes the pull request for a project generated with "idea"
+func RunPullRequest(idea *types.ProcessedIdea, report *types.ExecutionReport) *PullRequest {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Summary\n\n%s\n", idea.Description)
+
+	if len(idea.Features) > 0 {
+		b.WriteString("\n## Features\n\n")
+		for _, feature := range idea.Features {
+			fmt.Fprintf(&b, "- %s\n", feature)
+		}
+	}
+
+	var tasks []*types.Task
+	if report != nil {
+		tasks = report.Tasks
+		fmt.Fprintf(&b, "\n## Execution\n\n- Completed: %d/%d\n- Failed: %d\n- Duration: %s\n",
+			report.CompletedTasks, report.TotalTasks, report.FailedTasks, report.Duration)
+	}
+	writeTasks(&b, tasks)
+
+	if report != nil && report.Validation != nil {
+		b.WriteString("\n## Validation\n\n")
+		for _, check := range report.Validation.Checks {
+			mark := "x"
+			if !check.Passed {
+				mark = " "
+			}
+			fmt.Fprintf(&b, "- [%s] %s\n", mark, check.Name)
+		}
+	}
+
+	return &PullRequest{
+		Title:  fmt.Sprintf("feat: %s", idea.Name),
+		Body:   b.String(),
+		Labels: Labels(tasks),
+	}
+}
+
+// Labels returns the claude-auto label and one label per task type, sorted
+func Labels(tasks []*types.Task) []string {
+	seen := map[string]bool{LabelClaudeAuto: true}
+	for _, task := range tasks {
+		if label, ok := taskLabels[task.Type]; ok {
+			seen[label] = true
+		}
+	}
+
+	labels := make([]string, 0, len(seen))
+	for label := range seen {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// writeTasks writes a table of tasks with their status and changed files
+func writeTasks(b *strings.Builder, tasks []*types.Task) {
+	if len(tasks) == 0 {
+		return
+	}
+
+	b.WriteString("\n## Tasks\n\n| Task | Type | Status | Files |\n|------|------|--------|-------|\n")
+	for _, task := range tasks {
+		fmt.Fprintf(b, "| `%s` | %s | %s | %d |\n", task.ID, task.Type, task.Status, len(task.Changes.Files()))
+	}
+}
+
+// writeFiles writes a file list section, truncated to maxListedFiles
+func writeFiles(b *strings.Builder, title string, files []string) {
+	if len(files) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "\n## %s\n\n", title)
+	for i, file := range files {
+		if i == maxListedFiles {
+			fmt.Fprintf(b, "- ... and %d more\n", len(files)-maxListedFiles)
+			break
+		}
+		fmt.Fprintf(b, "- `%s`\n", file)
+	}
+}
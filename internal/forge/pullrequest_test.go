@@ -0,0 +1,66 @@
+package forge
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nohdol/claude-auto/internal/generators"
+	"github.com/nohdol/claude-auto/pkg/types"
+)
+
+func TestLabels(t *testing.T) {
+	tasks := []*types.Task{
+		{Type: types.TaskTypeBackend},
+		{Type: types.TaskTypeTesting},
+		{Type: types.TaskTypeBackend},
+		{Type: types.TaskTypeFix}, // no label of its own
+	}
+
+	want := []string{"backend", LabelClaudeAuto, "tests"}
+	if got := Labels(tasks); !reflect.DeepEqual(got, want) {
+		t.Errorf("Labels = %q, want %q", got, want)
+	}
+	if got := Labels(nil); !reflect.DeepEqual(got, []string{LabelClaudeAuto}) {
+		t.Errorf("Labels(nil) = %q", got)
+	}
+}
+
+func TestFeaturePullRequest(t *testing.T) {
+	var created []string
+	for i := 0; i < maxListedFiles+5; i++ {
+		created = append(created, fmt.Sprintf("src/file%d.go", i))
+	}
+	result := &generators.FeatureResult{
+		FilesCreated:  created,
+		FilesModified: []string{"go.mod"},
+		Tasks: []*types.Task{{
+			ID:      "task-1",
+			Type:    types.TaskTypeBackend,
+			Status:  types.TaskStatusCompleted,
+			Changes: &types.ChangeSet{Added: []string{"a.go", "b.go"}},
+		}},
+	}
+
+	pr := FeaturePullRequest("login", "Adds login", result)
+	if pr.Title != "feat: login" {
+		t.Errorf("Title = %q", pr.Title)
+	}
+	for _, want := range []string{
+		"## Summary\n\nAdds login",
+		"| `task-1` | backend | completed | 2 |",
+		"## Files Modified\n\n- `go.mod`",
+		"- ... and 5 more",
+	} {
+		if !strings.Contains(pr.Body, want) {
+			t.Errorf("Body is missing %q:\n%s", want, pr.Body)
+		}
+	}
+	if strings.Contains(pr.Body, "## Files Deleted") {
+		t.Error("Body lists an empty section")
+	}
+	if !reflect.DeepEqual(pr.Labels, []string{"backend", LabelClaudeAuto}) {
+		t.Errorf("Labels = %q", pr.Labels)
+	}
+}
@@ -0,0 +1,119 @@
+package generators
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AnalysisCacheDir holds the cached issues of each analyzed file, relative to
+// the workspace root
+const AnalysisCacheDir = ".claude-auto/cache/analysis"
+
+// analysisCacheVersion is part of every key; bump it when the prompt or the
+// issue format changes so old entries are not reused
+const analysisCacheVersion = "1"
+
+// analysisCache stores the issues found in a file under the hash of its content
+type analysisCache struct {
+	root   string
+	prefix string // path of the analyzed project below the workspace root
+	dir    string
+	mu     sync.Mutex
+	keys   map[string]string // Keys computed by Load, reused by Store
+}
+
+// cacheEntry is the cached analysis of one file
+type cacheEntry struct {
+	Path       string    `json:"path"`
+	Issues     []Issue   `json:"issues"`
+	AnalyzedAt time.Time `json:"analyzed_at"`
+}
+
+// newAnalysisCache opens the analysis cache of a project kept at the workspace
+// root, creating it when needed. Packages of a workspace share one cache.
+func newAnalysisCache(root, workspaceRoot string) (*analysisCache, error) {
+	prefix, err := filepath.Rel(workspaceRoot, root)
+	if err != nil || !filepath.IsLocal(prefix) {
+		prefix, workspaceRoot = ".", root
+	}
+
+	dir := filepath.Join(workspaceRoot, AnalysisCacheDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create analysis cache: %w", err)
+	}
+
+	// The cache ignores itself, so it never shows up as untracked files
+	ignore := filepath.Join(workspaceRoot, filepath.Dir(AnalysisCacheDir), ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		if err := os.WriteFile(ignore, []byte("*\n"), 0644); err != nil {
+			return nil, fmt.Errorf("failed to create analysis cache: %w", err)
+		}
+	}
+
+	return &analysisCache{root: root, prefix: filepath.ToSlash(prefix), dir: dir, keys: make(map[string]string)}, nil
+}
+
+// key hashes the path and content of a file and of the files it was analyzed with
+func (c *analysisCache) key(file string, related []string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "v%s\x00", analysisCacheVersion)
+
+	related = append([]string(nil), related...)
+	sort.Strings(related)
+	for _, name := range append([]string{file}, related...) {
+		content, err := os.ReadFile(filepath.Join(c.root, name))
+		if err != nil {
+			return "", err
+		}
+		// Paths are hashed from the workspace root, so packages do not share entries
+		fmt.Fprintf(h, "%s\x00%d\x00", path.Join(c.prefix, name), len(content))
+		h.Write(content)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Load returns the cached issues of a file analyzed together with related files
+func (c *analysisCache) Load(file string, related []string) ([]Issue, bool) {
+	key, err := c.key(file, related)
+	if err != nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	c.keys[file] = key
+	c.mu.Unlock()
+
+	data, err := os.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Path != file {
+		return nil, false
+	}
+	return entry.Issues, true
+}
+
+// Store caches the issues of a file under the key computed by Load
+func (c *analysisCache) Store(file string, issues []Issue) error {
+	c.mu.Lock()
+	key, ok := c.keys[file]
+	c.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no cache key for %s", file)
+	}
+
+	data, err := json.MarshalIndent(&cacheEntry{Path: file, Issues: issues, AnalyzedAt: time.Now()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(c.dir, key+".json"), data, 0644)
+}
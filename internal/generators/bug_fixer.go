@@ -0,0 +1,225 @@
+package generators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nohdol/claude-auto/internal/core"
+	"github.com/nohdol/claude-auto/internal/git"
+	"github.com/nohdol/claude-auto/internal/validation"
+	"github.com/nohdol/claude-auto/pkg/types"
+	"github.com/rs/zerolog"
+)
+
+// ErrNotReproduced is returned when the reproduction command passes before any fix
+var ErrNotReproduced = errors.New("the reproduction command passes, there is no failure to fix")
+
+// reproCheckName names the reproduction command among the checks
+const reproCheckName = "repro"
+
+// defaultBugFixAttempts is how often Claude may try to fix a bug
+const defaultBugFixAttempts = 3
+
+// BugReport describes a failure to fix
+type BugReport struct {
+	Description string // the bug in words
+	Repro       string // shell command failing while the bug is present
+	StackTrace  string
+}
+
+// FixAttempt is one round of fixing and verifying
+type FixAttempt struct {
+	Number      int
+	ReproPassed bool     // true when there is no reproduction command
+	Regressions []string // checks, or tests of a check, failing after the fix but not before
+}
+
+// BugFixResult is the outcome of fixing a bug
+type BugFixResult struct {
+	Fixed    bool
+	Attempts []FixAttempt
+	Checks   []string         // checks run before and after each attempt
+	Changes  *types.ChangeSet // the changes of the fix, nil when it was discarded
+	Summary  string           // Claude's account of the last attempt
+}
+
+// BugFixer fixes a bug with Claude and verifies the fix: the reproduction
+// command must pass afterwards and no check of the project may regress.
+// Failed fixes are discarded.
+type BugFixer struct {
+	claudeExecutor *core.ClaudeExecutor
+	validator      *validation.Validator
+	gitManager     *git.GitManager
+	logger         zerolog.Logger
+	maxAttempts    int
+}
+
+// NewBugFixer creates a bug fixer for the project of the validator and git manager
+func NewBugFixer(ce *core.ClaudeExecutor, v *validation.Validator, gm *git.GitManager, logger zerolog.Logger) *BugFixer {
+	return &BugFixer{
+		claudeExecutor: ce,
+		validator:      v,
+		gitManager:     gm,
+		logger:         logger,
+		maxAttempts:    defaultBugFixAttempts,
+	}
+}
+
+// SetMaxAttempts sets how often Claude may try to fix the bug
+func (bf *BugFixer) SetMaxAttempts(n int) {
+	if n > 0 {
+		bf.maxAttempts = n
+	}
+}
+
+// Fix confirms the failure, then lets Claude fix it until the reproduction
+// command passes without regressions or the attempts run out
+func (bf *BugFixer) Fix(ctx context.Context, report *BugReport) (*BugFixResult, error) {
+	checks := bf.validator.DetectChecks()
+	result := &BugFixResult{}
+	for _, check := range checks {
+		result.Checks = append(result.Checks, check.Name)
+	}
+
+	// Confirm the failure before changing anything
+	var repro []validation.CheckCommand
+	var reproOutput string
+	if report.Repro != "" {
+		repro = []validation.CheckCommand{validation.ShellCheck(validation.CheckKindTest, reproCheckName, report.Repro)}
+		confirmed := bf.validator.Run(ctx, repro)
+		if confirmed.Passed {
+			return nil, ErrNotReproduced
+		}
+		reproOutput = confirmed.Checks[0].Message
+	}
+
+	baseline := bf.validator.Run(ctx, checks)
+	bf.logger.Info().
+		Bool("repro", report.Repro != "").
+		Int("checks", len(checks)).
+		Int("failing", len(validation.FailedChecks(baseline))).
+		Msg("Recorded checks before fixing")
+
+	before, err := bf.gitManager.TakeSnapshot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot project: %w", err)
+	}
+
+	prompt := buildBugFixPrompt(report, reproOutput)
+	options := &core.ClaudeOptions{
+		Role:         "bug-fixer",
+		SystemPrompt: "You are an expert software engineer who finds the root cause of bugs and fixes it with minimal, focused changes.",
+		WorkDir:      bf.gitManager.GetProjectDir(),
+	}
+
+	next := prompt
+	for attempt := 1; attempt <= bf.maxAttempts; attempt++ {
+		bf.logger.Info().Int("attempt", attempt).Msg("Fixing bug")
+
+		response, err := bf.claudeExecutor.Execute(ctx, next, options)
+		if err != nil {
+			bf.gitManager.DiscardSince(before, "the failed fix")
+			return nil, fmt.Errorf("fix attempt %d failed: %w", attempt, err)
+		}
+		result.Summary = response.Output
+
+		// The bug is fixed when the repro passes and nothing else broke
+		var failed []types.Check
+		current := FixAttempt{Number: attempt, ReproPassed: true}
+		if repro != nil {
+			if rerun := bf.validator.Run(ctx, repro); !rerun.Passed {
+				current.ReproPassed = false
+				failed = append(failed, rerun.Checks[0])
+			}
+		}
+		after := bf.validator.Run(ctx, checks)
+		for _, check := range validation.FailedChecks(after) {
+			if regression := regressionOf(baseline, check); regression != "" {
+				current.Regressions = append(current.Regressions, regression)
+				failed = append(failed, check)
+			}
+		}
+		result.Attempts = append(result.Attempts, current)
+
+		if len(failed) == 0 {
+			result.Fixed = true
+			break
+		}
+
+		bf.logger.Warn().
+			Int("attempt", attempt).
+			Bool("repro_passed", current.ReproPassed).
+			Strs("regressions", current.Regressions).
+			Msg("Fix did not pass verification")
+		next = validation.BuildFixPrompt(prompt, failed)
+	}
+
+	if !result.Fixed {
+		bf.gitManager.DiscardSince(before, "the failed fix")
+		return result, nil
+	}
+
+	snapshot, err := bf.gitManager.TakeSnapshot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot project: %w", err)
+	}
+	result.Changes = git.DiffSnapshots(before, snapshot)
+	return result, nil
+}
+
+// regressionOf describes how a failed check regressed from the baseline: the
+// check when it passed before, or the tests failing now that did not before.
+// It returns "" when the check failed the same way before.
+func regressionOf(baseline *types.ValidationResult, check types.Check) string {
+	var before *types.Check
+	for i := range baseline.Checks {
+		if baseline.Checks[i].Name == check.Name {
+			before = &baseline.Checks[i]
+		}
+	}
+	if before == nil || before.Passed {
+		return check.Name
+	}
+
+	known := make(map[string]bool)
+	for _, failure := range before.FailingTests {
+		known[failure] = true
+	}
+	var fresh []string
+	for _, failure := range check.FailingTests {
+		if !known[failure] {
+			fresh = append(fresh, failure)
+		}
+	}
+	if len(fresh) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", check.Name, strings.Join(fresh, ", "))
+}
+
+// buildBugFixPrompt builds the prompt of the first fix attempt
+func buildBugFixPrompt(report *BugReport, reproOutput string) string {
+	var b strings.Builder
+	b.WriteString("프로젝트의 버그를 수정해주세요.\n")
+
+	if report.Description != "" {
+		fmt.Fprintf(&b, "\n문제:\n%s\n", report.Description)
+	}
+	if report.Repro != "" {
+		fmt.Fprintf(&b, "\n재현 명령 (현재 실패하며, 수정 후 통과해야 함):\n%s\n", report.Repro)
+		fmt.Fprintf(&b, "\n재현 결과:\n```\n%s\n```\n", strings.TrimSpace(reproOutput))
+	}
+	if report.StackTrace != "" {
+		fmt.Fprintf(&b, "\n스택 트레이스:\n```\n%s\n```\n", truncate(report.StackTrace, 8000))
+	}
+
+	b.WriteString("\n규칙:\n")
+	b.WriteString("- 근본 원인을 찾아 수정하세요. 증상만 가리는 수정은 하지 마세요.\n")
+	b.WriteString("- 테스트를 삭제하거나 약하게 만들거나 재현 명령을 우회하지 마세요.\n")
+	b.WriteString("- 버그와 관련 없는 코드는 바꾸지 마세요. 기존 테스트는 계속 통과해야 합니다.\n")
+	b.WriteString("- 가능하면 버그를 재현하는 테스트를 추가하세요.\n")
+	b.WriteString("- git 명령은 실행하지 마세요.\n")
+	return b.String()
+}
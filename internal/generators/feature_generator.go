@@ -3,6 +3,7 @@ package generators
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/nohdol/claude-auto/internal/core"
@@ -16,9 +17,9 @@ import (
 type FeatureGenerator struct {
 	claudeExecutor *core.ClaudeExecutor
 	taskManager    *tasks.TaskManager
-	analyzer       *ProjectAnalyzer
 	gitManager     *git.GitManager
 	logger         zerolog.Logger
+	pkg            string // workspace package the feature is limited to
 }
 
 // NewFeatureGenerator creates a new feature generator
@@ -26,7 +27,6 @@ func NewFeatureGenerator(ce *core.ClaudeExecutor, tm *tasks.TaskManager, logger
 	return &FeatureGenerator{
 		claudeExecutor: ce,
 		taskManager:    tm,
-		analyzer:       NewProjectAnalyzer(ce, logger),
 		logger:         logger,
 	}
 }
@@ -38,14 +38,18 @@ type FeatureRequest struct {
 	Type        string // api, ui, service, database, etc.
 	Components  []string
 	ProjectPath string
+	Package     string // package directory relative to the project, empty for the whole project
 }
 
 // FeatureResult represents the result of feature generation
 type FeatureResult struct {
 	FilesCreated  []string
 	FilesModified []string
+	FilesDeleted  []string
 	TestsCreated  []string
 	Documentation string
+	// Tasks are the executed tasks with the files each one changed
+	Tasks []*types.Task
 }
 
 // AddFeature adds a new feature to an existing project
@@ -55,12 +59,6 @@ func (fg *FeatureGenerator) AddFeature(ctx context.Context, projectPath string,
 		Str("feature", featureName).
 		Msg("Adding new feature to project")
 
-	// First, analyze the existing project
-	projectInfo, err := fg.analyzer.AnalyzeProject(ctx, projectPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to analyze project: %w", err)
-	}
-
 	// Create feature request
 	request := &FeatureRequest{
 		Name:        featureName,
@@ -68,42 +66,83 @@ func (fg *FeatureGenerator) AddFeature(ctx context.Context, projectPath string,
 		ProjectPath: projectPath,
 	}
 
-	// Determine feature type based on project and description
-	request.Type = fg.determineFeatureType(featureDescription, projectInfo)
+	// Limit the feature to one package of a monorepo when one is set
+	analyzePath := projectPath
+	if fg.pkg != "" {
+		pkg, err := findPackage(projectPath, fg.pkg)
+		if err != nil {
+			return nil, err
+		}
+		analyzePath, request.Package = pkg.Path, pkg.Dir
+	}
 
-	// Generate feature plan
-	plan, err := fg.generateFeaturePlan(ctx, request, projectInfo)
+	// Planning only needs the stack of the project, not an analysis of its code
+	projectInfo, err := DetectProject(analyzePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate feature plan: %w", err)
+		return nil, fmt.Errorf("failed to detect project: %w", err)
 	}
 
-	// Create tasks for feature implementation
-	tasks := fg.createFeatureTasks(request, projectInfo, plan)
+	// Create tasks for feature implementation; in a monorepo the plan spans
+	// packages and each task runs in the package it changes
+	var tasks []*types.Task
+	if request.Package == "" && len(projectInfo.Packages) > 0 {
+		tasks, err = fg.createWorkspaceTasks(ctx, request, projectInfo)
+	} else {
+		tasks, err = fg.createProjectTasks(ctx, request, projectInfo)
+	}
+	if err != nil {
+		return nil, err
+	}
 
 	// Execute tasks
 	result := &FeatureResult{
 		FilesCreated:  []string{},
 		FilesModified: []string{},
+		FilesDeleted:  []string{},
 		TestsCreated:  []string{},
 	}
 
+	// Snapshot the worktree around each task to track exactly what it changed
+	initial := fg.takeSnapshot()
+	before := initial
+
 	for _, task := range tasks {
 		fg.logger.Info().
 			Str("task", task.ID).
 			Str("type", string(task.Type)).
 			Msg("Executing feature task")
 
-		// Execute task with Claude
-		response, err := fg.claudeExecutor.Execute(ctx, task.Prompt, &core.ClaudeOptions{
-			Role: fg.getRoleForTaskType(task.Type),
+		// Execute task with Claude in the package it belongs to
+		_, err := fg.claudeExecutor.Execute(ctx, task.Prompt, &core.ClaudeOptions{
+			Role:    fg.getRoleForTaskType(task.Type),
+			WorkDir: filepath.Join(projectPath, filepath.FromSlash(task.WorkDir)),
 		})
+
+		// A failed task may still have written files
+		after := fg.takeSnapshot()
+		if before != nil && after != nil {
+			changes := git.DiffSnapshots(before, after)
+			fg.taskManager.SetTaskChanges(task.ID, changes)
+			fg.logger.Debug().
+				Str("task", task.ID).
+				Int("added", len(changes.Added)).
+				Int("modified", len(changes.Modified)).
+				Int("deleted", len(changes.Deleted)).
+				Msg("Task changes recorded")
+		}
+		before = after
+
 		if err != nil {
 			fg.logger.Error().Err(err).Str("task", task.ID).Msg("Task execution failed")
 			continue
 		}
+	}
 
-		// Process response and update result
-		fg.processTaskResponse(response.Output, task.Type, result)
+	result.Tasks = tasks
+
+	// Files created and later changed by another task count as created only
+	if initial != nil && before != nil {
+		fg.recordChanges(git.DiffSnapshots(initial, before), result)
 	}
 
 	// Generate documentation
@@ -112,6 +151,25 @@ func (fg *FeatureGenerator) AddFeature(ctx context.Context, projectPath string,
 	return result, nil
 }
 
+// createProjectTasks plans the feature for a single project, or the package
+// of the request, and creates its tasks
+func (fg *FeatureGenerator) createProjectTasks(ctx context.Context, request *FeatureRequest, projectInfo *ProjectInfo) ([]*types.Task, error) {
+	// Determine feature type based on project and description
+	request.Type = fg.determineFeatureType(request.Description, projectInfo)
+
+	// Generate feature plan
+	plan, err := fg.generateFeaturePlan(ctx, request, projectInfo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate feature plan: %w", err)
+	}
+
+	tasks := fg.createFeatureTasks(request, projectInfo, plan)
+	for _, task := range tasks {
+		task.WorkDir = request.Package
+	}
+	return tasks, nil
+}
+
 // generateFeaturePlan generates a detailed plan for the feature
 func (fg *FeatureGenerator) generateFeaturePlan(ctx context.Context, request *FeatureRequest, projectInfo *ProjectInfo) (string, error) {
 	prompt := fmt.Sprintf(`프로젝트에 새로운 기능을 추가하려고 합니다.
@@ -433,30 +491,47 @@ func (fg *FeatureGenerator) getRoleForTaskType(taskType types.TaskType) string {
 	return "software-developer"
 }
 
-// processTaskResponse processes the response from a task execution
-func (fg *FeatureGenerator) processTaskResponse(output string, taskType types.TaskType, result *FeatureResult) {
-	// Parse the output to identify created/modified files
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// Look for file paths in the output
-		if strings.HasPrefix(line, "Created:") || strings.Contains(line, "created") {
-			file := strings.TrimSpace(strings.TrimPrefix(line, "Created:"))
-			result.FilesCreated = append(result.FilesCreated, file)
-		} else if strings.HasPrefix(line, "Modified:") || strings.Contains(line, "modified") {
-			file := strings.TrimSpace(strings.TrimPrefix(line, "Modified:"))
-			result.FilesModified = append(result.FilesModified, file)
+// takeSnapshot snapshots the project worktree, returning nil when changes cannot be tracked
+func (fg *FeatureGenerator) takeSnapshot() git.Snapshot {
+	if fg.gitManager == nil {
+		return nil
+	}
+
+	snapshot, err := fg.gitManager.TakeSnapshot()
+	if err != nil {
+		fg.logger.Warn().Err(err).Msg("Failed to snapshot worktree, changes will not be tracked")
+		return nil
+	}
+	return snapshot
+}
+
+// recordChanges fills the result with the files changed by the feature
+func (fg *FeatureGenerator) recordChanges(changes *types.ChangeSet, result *FeatureResult) {
+	result.FilesCreated = append(result.FilesCreated, changes.Added...)
+	result.FilesModified = append(result.FilesModified, changes.Modified...)
+	result.FilesDeleted = append(result.FilesDeleted, changes.Deleted...)
+
+	for _, file := range changes.Added {
+		if isTestFile(file) {
+			result.TestsCreated = append(result.TestsCreated, file)
 		}
+	}
+}
 
-		// Track test files
-		if taskType == types.TaskTypeTesting {
-			if strings.Contains(line, ".test.") || strings.Contains(line, ".spec.") ||
-				strings.Contains(line, "_test.") {
-				result.TestsCreated = append(result.TestsCreated, line)
-			}
+// isTestFile checks if a path follows a common test file naming convention
+func isTestFile(path string) bool {
+	base := filepath.Base(path)
+	if strings.Contains(base, "_test.") || strings.Contains(base, ".test.") ||
+		strings.Contains(base, ".spec.") || strings.HasPrefix(base, "test_") {
+		return true
+	}
+
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		if dir == "__tests__" || dir == "tests" {
+			return true
 		}
 	}
+	return false
 }
 
 // generateDocumentation generates documentation for the new feature
@@ -480,6 +555,13 @@ func (fg *FeatureGenerator) generateDocumentation(request *FeatureRequest, resul
 		doc += fmt.Sprintf("- %s\n", file)
 	}
 
+	if len(result.FilesDeleted) > 0 {
+		doc += "\n### Files Deleted\n"
+		for _, file := range result.FilesDeleted {
+			doc += fmt.Sprintf("- %s\n", file)
+		}
+	}
+
 	if len(result.TestsCreated) > 0 {
 		doc += "\n### Tests\n"
 		for _, test := range result.TestsCreated {
@@ -504,6 +586,11 @@ Run tests with the project's test command.
 	return doc
 }
 
+// SetPackage limits features to one workspace package, given by name or path
+func (fg *FeatureGenerator) SetPackage(name string) {
+	fg.pkg = name
+}
+
 // SetGitManager sets the git manager for the feature generator
 func (fg *FeatureGenerator) SetGitManager(gm *git.GitManager) {
 	fg.gitManager = gm
@@ -3,19 +3,38 @@ package generators
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/nohdol/claude-auto/internal/core"
 	"github.com/nohdol/claude-auto/internal/tasks"
+	"github.com/nohdol/claude-auto/internal/templates"
 	"github.com/nohdol/claude-auto/pkg/types"
 	"github.com/rs/zerolog"
 )
 
+// Artifacts exchanged between idea tasks. Dependencies are derived from
+// which task produces and which task consumes each artifact.
+const (
+	artifactSkeleton   = "project-skeleton"
+	artifactSchema     = "db/schema.sql"
+	artifactAPISpec    = "openapi.yaml"
+	artifactAPIRoutes  = "api/routes"
+	artifactServices   = "api/services"
+	artifactAuth       = "api/auth"
+	artifactComponents = "src/components"
+	artifactPages      = "src/pages"
+	artifactAPIClient  = "src/api/client.ts"
+	artifactUnitTests  = "tests/unit"
+	artifactIntegTests = "tests/integration"
+)
+
 // IdeaProcessor processes user ideas into concrete project plans
 type IdeaProcessor struct {
 	claudeExecutor *core.ClaudeExecutor
 	taskManager    *tasks.TaskManager
+	preset         *templates.Preset
 	logger         zerolog.Logger
 }
 
@@ -51,11 +70,21 @@ func (ip *IdeaProcessor) ProcessIdea(ctx context.Context, idea string) (*types.P
 		return nil, fmt.Errorf("failed to parse processed idea: %w", err)
 	}
 
+	// Pin the architecture to the vetted preset stack
+	if ip.preset != nil {
+		ip.preset.ApplyTo(processedIdea)
+	}
+
 	// Step 3: Create tasks from the processed idea
-	tasks := ip.decomposeTasks(processedIdea)
+	tasks, err := ip.decomposeTasks(processedIdea)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tasks: %w", err)
+	}
 
 	// Step 4: Set up task dependencies
-	ip.setupDependencies(tasks)
+	if err := ip.setupDependencies(tasks); err != nil {
+		return nil, fmt.Errorf("failed to set up task dependencies: %w", err)
+	}
 
 	ip.logger.Info().
 		Str("project_name", processedIdea.Name).
@@ -65,9 +94,14 @@ func (ip *IdeaProcessor) ProcessIdea(ctx context.Context, idea string) (*types.P
 	return processedIdea, nil
 }
 
+// SetPreset sets the stack preset the generated project is pinned to
+func (ip *IdeaProcessor) SetPreset(preset *templates.Preset) {
+	ip.preset = preset
+}
+
 // buildRefinementPrompt builds the prompt for idea refinement
 func (ip *IdeaProcessor) buildRefinementPrompt(idea string) string {
-	return fmt.Sprintf(`당신은 소프트웨어 아키텍트입니다. 다음 아이디어를 구체적인 프로젝트로 변환해주세요:
+	prompt := fmt.Sprintf(`당신은 소프트웨어 아키텍트입니다. 다음 아이디어를 구체적인 프로젝트로 변환해주세요:
 "%s"
 
 반드시 다음 JSON 형식으로만 응답해주세요. 다른 설명 없이 JSON만 출력하세요:
@@ -102,6 +136,15 @@ func (ip *IdeaProcessor) buildRefinementPrompt(idea string) string {
 }
 
 프로젝트의 규모와 복잡도를 고려하여 실현 가능한 계획을 수립해주세요.`, idea)
+
+	if ip.preset != nil {
+		prompt += fmt.Sprintf(`
+
+기술 스택은 "%s" 프리셋으로 고정되어 있습니다 (%s). architecture 항목은 이 스택에 맞춰 작성해주세요.`,
+			ip.preset.Name, ip.preset.Description)
+	}
+
+	return prompt
 }
 
 // parseProcessedIdea parses the JSON response into ProcessedIdea
@@ -221,16 +264,21 @@ func (ip *IdeaProcessor) createDefaultProcessedIdea(output string) *types.Proces
 }
 
 // decomposeTasks creates tasks from the processed idea
-func (ip *IdeaProcessor) decomposeTasks(idea *types.ProcessedIdea) []*types.Task {
+func (ip *IdeaProcessor) decomposeTasks(idea *types.ProcessedIdea) ([]*types.Task, error) {
 	var tasks []*types.Task
+	var errs []error
 
 	// 1. Project initialization (priority 0)
-	initTask := ip.taskManager.CreateTask(
-		types.TaskTypeDevOps,
-		0,
-		ip.buildInitPrompt(idea),
-	)
-	tasks = append(tasks, initTask)
+	// Presets lay down the skeleton locally, so no init task is needed
+	if ip.preset == nil {
+		initTask := ip.taskManager.CreateTask(
+			types.TaskTypeDevOps,
+			0,
+			ip.buildInitPrompt(idea),
+		)
+		errs = append(errs, ip.declareArtifacts(initTask, []string{artifactSkeleton}, nil))
+		tasks = append(tasks, initTask)
+	}
 
 	// 2. Database setup (priority 1)
 	if idea.HasDatabase {
@@ -239,24 +287,28 @@ func (ip *IdeaProcessor) decomposeTasks(idea *types.ProcessedIdea) []*types.Task
 			1,
 			ip.buildDatabasePrompt(idea),
 		)
+		errs = append(errs, ip.declareArtifacts(dbTask, []string{artifactSchema}, []string{artifactSkeleton}))
 		tasks = append(tasks, dbTask)
 	}
 
 	// 3. Backend API (priority 2)
 	if idea.HasBackend {
-		backendTasks := ip.createBackendTasks(idea)
+		backendTasks, err := ip.createBackendTasks(idea)
 		tasks = append(tasks, backendTasks...)
+		errs = append(errs, err)
 	}
 
 	// 4. Frontend (priority 3)
 	if idea.HasFrontend {
-		frontendTasks := ip.createFrontendTasks(idea)
+		frontendTasks, err := ip.createFrontendTasks(idea)
 		tasks = append(tasks, frontendTasks...)
+		errs = append(errs, err)
 	}
 
 	// 5. Testing (priority 4)
-	testingTasks := ip.createTestingTasks(idea)
+	testingTasks, err := ip.createTestingTasks(idea)
 	tasks = append(tasks, testingTasks...)
+	errs = append(errs, err)
 
 	// 6. Documentation (priority 5)
 	docTask := ip.taskManager.CreateTask(
@@ -264,9 +316,47 @@ func (ip *IdeaProcessor) decomposeTasks(idea *types.ProcessedIdea) []*types.Task
 		5,
 		ip.buildDocumentationPrompt(idea),
 	)
+	// Documentation describes everything the other tasks produce
+	var documented []string
+	seen := make(map[string]bool)
+	for _, task := range tasks {
+		for _, artifact := range task.Produces {
+			if !seen[artifact] {
+				seen[artifact] = true
+				documented = append(documented, artifact)
+			}
+		}
+	}
+	errs = append(errs, ip.declareArtifacts(docTask, nil, documented))
 	tasks = append(tasks, docTask)
 
-	return tasks
+	// Append preset-specific conventions to each prompt
+	if ip.preset != nil {
+		for _, task := range tasks {
+			if fragment := ip.preset.PromptFragment(task.Type); fragment != "" {
+				task.Prompt += fmt.Sprintf("\n\n프로젝트 스켈레톤(%s 프리셋)이 이미 생성되어 있습니다. 다음 규칙을 따라주세요:\n%s",
+					ip.preset.Name, fragment)
+			}
+		}
+	}
+
+	return tasks, errors.Join(errs...)
+}
+
+// declareArtifacts records the artifacts a task produces and consumes
+func (ip *IdeaProcessor) declareArtifacts(task *types.Task, produces, consumes []string) error {
+	if err := ip.taskManager.DeclareArtifacts(task.ID, produces, consumes); err != nil {
+		return fmt.Errorf("failed to declare artifacts of task %s: %w", task.ID, err)
+	}
+	return nil
+}
+
+// backendBase returns the artifact backend tasks build on
+func (ip *IdeaProcessor) backendBase(idea *types.ProcessedIdea) string {
+	if idea.HasDatabase {
+		return artifactSchema
+	}
+	return artifactSkeleton
 }
 
 // buildInitPrompt builds the project initialization prompt
@@ -304,8 +394,9 @@ func (ip *IdeaProcessor) buildDatabasePrompt(idea *types.ProcessedIdea) string {
 }
 
 // createBackendTasks creates backend-related tasks
-func (ip *IdeaProcessor) createBackendTasks(idea *types.ProcessedIdea) []*types.Task {
+func (ip *IdeaProcessor) createBackendTasks(idea *types.ProcessedIdea) ([]*types.Task, error) {
 	var tasks []*types.Task
+	var errs []error
 
 	// API structure
 	apiTask := ip.taskManager.CreateTask(
@@ -319,9 +410,11 @@ func (ip *IdeaProcessor) createBackendTasks(idea *types.ProcessedIdea) []*types.
 1. 라우터 설정
 2. 미들웨어 구성
 3. 에러 핸들링
-4. 환경 변수 관리`,
-			idea.Architecture.Backend.Framework, idea.Features),
+4. 환경 변수 관리
+5. 프로젝트 루트의 %s에 모든 엔드포인트의 OpenAPI 3 명세 작성`,
+			idea.Architecture.Backend.Framework, idea.Features, artifactAPISpec),
 	)
+	errs = append(errs, ip.declareArtifacts(apiTask, []string{artifactAPISpec, artifactAPIRoutes}, []string{ip.backendBase(idea)}))
 	tasks = append(tasks, apiTask)
 
 	// Business logic
@@ -334,6 +427,7 @@ func (ip *IdeaProcessor) createBackendTasks(idea *types.ProcessedIdea) []*types.
 3. 비즈니스 규칙
 4. 유틸리티 함수`,
 	)
+	errs = append(errs, ip.declareArtifacts(logicTask, []string{artifactServices}, []string{ip.backendBase(idea)}))
 	tasks = append(tasks, logicTask)
 
 	// Authentication if needed
@@ -347,15 +441,17 @@ func (ip *IdeaProcessor) createBackendTasks(idea *types.ProcessedIdea) []*types.
 3. 권한 관리 시스템
 4. 세션 관리`,
 		)
+		errs = append(errs, ip.declareArtifacts(authTask, []string{artifactAuth}, []string{ip.backendBase(idea)}))
 		tasks = append(tasks, authTask)
 	}
 
-	return tasks
+	return tasks, errors.Join(errs...)
 }
 
 // createFrontendTasks creates frontend-related tasks
-func (ip *IdeaProcessor) createFrontendTasks(idea *types.ProcessedIdea) []*types.Task {
+func (ip *IdeaProcessor) createFrontendTasks(idea *types.ProcessedIdea) ([]*types.Task, error) {
 	var tasks []*types.Task
+	var errs []error
 
 	// UI components
 	uiTask := ip.taskManager.CreateTask(
@@ -375,6 +471,7 @@ func (ip *IdeaProcessor) createFrontendTasks(idea *types.ProcessedIdea) []*types
 			idea.Architecture.Frontend.Styling,
 			idea.Architecture.Frontend.State),
 	)
+	errs = append(errs, ip.declareArtifacts(uiTask, []string{artifactComponents}, []string{artifactSkeleton}))
 	tasks = append(tasks, uiTask)
 
 	// Pages/Routes
@@ -387,26 +484,35 @@ func (ip *IdeaProcessor) createFrontendTasks(idea *types.ProcessedIdea) []*types
 3. 라우팅 설정
 4. 404 페이지`,
 	)
+	errs = append(errs, ip.declareArtifacts(pagesTask, []string{artifactPages}, []string{artifactSkeleton}))
 	tasks = append(tasks, pagesTask)
 
-	// API integration
+	// API integration waits for the API spec when there is a backend
+	integrationPrompt := fmt.Sprintf(`API 통합:
+1. API 클라이언트 설정 (%s)
+2. 데이터 페칭 로직
+3. 에러 처리
+4. 로딩 상태 관리`, artifactAPIClient)
+	integrationConsumes := []string{artifactSkeleton}
+	if idea.HasBackend {
+		integrationPrompt += fmt.Sprintf("\n\n백엔드 API 명세는 %s를 기준으로 사용하세요.", artifactAPISpec)
+		integrationConsumes = []string{artifactAPISpec}
+	}
 	integrationTask := ip.taskManager.CreateTask(
 		types.TaskTypeFrontend,
 		3,
-		`API 통합:
-1. API 클라이언트 설정
-2. 데이터 페칭 로직
-3. 에러 처리
-4. 로딩 상태 관리`,
+		integrationPrompt,
 	)
+	errs = append(errs, ip.declareArtifacts(integrationTask, []string{artifactAPIClient}, integrationConsumes))
 	tasks = append(tasks, integrationTask)
 
-	return tasks
+	return tasks, errors.Join(errs...)
 }
 
 // createTestingTasks creates testing-related tasks
-func (ip *IdeaProcessor) createTestingTasks(idea *types.ProcessedIdea) []*types.Task {
+func (ip *IdeaProcessor) createTestingTasks(idea *types.ProcessedIdea) ([]*types.Task, error) {
 	var tasks []*types.Task
+	var errs []error
 
 	// Unit tests
 	unitTestTask := ip.taskManager.CreateTask(
@@ -418,6 +524,17 @@ func (ip *IdeaProcessor) createTestingTasks(idea *types.ProcessedIdea) []*types.
 3. 컴포넌트 테스트
 커버리지 목표: 80% 이상`,
 	)
+	var unitConsumes []string
+	if idea.HasBackend {
+		unitConsumes = append(unitConsumes, artifactServices)
+	}
+	if idea.HasFrontend {
+		unitConsumes = append(unitConsumes, artifactComponents, artifactPages)
+	}
+	if len(unitConsumes) == 0 {
+		unitConsumes = []string{artifactSkeleton}
+	}
+	errs = append(errs, ip.declareArtifacts(unitTestTask, []string{artifactUnitTests}, unitConsumes))
 	tasks = append(tasks, unitTestTask)
 
 	// Integration tests
@@ -429,9 +546,26 @@ func (ip *IdeaProcessor) createTestingTasks(idea *types.ProcessedIdea) []*types.
 2. 데이터베이스 통합 테스트
 3. 인증 플로우 테스트`,
 	)
+	var integConsumes []string
+	if idea.HasBackend {
+		integConsumes = append(integConsumes, artifactAPISpec, artifactAPIRoutes)
+		if ip.requiresAuth(idea) {
+			integConsumes = append(integConsumes, artifactAuth)
+		}
+	}
+	if idea.HasDatabase {
+		integConsumes = append(integConsumes, artifactSchema)
+	}
+	if idea.HasFrontend {
+		integConsumes = append(integConsumes, artifactAPIClient)
+	}
+	if len(integConsumes) == 0 {
+		integConsumes = []string{artifactSkeleton}
+	}
+	errs = append(errs, ip.declareArtifacts(integrationTestTask, []string{artifactIntegTests}, integConsumes))
 	tasks = append(tasks, integrationTestTask)
 
-	return tasks
+	return tasks, errors.Join(errs...)
 }
 
 // buildDocumentationPrompt builds the documentation prompt
@@ -449,68 +583,19 @@ func (ip *IdeaProcessor) buildDocumentationPrompt(idea *types.ProcessedIdea) str
 기능 목록: %v`, idea.Name, idea.Description, idea.Features)
 }
 
-// setupDependencies sets up task dependencies
-func (ip *IdeaProcessor) setupDependencies(tasks []*types.Task) {
+// setupDependencies wires task dependencies from the declared artifacts
+func (ip *IdeaProcessor) setupDependencies(tasks []*types.Task) error {
 	if len(tasks) < 2 {
-		return
+		return nil
 	}
 
-	// Database tasks depend on initialization
-	initTaskID := tasks[0].ID
-	for i := 1; i < len(tasks); i++ {
-		task := tasks[i]
-		if task.Type == types.TaskTypeDatabase {
-			ip.taskManager.AddDependency(task.ID, initTaskID)
-		}
-	}
-
-	// Backend tasks depend on database (if exists)
-	var dbTaskID string
-	for _, task := range tasks {
-		if task.Type == types.TaskTypeDatabase {
-			dbTaskID = task.ID
-			break
-		}
+	// A preset skeleton is laid down before any task runs
+	var available []string
+	if ip.preset != nil {
+		available = append(available, artifactSkeleton)
 	}
 
-	if dbTaskID != "" {
-		for _, task := range tasks {
-			if task.Type == types.TaskTypeBackend {
-				ip.taskManager.AddDependency(task.ID, dbTaskID)
-			}
-		}
-	}
-
-	// Frontend tasks can run in parallel with backend
-	// but depend on initialization
-	for _, task := range tasks {
-		if task.Type == types.TaskTypeFrontend {
-			ip.taskManager.AddDependency(task.ID, initTaskID)
-		}
-	}
-
-	// Testing depends on backend and frontend
-	for _, task := range tasks {
-		if task.Type == types.TaskTypeTesting {
-			// Add dependencies to all backend and frontend tasks
-			for _, depTask := range tasks {
-				if depTask.Type == types.TaskTypeBackend || depTask.Type == types.TaskTypeFrontend {
-					ip.taskManager.AddDependency(task.ID, depTask.ID)
-				}
-			}
-		}
-	}
-
-	// Documentation depends on everything except itself
-	for _, task := range tasks {
-		if task.Type == types.TaskTypeDocumentation {
-			for _, depTask := range tasks {
-				if depTask.Type != types.TaskTypeDocumentation && depTask.ID != task.ID {
-					ip.taskManager.AddDependency(task.ID, depTask.ID)
-				}
-			}
-		}
-	}
+	return ip.taskManager.ResolveArtifactDependencies(available)
 }
 
 // requiresAuth checks if the project requires authentication
@@ -0,0 +1,58 @@
+package generators
+
+import (
+	"testing"
+
+	"github.com/nohdol/claude-auto/internal/tasks"
+	"github.com/nohdol/claude-auto/internal/templates"
+	"github.com/nohdol/claude-auto/pkg/types"
+	"github.com/rs/zerolog"
+)
+
+func TestDecomposeTasksBuildsValidGraph(t *testing.T) {
+	tests := []struct {
+		name   string
+		preset string
+		idea   types.ProcessedIdea
+	}{
+		{"full stack", "", types.ProcessedIdea{HasFrontend: true, HasBackend: true, HasDatabase: true, Features: []string{"user login"}}},
+		{"backend only", "", types.ProcessedIdea{HasBackend: true}},
+		{"frontend only", "", types.ProcessedIdea{HasFrontend: true}},
+		{"nothing", "", types.ProcessedIdea{}},
+		{"preset", "gin-postgres", types.ProcessedIdea{HasBackend: true, HasDatabase: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := tasks.NewTaskManager(zerolog.Nop())
+			ip := NewIdeaProcessor(nil, tm, zerolog.Nop())
+			if tt.preset != "" {
+				preset, ok := templates.Get(tt.preset)
+				if !ok {
+					t.Fatalf("preset %s is not registered", tt.preset)
+				}
+				ip.SetPreset(preset)
+			}
+
+			created, err := ip.decomposeTasks(&tt.idea)
+			if err != nil {
+				t.Fatalf("decomposeTasks: %v", err)
+			}
+			if err := ip.setupDependencies(created); err != nil {
+				t.Fatalf("setupDependencies: %v", err)
+			}
+			if _, err := tm.GetExecutionOrder(); err != nil {
+				t.Fatalf("GetExecutionOrder: %v", err)
+			}
+
+			// Documentation runs last, after every other task
+			doc := created[len(created)-1]
+			if doc.Type != types.TaskTypeDocumentation {
+				t.Fatalf("last task is %s, want documentation", doc.Type)
+			}
+			if len(created) > 1 && len(doc.Dependencies) == 0 {
+				t.Error("documentation depends on no task")
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package generators
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	goModulePattern = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+	jsImportPattern = regexp.MustCompile(`(?:from\s+|require\(\s*|import\(\s*|import\s+)['"]([^'"]+)['"]`)
+	pyImportPattern = regexp.MustCompile(`(?m)^\s*(?:from\s+(\.*[\w.]*)\s+import\s+([\w\s,.*]+)|import\s+([\w.]+))`)
+)
+
+// jsExtensions are the extensions resolved for extensionless JavaScript imports
+var jsExtensions = []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", ".vue", ".svelte"}
+
+// findImporters returns the files that directly import one of the changed files,
+// mapped to the changed files they import. Go packages, relative JavaScript and
+// TypeScript imports, and Python modules are recognized.
+func findImporters(root string, files []RepositoryFile, changed map[string]bool) map[string][]string {
+	goModule := ""
+	if content, err := os.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+		if match := goModulePattern.FindSubmatch(content); match != nil {
+			goModule = string(match[1])
+		}
+	}
+
+	// Import paths of the Go packages and Python modules that changed
+	goPackages := make(map[string][]string)
+	pyModules := make(map[string]string)
+	for file := range changed {
+		switch path.Ext(file) {
+		case ".go":
+			if goModule != "" && !strings.HasSuffix(file, "_test.go") {
+				importPath := goModule
+				if dir := path.Dir(file); dir != "." {
+					importPath += "/" + dir
+				}
+				goPackages[importPath] = append(goPackages[importPath], file)
+			}
+		case ".py":
+			pyModules[pythonModule(file)] = file
+		}
+	}
+
+	importers := make(map[string][]string)
+	for _, file := range files {
+		if changed[file.Path] {
+			continue
+		}
+
+		var imported []string
+		switch ext := path.Ext(file.Path); {
+		case ext == ".go" && len(goPackages) > 0:
+			imported = goImports(root, file.Path, goPackages)
+		case ext == ".py" && len(pyModules) > 0:
+			imported = pythonImports(root, file.Path, pyModules)
+		case isJSFile(ext):
+			imported = jsImports(root, file.Path, changed)
+		}
+
+		if len(imported) > 0 {
+			importers[file.Path] = imported
+		}
+	}
+	return importers
+}
+
+// goImports returns the changed files of the packages a Go file imports
+func goImports(root, file string, packages map[string][]string) []string {
+	content, err := os.ReadFile(filepath.Join(root, file))
+	if err != nil {
+		return nil
+	}
+
+	var imported []string
+	for importPath, files := range packages {
+		if strings.Contains(string(content), `"`+importPath+`"`) {
+			imported = append(imported, files...)
+		}
+	}
+	return imported
+}
+
+// jsImports returns the changed files a JavaScript or TypeScript file imports
+// through relative paths
+func jsImports(root, file string, changed map[string]bool) []string {
+	content, err := os.ReadFile(filepath.Join(root, file))
+	if err != nil {
+		return nil
+	}
+
+	var imported []string
+	for _, match := range jsImportPattern.FindAllStringSubmatch(string(content), -1) {
+		spec := match[1]
+		if !strings.HasPrefix(spec, "./") && !strings.HasPrefix(spec, "../") {
+			continue
+		}
+
+		target := path.Join(path.Dir(file), spec)
+		candidates := []string{target}
+		for _, ext := range jsExtensions {
+			candidates = append(candidates, target+ext, target+"/index"+ext)
+		}
+		for _, candidate := range candidates {
+			if changed[candidate] {
+				imported = append(imported, candidate)
+				break
+			}
+		}
+	}
+	return imported
+}
+
+// pythonImports returns the changed modules a Python file imports
+func pythonImports(root, file string, modules map[string]string) []string {
+	content, err := os.ReadFile(filepath.Join(root, file))
+	if err != nil {
+		return nil
+	}
+
+	var imported []string
+	for _, match := range pyImportPattern.FindAllStringSubmatch(string(content), -1) {
+		var names []string
+		if match[3] != "" {
+			names = []string{match[3]}
+		} else {
+			base := resolvePythonModule(file, match[1])
+			names = append(names, base)
+			// from package import module
+			for _, name := range strings.Split(match[2], ",") {
+				if fields := strings.Fields(name); len(fields) > 0 && fields[0] != "*" {
+					names = append(names, strings.TrimPrefix(base+"."+fields[0], "."))
+				}
+			}
+		}
+
+		// Modules may be imported relative to a source root below the project
+		for _, name := range names {
+			for module, changedFile := range modules {
+				if name != "" && (module == name || strings.HasSuffix(module, "."+name)) {
+					imported = append(imported, changedFile)
+				}
+			}
+		}
+	}
+	return imported
+}
+
+// pythonModule returns the dotted module name of a Python file
+func pythonModule(file string) string {
+	module := strings.TrimSuffix(strings.TrimSuffix(file, ".py"), "/__init__")
+	return strings.ReplaceAll(module, "/", ".")
+}
+
+// resolvePythonModule resolves a possibly relative module name imported by file
+func resolvePythonModule(file, module string) string {
+	dots := len(module) - len(strings.TrimLeft(module, "."))
+	if dots == 0 {
+		return module
+	}
+
+	dir := path.Dir(file)
+	for i := 1; i < dots; i++ {
+		dir = path.Dir(dir)
+	}
+	base := ""
+	if dir != "." {
+		base = strings.ReplaceAll(dir, "/", ".")
+	}
+	if rest := module[dots:]; rest != "" {
+		if base == "" {
+			return rest
+		}
+		return base + "." + rest
+	}
+	return base
+}
+
+// isJSFile reports whether ext is a JavaScript or TypeScript source extension
+func isJSFile(ext string) bool {
+	for _, jsExt := range jsExtensions {
+		if ext == jsExt {
+			return true
+		}
+	}
+	return false
+}
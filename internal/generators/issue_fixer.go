@@ -0,0 +1,236 @@
+package generators
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/nohdol/claude-auto/internal/tasks"
+	"github.com/nohdol/claude-auto/pkg/types"
+	"github.com/rs/zerolog"
+)
+
+// FixStatus is the outcome of fixing an issue
+type FixStatus string
+
+const (
+	FixStatusFixed   FixStatus = "fixed"   // The fix passed the gates and was kept
+	FixStatusFailed  FixStatus = "failed"  // The fix failed or broke a gate and was discarded
+	FixStatusSkipped FixStatus = "skipped" // The issue was not attempted or needed no change
+)
+
+// IssueFilter selects the issues to fix
+type IssueFilter struct {
+	Severity  string   // minimum severity, empty for any
+	Types     []string // issue types, empty for any
+	MaxIssues int      // most severe issues to keep, 0 for all
+}
+
+// Validate checks the severity and types of the filter
+func (f IssueFilter) Validate() error {
+	if f.Severity != "" && !validSeverities[f.Severity] {
+		return fmt.Errorf("unknown severity %q (critical, high, medium, low)", f.Severity)
+	}
+	for _, issueType := range f.Types {
+		if !validIssueTypes[issueType] {
+			return fmt.Errorf("unknown issue type %q (bug, security, performance, quality)", issueType)
+		}
+	}
+	if f.MaxIssues < 0 {
+		return fmt.Errorf("max issues must not be negative")
+	}
+	return nil
+}
+
+// Filter returns the issues matching the filter, most severe first, and the
+// issues it leaves out
+func (f IssueFilter) Filter(issues []Issue) (selected, skipped []Issue) {
+	sorted := append([]Issue(nil), issues...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return severityRank[sorted[a].Severity] < severityRank[sorted[b].Severity]
+	})
+
+	for _, issue := range sorted {
+		switch {
+		case f.Severity != "" && severityRank[issue.Severity] > severityRank[f.Severity]:
+			skipped = append(skipped, issue)
+		case len(f.Types) > 0 && !slices.Contains(f.Types, issue.Type):
+			skipped = append(skipped, issue)
+		case f.MaxIssues > 0 && len(selected) >= f.MaxIssues:
+			skipped = append(skipped, issue)
+		default:
+			selected = append(selected, issue)
+		}
+	}
+	return selected, skipped
+}
+
+// IssueFix is what happened to one issue
+type IssueFix struct {
+	Issue  Issue     `json:"issue"`
+	Status FixStatus `json:"status"`
+	TaskID string    `json:"task_id,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// IssueFixer turns analysis issues into fix tasks. Issues are grouped by file
+// so tasks running in parallel never edit the same file; issues without a file
+// are fixed last, once every file task has run.
+type IssueFixer struct {
+	taskManager *tasks.TaskManager
+	logger      zerolog.Logger
+	dir         string // package directory relative to the project
+	fixes       []IssueFix
+}
+
+// NewIssueFixer creates a new issue fixer
+func NewIssueFixer(tm *tasks.TaskManager, logger zerolog.Logger) *IssueFixer {
+	return &IssueFixer{
+		taskManager: tm,
+		logger:      logger,
+	}
+}
+
+// SetPackage sets the workspace package the issues were found in; their files
+// are relative to it and the tasks run in it
+func (f *IssueFixer) SetPackage(dir string) {
+	f.dir = dir
+}
+
+// Skip records issues that are not fixed
+func (f *IssueFixer) Skip(issues []Issue, reason string) {
+	for _, issue := range issues {
+		f.fixes = append(f.fixes, IssueFix{Issue: issue, Status: FixStatusSkipped, Reason: reason})
+	}
+}
+
+// CreateTasks creates a fix task for each file with issues
+func (f *IssueFixer) CreateTasks(issues []Issue) []*types.Task {
+	var files []string
+	byFile := make(map[string][]Issue)
+	for _, issue := range issues {
+		if _, seen := byFile[issue.File]; !seen {
+			files = append(files, issue.File)
+		}
+		byFile[issue.File] = append(byFile[issue.File], issue)
+	}
+
+	var created []*types.Task
+	var unscoped *types.Task
+	for _, file := range files {
+		group := byFile[file]
+		task := f.taskManager.CreateTask(types.TaskTypeFix, severityRank[group[0].Severity], f.buildFixPrompt(file, group))
+		task.WorkDir = f.dir
+		if file != "" {
+			f.taskManager.SetTaskContext(task.ID, tasks.ContextScope, path.Join(f.dir, file))
+		} else {
+			unscoped = task
+		}
+
+		for _, issue := range group {
+			f.fixes = append(f.fixes, IssueFix{Issue: issue, TaskID: task.ID})
+		}
+		created = append(created, task)
+	}
+
+	// Issues without a file may touch any file, so they wait for the others
+	if unscoped != nil {
+		for _, task := range created {
+			if task != unscoped {
+				f.taskManager.AddDependency(unscoped.ID, task.ID)
+			}
+		}
+	}
+
+	f.logger.Info().
+		Int("issues", len(issues)).
+		Int("tasks", len(created)).
+		Msg("Fix tasks created")
+
+	return created
+}
+
+// Results reports what happened to each issue once the tasks have run
+func (f *IssueFixer) Results() []IssueFix {
+	results := make([]IssueFix, len(f.fixes))
+	for i, fix := range f.fixes {
+		results[i] = fix
+		if fix.TaskID == "" {
+			continue
+		}
+
+		task, exists := f.taskManager.GetTask(fix.TaskID)
+		if !exists {
+			continue
+		}
+		switch task.Status {
+		case types.TaskStatusCompleted:
+			if changedInScope(task) {
+				results[i].Status = FixStatusFixed
+			} else {
+				results[i].Status = FixStatusSkipped
+				results[i].Reason = "no changes were made"
+			}
+		case types.TaskStatusFailed:
+			results[i].Status = FixStatusFailed
+			if task.Error != nil {
+				results[i].Reason = task.Error.Error()
+			}
+		default:
+			results[i].Status = FixStatusSkipped
+			results[i].Reason = "not run"
+		}
+	}
+	return results
+}
+
+// changedInScope reports whether a task changed a file of its scope, or any
+// file when it has none
+func changedInScope(task *types.Task) bool {
+	scope := task.Context[tasks.ContextScope]
+	for _, file := range task.Changes.Files() {
+		if scope == "" || slices.Contains(strings.Split(scope, ","), file) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildFixPrompt builds the prompt fixing the issues of one file
+func (f *IssueFixer) buildFixPrompt(file string, issues []Issue) string {
+	var b strings.Builder
+	if f.dir != "" {
+		fmt.Fprintf(&b, "작업 디렉토리: 모노레포의 %s 패키지\n다른 패키지는 수정하지 마세요.\n\n", f.dir)
+	}
+	if file != "" {
+		fmt.Fprintf(&b, "%s 파일에서 발견된 다음 문제를 수정해주세요.\n\n", file)
+	} else {
+		b.WriteString("프로젝트에서 발견된 다음 문제를 수정해주세요.\n\n")
+	}
+
+	for i, issue := range issues {
+		fmt.Fprintf(&b, "%d. [%s, %s] %s", i+1, issue.Severity, issue.Type, issue.Description)
+		if issue.RuleID != "" {
+			fmt.Fprintf(&b, " (%s)", issue.RuleID)
+		}
+		if location := issue.Location(); location != "" {
+			fmt.Fprintf(&b, "\n   위치: %s", location)
+		}
+		if issue.Suggestion != "" {
+			fmt.Fprintf(&b, "\n   제안: %s", issue.Suggestion)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n규칙:\n")
+	b.WriteString("- 나열된 문제만 최소한으로 수정하고 그 외의 동작은 바꾸지 마세요.\n")
+	if file != "" {
+		b.WriteString("- 다른 작업이 동시에 다른 파일을 수정하므로 가능하면 이 파일만 수정하세요.\n")
+	}
+	b.WriteString("- 수정 후에도 빌드와 테스트가 통과해야 합니다.\n")
+	b.WriteString("- 실제로 문제가 아니라고 판단되면 수정하지 말고 이유를 설명해주세요.\n")
+	b.WriteString("- git 명령은 실행하지 마세요.\n")
+	return b.String()
+}
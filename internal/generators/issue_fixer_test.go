@@ -0,0 +1,50 @@
+package generators
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nohdol/claude-auto/internal/tasks"
+	"github.com/nohdol/claude-auto/pkg/types"
+	"github.com/rs/zerolog"
+)
+
+func TestIssueFixerResults(t *testing.T) {
+	tm := tasks.NewTaskManager(zerolog.Nop())
+	fixer := NewIssueFixer(tm, zerolog.Nop())
+
+	issue := func(file string) Issue {
+		return Issue{Type: "bug", Severity: "high", File: file, Description: "bug in " + file}
+	}
+	created := fixer.CreateTasks([]Issue{issue("fixed.go"), issue("unchanged.go"), issue("crashed.go"), issue("pending.go")})
+	fixer.Skip([]Issue{issue("filtered.go")}, "filtered out")
+
+	fixed, unchanged, crashed := created[0].ID, created[1].ID, created[2].ID
+	tm.SetTaskChanges(fixed, &types.ChangeSet{Modified: []string{"fixed.go"}})
+	tm.UpdateTaskStatus(fixed, types.TaskStatusCompleted)
+	tm.SetTaskChanges(unchanged, &types.ChangeSet{Modified: []string{"other.go"}})
+	tm.UpdateTaskStatus(unchanged, types.TaskStatusCompleted)
+	// A run that exits non-zero fails its task, whatever it printed
+	tm.SetTaskError(crashed, errors.New("claude exited with code 1: exit status 1"))
+
+	want := map[string]struct {
+		status FixStatus
+		reason string
+	}{
+		"fixed.go":     {FixStatusFixed, ""},
+		"unchanged.go": {FixStatusSkipped, "no changes were made"},
+		"crashed.go":   {FixStatusFailed, "claude exited with code 1: exit status 1"},
+		"pending.go":   {FixStatusSkipped, "not run"},
+		"filtered.go":  {FixStatusSkipped, "filtered out"},
+	}
+	results := fixer.Results()
+	if len(results) != len(want) {
+		t.Fatalf("Results() returned %d fixes, want %d", len(results), len(want))
+	}
+	for _, result := range results {
+		w := want[result.Issue.File]
+		if result.Status != w.status || result.Reason != w.reason {
+			t.Errorf("%s: got %s (%q), want %s (%q)", result.Issue.File, result.Status, result.Reason, w.status, w.reason)
+		}
+	}
+}
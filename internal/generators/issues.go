@@ -0,0 +1,276 @@
+package generators
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidAnalysis is returned when an analysis response holds no valid JSON
+var ErrInvalidAnalysis = errors.New("invalid analysis response")
+
+// defaultConfidence is used when an issue does not state its confidence
+const defaultConfidence = 0.5
+
+// validIssueTypes and validSeverities are the values an issue may use
+var (
+	validIssueTypes = map[string]bool{"bug": true, "security": true, "performance": true, "quality": true}
+	validSeverities = map[string]bool{"critical": true, "high": true, "medium": true, "low": true}
+)
+
+// severityRank orders severities from most to least severe
+var severityRank = map[string]int{"critical": 0, "high": 1, "medium": 2, "low": 3}
+
+// analysisResponse is the JSON document requested by the analysis prompt
+type analysisResponse struct {
+	Issues       []Issue  `json:"issues"`
+	Improvements []string `json:"improvements"`
+}
+
+// analysisResponseFormat describes analysisResponse to Claude
+const analysisResponseFormat = `다른 설명 없이 다음 형식의 JSON만 응답해주세요:
+{
+  "issues": [
+    {
+      "type": "bug | security | performance | quality",
+      "severity": "critical | high | medium | low",
+      "file": "프로젝트 루트 기준 상대 경로",
+      "line": 시작 줄 번호 (모르면 0),
+      "end_line": 끝 줄 번호 (모르면 0),
+      "rule_id": "짧은 규칙 ID (예: sql-injection, unchecked-error)",
+      "description": "문제 설명",
+      "suggestion": "개선 제안",
+      "confidence": 0.0에서 1.0 사이의 확신도
+    }
+  ],
+  "improvements": ["아키텍처, 테스트, 의존성 개선 제안"]
+}`
+
+// ParseIssues parses the JSON analysis response of the project at root into
+// validated, deduplicated issues and improvements. Invalid issues are dropped
+// and described in warnings.
+func ParseIssues(output, root string) (issues []Issue, improvements []string, warnings []string, err error) {
+	var response analysisResponse
+	jsonStr, err := extractJSON(output, func(candidate string) error {
+		response = analysisResponse{}
+		if strings.HasPrefix(candidate, "[") {
+			return json.Unmarshal([]byte(candidate), &response.Issues)
+		}
+		return json.Unmarshal([]byte(candidate), &response)
+	})
+	if jsonStr == "" && err == nil {
+		return nil, nil, nil, fmt.Errorf("%w: no JSON found in %q", ErrInvalidAnalysis, truncate(output, 200))
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %v", ErrInvalidAnalysis, err)
+	}
+
+	for i := range response.Issues {
+		issue := &response.Issues[i]
+		if err := issue.normalize(root); err != nil {
+			warnings = append(warnings, fmt.Sprintf("issue %d dropped: %v", i+1, err))
+			continue
+		}
+		issues = append(issues, *issue)
+	}
+
+	for _, improvement := range response.Improvements {
+		if improvement = strings.TrimSpace(improvement); improvement != "" {
+			improvements = append(improvements, improvement)
+		}
+	}
+
+	return DedupeIssues(issues), improvements, warnings, nil
+}
+
+// normalize lower-cases and cleans the fields of an issue of the project at
+// root and validates them
+func (i *Issue) normalize(root string) error {
+	i.Type = strings.ToLower(strings.TrimSpace(i.Type))
+	i.Severity = strings.ToLower(strings.TrimSpace(i.Severity))
+	i.RuleID = strings.ToLower(strings.TrimSpace(i.RuleID))
+	i.Description = strings.TrimSpace(i.Description)
+	i.Suggestion = strings.TrimSpace(i.Suggestion)
+	i.Source = SourceClaude
+
+	if !validIssueTypes[i.Type] {
+		return fmt.Errorf("invalid type %q", i.Type)
+	}
+	if !validSeverities[i.Severity] {
+		return fmt.Errorf("invalid severity %q", i.Severity)
+	}
+	if i.Description == "" {
+		return fmt.Errorf("missing description")
+	}
+
+	if file := strings.TrimSpace(i.File); file != "" {
+		file = filepath.Clean(file)
+		// Claude sometimes reports absolute paths inside the project
+		if filepath.IsAbs(file) {
+			if absRoot, err := filepath.Abs(root); err == nil {
+				if rel, err := filepath.Rel(absRoot, file); err == nil {
+					file = rel
+				}
+			}
+		}
+		if !filepath.IsLocal(file) {
+			return fmt.Errorf("file %q is outside the project", i.File)
+		}
+		i.File = filepath.ToSlash(file)
+	}
+
+	if i.Line < 0 || i.EndLine < 0 {
+		return fmt.Errorf("negative line number")
+	}
+	if i.EndLine < i.Line {
+		i.EndLine = i.Line
+	}
+
+	if i.Confidence < 0 || i.Confidence > 1 {
+		return fmt.Errorf("confidence %v is outside [0, 1]", i.Confidence)
+	}
+	if i.Confidence == 0 {
+		i.Confidence = defaultConfidence
+	}
+	return nil
+}
+
+// Location returns the file and line range of an issue, such as "main.go:10-12"
+func (i *Issue) Location() string {
+	switch {
+	case i.File == "":
+		return ""
+	case i.Line == 0:
+		return i.File
+	case i.EndLine > i.Line:
+		return fmt.Sprintf("%s:%d-%d", i.File, i.Line, i.EndLine)
+	}
+	return fmt.Sprintf("%s:%d", i.File, i.Line)
+}
+
+// key identifies an issue for deduplication: the same rule, or the same type and
+// description without one, at the same place
+func (i *Issue) key() string {
+	what := i.RuleID
+	if what == "" {
+		what = i.Type + ":" + strings.ToLower(i.Description)
+	}
+	return fmt.Sprintf("%s:%d:%s", i.File, i.Line, what)
+}
+
+// DedupeIssues removes duplicate issues, keeping the most severe and confident
+// of each, and sorts the result by severity, file and line
+func DedupeIssues(issues []Issue) []Issue {
+	byKey := make(map[string]int)
+	var result []Issue
+
+	for _, issue := range issues {
+		key := issue.key()
+		idx, seen := byKey[key]
+		if !seen {
+			byKey[key] = len(result)
+			result = append(result, issue)
+			continue
+		}
+
+		kept := &result[idx]
+		if severityRank[issue.Severity] < severityRank[kept.Severity] {
+			kept.Severity = issue.Severity
+		}
+		if issue.Confidence > kept.Confidence {
+			kept.Confidence = issue.Confidence
+		}
+		if issue.EndLine > kept.EndLine {
+			kept.EndLine = issue.EndLine
+		}
+		if kept.Suggestion == "" {
+			kept.Suggestion = issue.Suggestion
+		}
+	}
+
+	sort.SliceStable(result, func(a, b int) bool {
+		if ra, rb := severityRank[result[a].Severity], severityRank[result[b].Severity]; ra != rb {
+			return ra < rb
+		}
+		if result[a].File != result[b].File {
+			return result[a].File < result[b].File
+		}
+		return result[a].Line < result[b].Line
+	})
+	return result
+}
+
+// extractJSON returns the first balanced JSON object or array in the output
+// that decode accepts, skipping brackets inside strings. Prose before the JSON
+// may hold brackets of its own, so every candidate is tried in turn; when none
+// is accepted the error of the first one is returned.
+func extractJSON(output string, decode func(candidate string) error) (string, error) {
+	var firstErr error
+	for start := 0; start < len(output); start++ {
+		if output[start] != '{' && output[start] != '[' {
+			continue
+		}
+		candidate := balancedJSON(output[start:])
+		if candidate == "" {
+			continue
+		}
+		err := decode(candidate)
+		if err == nil {
+			return candidate, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return "", firstErr
+}
+
+// balancedJSON returns the prefix of s up to the bracket closing its first one
+func balancedJSON(s string) string {
+	depth := 0
+	inString, escaped := false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return s[:i+1]
+			}
+		}
+	}
+	return ""
+}
+
+// truncate shortens s to at most n bytes for error messages, cutting on a
+// rune boundary
+func truncate(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) > n {
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		return s[:n] + "..."
+	}
+	return s
+}
@@ -0,0 +1,174 @@
+package generators
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParseIssues(t *testing.T) {
+	root := t.TempDir()
+	output := "분석 결과입니다 [참고]:\n```json\n" + `{
+  "issues": [
+    {"type": "Bug", "severity": "HIGH", "file": "./cmd/main.go", "line": 10, "rule_id": "Unchecked-Error", "description": " error ignored ", "confidence": 0.9},
+    {"type": "security", "severity": "critical", "file": "` + filepath.ToSlash(filepath.Join(root, "db", "query.go")) + `", "line": 5, "end_line": 3, "description": "SQL built with {user} input"},
+    {"type": "style", "severity": "low", "file": "a.go", "description": "bad type"},
+    {"type": "bug", "severity": "urgent", "file": "a.go", "description": "bad severity"},
+    {"type": "bug", "severity": "low", "file": "../outside.go", "description": "outside"},
+    {"type": "bug", "severity": "low", "file": "a.go", "description": ""},
+    {"type": "bug", "severity": "low", "file": "a.go", "line": -1, "description": "negative"},
+    {"type": "bug", "severity": "low", "file": "a.go", "description": "confident", "confidence": 1.5}
+  ],
+  "improvements": ["  add tests  ", ""]
+}` + "\n```"
+
+	issues, improvements, warnings, err := ParseIssues(output, root)
+	if err != nil {
+		t.Fatalf("ParseIssues: %v", err)
+	}
+
+	want := []Issue{
+		{Type: "security", Severity: "critical", File: "db/query.go", Line: 5, EndLine: 5, Description: "SQL built with {user} input", Confidence: defaultConfidence, Source: SourceClaude},
+		{Type: "bug", Severity: "high", File: "cmd/main.go", Line: 10, EndLine: 10, RuleID: "unchecked-error", Description: "error ignored", Confidence: 0.9, Source: SourceClaude},
+	}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("issues = %+v\nwant %+v", issues, want)
+	}
+	if !reflect.DeepEqual(improvements, []string{"add tests"}) {
+		t.Errorf("improvements = %q", improvements)
+	}
+	if len(warnings) != 6 {
+		t.Errorf("warnings = %q, want one per dropped issue", warnings)
+	}
+}
+
+func TestParseIssuesArray(t *testing.T) {
+	issues, _, _, err := ParseIssues(`[{"type":"bug","severity":"low","file":"a.go","description":"x"}]`, t.TempDir())
+	if err != nil {
+		t.Fatalf("ParseIssues: %v", err)
+	}
+	if len(issues) != 1 || issues[0].File != "a.go" {
+		t.Errorf("issues = %+v", issues)
+	}
+}
+
+func TestParseIssuesInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{"no JSON", "문제를 찾지 못했습니다"},
+		{"truncated JSON", `{"issues": [{"type": "bug"`},
+		{"wrong shape", `{"issues": "none"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := ParseIssues(tt.output, t.TempDir()); !errors.Is(err, ErrInvalidAnalysis) {
+				t.Errorf("ParseIssues(%q) = %v, want ErrInvalidAnalysis", tt.output, err)
+			}
+		})
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	decodeObject := func(candidate string) error {
+		var v map[string]interface{}
+		return json.Unmarshal([]byte(candidate), &v)
+	}
+
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{"plain", `{"a":1}`, `{"a":1}`, false},
+		{"prose brackets first", `see [docs] and {note}: {"a":[1,2]}`, `{"a":[1,2]}`, false},
+		{"brackets in strings", `{"a":"}{][","b":"\"}"}`, `{"a":"}{][","b":"\"}"}`, false},
+		{"nested", "```json\n{\"a\":{\"b\":{}}}\n```", `{"a":{"b":{}}}`, false},
+		{"none", "no json", "", false},
+		{"unbalanced", `{"a":1`, "", false},
+		{"invalid only", `{not json}`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractJSON(tt.output, decodeObject)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractJSON(%q) error = %v, wantErr %v", tt.output, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("extractJSON(%q) = %q, want %q", tt.output, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDedupeIssues(t *testing.T) {
+	issues := []Issue{
+		{Type: "bug", Severity: "low", File: "b.go", Line: 3, RuleID: "nil-deref", Description: "first", Confidence: 0.4},
+		{Type: "bug", Severity: "high", File: "b.go", Line: 3, RuleID: "nil-deref", Description: "second", Suggestion: "check nil", Confidence: 0.8, EndLine: 6},
+		{Type: "quality", Severity: "medium", File: "a.go", Line: 1, Description: "Long function"},
+		{Type: "quality", Severity: "medium", File: "a.go", Line: 1, Description: "long function"},
+		{Type: "quality", Severity: "medium", File: "a.go", Line: 1, Description: "deep nesting"},
+		{Type: "security", Severity: "critical", File: "z.go", Line: 9, RuleID: "sql-injection", Description: "sql"},
+	}
+
+	got := DedupeIssues(issues)
+
+	var summary []string
+	for _, issue := range got {
+		summary = append(summary, issue.Severity+" "+issue.Location()+" "+issue.Description)
+	}
+	want := []string{
+		"critical z.go:9 sql",
+		"high b.go:3-6 first",
+		"medium a.go:1 Long function",
+		"medium a.go:1 deep nesting",
+	}
+	if !reflect.DeepEqual(summary, want) {
+		t.Errorf("DedupeIssues = %q\nwant %q", summary, want)
+	}
+
+	merged := got[1]
+	if merged.Confidence != 0.8 || merged.Suggestion != "check nil" {
+		t.Errorf("merged duplicate = %+v, want the highest confidence and the suggestion", merged)
+	}
+}
+
+func TestIssueLocation(t *testing.T) {
+	tests := []struct {
+		issue Issue
+		want  string
+	}{
+		{Issue{}, ""},
+		{Issue{File: "a.go"}, "a.go"},
+		{Issue{File: "a.go", Line: 4}, "a.go:4"},
+		{Issue{File: "a.go", Line: 4, EndLine: 4}, "a.go:4"},
+		{Issue{File: "a.go", Line: 4, EndLine: 8}, "a.go:4-8"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.issue.Location(); got != tt.want {
+			t.Errorf("Location(%+v) = %q, want %q", tt.issue, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("  short  ", 10); got != "short" {
+		t.Errorf("truncate of a short string = %q", got)
+	}
+	long := strings.Repeat("분석", 100)
+	for n := 10; n < 13; n++ {
+		got := truncate(long, n)
+		if !utf8.ValidString(got) || len(got) > n+len("...") {
+			t.Errorf("truncate(%d) = %q", n, got)
+		}
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"strings"
 
 	"github.com/nohdol/claude-auto/internal/core"
+	"github.com/nohdol/claude-auto/internal/detect"
+	"github.com/nohdol/claude-auto/internal/tasks"
+	"github.com/nohdol/claude-auto/pkg/types"
 	"github.com/rs/zerolog"
 )
 
@@ -16,6 +19,11 @@ import (
 type ProjectAnalyzer struct {
 	claudeExecutor *core.ClaudeExecutor
 	logger         zerolog.Logger
+	indexOptions   IndexOptions
+	maxWorkers     int
+	cache          bool
+	cacheRoot      string
+	staticChecks   bool
 }
 
 // NewProjectAnalyzer creates a new project analyzer
@@ -23,12 +31,44 @@ func NewProjectAnalyzer(ce *core.ClaudeExecutor, logger zerolog.Logger) *Project
 	return &ProjectAnalyzer{
 		claudeExecutor: ce,
 		logger:         logger,
+		indexOptions:   DefaultIndexOptions(),
+		maxWorkers:     3,
+		cache:          true,
+		staticChecks:   true,
 	}
 }
 
+// SetIndexOptions sets the chunk budget of the repository index
+func (pa *ProjectAnalyzer) SetIndexOptions(opts IndexOptions) {
+	pa.indexOptions = opts
+}
+
+// SetMaxWorkers sets how many chunks are analyzed in parallel
+func (pa *ProjectAnalyzer) SetMaxWorkers(n int) {
+	pa.maxWorkers = n
+}
+
+// SetCache turns the per-file analysis cache on or off
+func (pa *ProjectAnalyzer) SetCache(enabled bool) {
+	pa.cache = enabled
+}
+
+// SetCacheRoot keeps the analysis cache at the workspace root when a package
+// of the workspace is analyzed; by default it is kept in the analyzed project
+func (pa *ProjectAnalyzer) SetCacheRoot(root string) {
+	pa.cacheRoot = root
+}
+
+// SetStaticChecks turns the local checks run before Claude on or off
+func (pa *ProjectAnalyzer) SetStaticChecks(enabled bool) {
+	pa.staticChecks = enabled
+}
+
 // ProjectInfo contains information about the analyzed project
 type ProjectInfo struct {
 	Path          string
+	Package       string   // workspace package name, empty for the workspace root
+	Dir           string   // package directory relative to the workspace root
 	Type          string   // web, api, cli, mobile
 	Language      string   // javascript, typescript, go, python, etc.
 	Framework     string   // react, vue, express, gin, etc.
@@ -37,21 +77,66 @@ type ProjectInfo struct {
 	Dependencies  []string
 	Issues        []Issue
 	Improvements  []string
-	Structure     map[string]int // file counts by type
+	Warnings      []string           // problems found while parsing the analysis
+	Structure     map[string]int     // file counts by type
+	Changed       []string           // changed files analyzed by AnalyzeChanges
+	Importers     []string           // files analyzed because they import a changed file
+	CachedFiles   int                // files whose issues came from the cache
+	StaticChecks  []string           // local checks run before Claude
+	Components    []detect.Component // detected languages, frameworks and tools
+	Packages      []*ProjectInfo     // workspace packages, each with the issues in its files
 }
 
 // Issue represents a problem found in the project
 type Issue struct {
-	Type        string // bug, security, performance, quality
-	Severity    string // critical, high, medium, low
-	File        string
-	Line        int
-	Description string
-	Suggestion  string
+	Type        string  `json:"type"`     // bug, security, performance, quality
+	Severity    string  `json:"severity"` // critical, high, medium, low
+	File        string  `json:"file"`
+	Line        int     `json:"line"`
+	EndLine     int     `json:"end_line"`
+	RuleID      string  `json:"rule_id"`
+	Description string  `json:"description"`
+	Suggestion  string  `json:"suggestion"`
+	Confidence  float64 `json:"confidence"`       // 0.0 to 1.0
+	Source      string  `json:"source,omitempty"` // static or claude
 }
 
 // AnalyzeProject analyzes an existing project
 func (pa *ProjectAnalyzer) AnalyzeProject(ctx context.Context, projectPath string) (*ProjectInfo, error) {
+	return pa.analyze(ctx, projectPath, nil)
+}
+
+// DetectProject describes a project from its manifests: its type, language,
+// framework and workspace packages. Unlike AnalyzeProject it neither runs
+// checks nor calls Claude.
+func DetectProject(projectPath string) (*ProjectInfo, error) {
+	if _, err := os.Stat(projectPath); err != nil {
+		return nil, fmt.Errorf("project path does not exist: %w", err)
+	}
+
+	detection := detect.Detect(projectPath)
+	projectType, language, framework := detection.Primary()
+	return &ProjectInfo{
+		Path:       projectPath,
+		Type:       projectType,
+		Language:   language,
+		Framework:  framework,
+		Components: detection.Components,
+		Packages:   packageInfos(projectPath, detection),
+	}, nil
+}
+
+// AnalyzeChanges analyzes the changed files of a project, given relative to the
+// project, and the files that directly import them
+func (pa *ProjectAnalyzer) AnalyzeChanges(ctx context.Context, projectPath string, changed []string) (*ProjectInfo, error) {
+	if changed == nil {
+		changed = []string{}
+	}
+	return pa.analyze(ctx, projectPath, changed)
+}
+
+// analyze analyzes the whole project, or only the changes when changed is not nil
+func (pa *ProjectAnalyzer) analyze(ctx context.Context, projectPath string, changed []string) (*ProjectInfo, error) {
 	pa.logger.Info().Str("path", projectPath).Msg("Analyzing project")
 
 	// Check if project exists
@@ -62,40 +147,195 @@ func (pa *ProjectAnalyzer) AnalyzeProject(ctx context.Context, projectPath strin
 	// Scan project structure
 	structure := pa.scanProjectStructure(projectPath)
 
-	// Detect project type and framework
-	projectType, language, framework := pa.detectProjectType(projectPath, structure)
+	// Detect languages, frameworks and tools
+	detection := detect.Detect(projectPath)
+	projectType, language, framework := detection.Primary()
 
-	// Read key files for analysis
-	keyFiles := pa.getKeyFiles(projectPath, projectType)
-	fileContents := pa.readKeyFiles(keyFiles)
+	info := &ProjectInfo{
+		Path:       projectPath,
+		Type:       projectType,
+		Language:   language,
+		Framework:  framework,
+		Structure:  structure,
+		Components: detection.Components,
+		Packages:   packageInfos(projectPath, detection),
+	}
 
-	// Build analysis prompt
-	prompt := pa.buildAnalysisPrompt(projectPath, structure, fileContents)
+	// Index the repository
+	index, err := IndexRepository(projectPath, pa.indexOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Narrow the index down to the changes and their direct importers
+	related := make(map[string][]string)
+	if changed != nil {
+		related = pa.selectChanges(index, changed, info)
+	} else if len(index.Files) == 0 {
+		return nil, fmt.Errorf("no source files to analyze in %s", projectPath)
+	}
 
-	// Get analysis from Claude
-	options := &core.ClaudeOptions{
-		Role:         "code-reviewer",
-		SystemPrompt: "You are an expert code reviewer and software architect.",
+	// Run the local checks first; their findings are reported even when
+	// Claude analyzes nothing
+	var issues []Issue
+	var static *StaticResult
+	if pa.staticChecks {
+		static = pa.RunStaticChecks(ctx, projectPath, index.Files, index.SecretFiles)
+		info.StaticChecks = static.Tools
+		info.Dependencies = static.Dependencies
+		issues = append(issues, static.Issues...)
 	}
 
-	response, err := pa.claudeExecutor.Execute(ctx, prompt, options)
+	// Reuse the issues of files analyzed before with the same content
+	var cache *analysisCache
+	if pa.cache {
+		cacheRoot := pa.cacheRoot
+		if cacheRoot == "" {
+			cacheRoot = projectPath
+		}
+		if cache, err = newAnalysisCache(projectPath, cacheRoot); err != nil {
+			pa.logger.Warn().Err(err).Msg("Analysis cache unavailable")
+		}
+	}
+	if cache != nil {
+		var pending []RepositoryFile
+		for _, file := range index.Files {
+			if cached, ok := cache.Load(file.Path, related[file.Path]); ok {
+				issues = append(issues, cached...)
+				info.CachedFiles++
+			} else {
+				pending = append(pending, file)
+			}
+		}
+		index.Files = pending
+	}
+
+	// Pack the remaining files into chunks
+	chunks, omitted := index.Chunks(pa.indexOptions)
+
+	pa.logger.Info().
+		Int("files", len(index.Files)).
+		Int("cached", info.CachedFiles).
+		Int("chunks", len(chunks)).
+		Int("omitted", omitted).
+		Msg("Repository indexed")
+
+	if omitted > 0 {
+		info.Warnings = append(info.Warnings,
+			fmt.Sprintf("%d lower-ranked files did not fit in %d chunks and were not analyzed", omitted, len(chunks)))
+	}
+
+	// Analyze the chunks in parallel and merge their issues
+	if len(chunks) > 0 {
+		chunkIssues, err := pa.analyzeChunks(ctx, info, chunks, cache, static)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, chunkIssues...)
+	}
+
+	info.Issues = DedupeIssues(issues)
+	assignIssues(info.Issues, info.Packages)
+	return info, nil
+}
+
+// selectChanges narrows the index to the changed files and their direct importers,
+// returning the changed files each importer imports
+func (pa *ProjectAnalyzer) selectChanges(index *RepositoryIndex, changed []string, info *ProjectInfo) map[string][]string {
+	changedSet := make(map[string]bool, len(changed))
+	for _, file := range changed {
+		changedSet[filepath.ToSlash(filepath.Clean(file))] = true
+	}
+	importers := findImporters(index.Root, index.Files, changedSet)
+
+	var selected []RepositoryFile
+	for _, file := range index.Files {
+		if changedSet[file.Path] {
+			selected = append(selected, file)
+			info.Changed = append(info.Changed, file.Path)
+		} else if _, ok := importers[file.Path]; ok {
+			selected = append(selected, file)
+			info.Importers = append(info.Importers, file.Path)
+		}
+	}
+	index.Files = selected
+
+	var secretFiles []string
+	for _, file := range index.SecretFiles {
+		if changedSet[file] {
+			secretFiles = append(secretFiles, file)
+		}
+	}
+	index.SecretFiles = secretFiles
+
+	pa.logger.Info().
+		Int("changed", len(info.Changed)).
+		Int("importers", len(info.Importers)).
+		Msg("Selected changed files")
+
+	return importers
+}
+
+// analyzeChunks reviews each chunk in its own task, caching the issues of every
+// file of the chunks that were analyzed, and returns the issues of all chunks
+func (pa *ProjectAnalyzer) analyzeChunks(ctx context.Context, info *ProjectInfo, chunks []Chunk, cache *analysisCache, static *StaticResult) ([]Issue, error) {
+	taskManager := tasks.NewTaskManager(pa.logger)
+	chunkOf := make(map[string]Chunk, len(chunks))
+	for i, chunk := range chunks {
+		task := taskManager.CreateTask(types.TaskTypeReview, i, pa.buildAnalysisPrompt(info, chunk, i+1, len(chunks), static))
+		chunkOf[task.ID] = chunk
+	}
+
+	executor := tasks.NewParallelExecutor(taskManager, pa.claudeExecutor, pa.maxWorkers, pa.logger)
+	executor.SetWorkDir(info.Path)
+
+	report, err := executor.ExecuteTasks(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze project: %w", err)
 	}
 
-	// Parse analysis results
-	info := &ProjectInfo{
-		Path:      projectPath,
-		Type:      projectType,
-		Language:  language,
-		Framework: framework,
-		Structure: structure,
+	var issues []Issue
+	var failures []string
+	seen := make(map[string]bool)
+
+	for _, task := range report.Tasks {
+		if task.Status != types.TaskStatusCompleted {
+			failures = append(failures, fmt.Sprintf("%s: %v", task.ID, task.Error))
+			continue
+		}
+
+		chunkIssues, err := pa.parseAnalysisResults(task, seen, info)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", task.ID, err))
+			continue
+		}
+		issues = append(issues, chunkIssues...)
+
+		if cache != nil {
+			pa.storeChunk(cache, chunkOf[task.ID], chunkIssues)
+		}
 	}
 
-	// Parse issues and improvements from response
-	pa.parseAnalysisResults(response.Output, info)
+	if len(failures) == len(report.Tasks) {
+		return nil, fmt.Errorf("failed to analyze project: %s", strings.Join(failures, "; "))
+	}
+	info.Warnings = append(info.Warnings, failures...)
+	return issues, nil
+}
 
-	return info, nil
+// storeChunk caches the issues of each file of an analyzed chunk, including
+// files without issues
+func (pa *ProjectAnalyzer) storeChunk(cache *analysisCache, chunk Chunk, issues []Issue) {
+	byFile := make(map[string][]Issue)
+	for _, issue := range issues {
+		byFile[issue.File] = append(byFile[issue.File], issue)
+	}
+
+	for _, file := range chunk.Files {
+		if err := cache.Store(file.Path, byFile[file.Path]); err != nil {
+			pa.logger.Warn().Err(err).Str("file", file.Path).Msg("Failed to cache analysis")
+		}
+	}
 }
 
 // scanProjectStructure scans the project directory structure
@@ -123,170 +363,72 @@ func (pa *ProjectAnalyzer) scanProjectStructure(projectPath string) map[string]i
 	return structure
 }
 
-// detectProjectType detects the project type based on files
-func (pa *ProjectAnalyzer) detectProjectType(projectPath string, structure map[string]int) (projectType, language, framework string) {
-	// Check for package.json
-	if _, err := os.Stat(filepath.Join(projectPath, "package.json")); err == nil {
-		language = "javascript"
-
-		// Read package.json to detect framework
-		content, _ := os.ReadFile(filepath.Join(projectPath, "package.json"))
-		contentStr := string(content)
-
-		if strings.Contains(contentStr, "react") {
-			framework = "react"
-			projectType = "web"
-		} else if strings.Contains(contentStr, "vue") {
-			framework = "vue"
-			projectType = "web"
-		} else if strings.Contains(contentStr, "express") {
-			framework = "express"
-			projectType = "api"
-		} else if strings.Contains(contentStr, "next") {
-			framework = "nextjs"
-			projectType = "web"
-		}
+// buildAnalysisPrompt builds the analysis prompt for one chunk of the project,
+// listing the static findings in its files
+func (pa *ProjectAnalyzer) buildAnalysisPrompt(info *ProjectInfo, chunk Chunk, part, parts int, static *StaticResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `프로젝트 분석을 수행해주세요:
 
-		if structure[".ts"] > 0 || structure[".tsx"] > 0 {
-			language = "typescript"
-		}
-	}
+프로젝트 경로: %s
+프로젝트 유형: %s
+언어: %s
+프레임워크: %s
+
+파일 구조:
+`, info.Path, info.Type, info.Language, info.Framework)
 
-	// Check for go.mod
-	if _, err := os.Stat(filepath.Join(projectPath, "go.mod")); err == nil {
-		language = "go"
-		projectType = "api"
-
-		// Check for web frameworks
-		content, _ := os.ReadFile(filepath.Join(projectPath, "go.mod"))
-		if strings.Contains(string(content), "gin") {
-			framework = "gin"
-		} else if strings.Contains(string(content), "fiber") {
-			framework = "fiber"
-		} else if strings.Contains(string(content), "echo") {
-			framework = "echo"
+	// Add file structure summary
+	for ext, count := range info.Structure {
+		if count > 0 && ext != "" {
+			fmt.Fprintf(&b, "- %s 파일: %d개\n", ext, count)
 		}
 	}
 
-	// Check for requirements.txt or setup.py
-	if _, err := os.Stat(filepath.Join(projectPath, "requirements.txt")); err == nil {
-		language = "python"
-
-		content, _ := os.ReadFile(filepath.Join(projectPath, "requirements.txt"))
-		if strings.Contains(string(content), "django") {
-			framework = "django"
-			projectType = "web"
-		} else if strings.Contains(string(content), "flask") {
-			framework = "flask"
-			projectType = "api"
-		} else if strings.Contains(string(content), "fastapi") {
-			framework = "fastapi"
-			projectType = "api"
+	if len(info.Components) > 0 {
+		b.WriteString("\n감지된 구성 요소:\n")
+		for _, c := range info.Components {
+			fmt.Fprintf(&b, "- %s: %s", c.Kind, c.Name)
+			if c.Version != "" {
+				fmt.Fprintf(&b, " %s", c.Version)
+			}
+			fmt.Fprintf(&b, " (%s, %s)\n", c.Path, c.Evidence)
 		}
 	}
 
-	if projectType == "" {
-		projectType = "unknown"
+	if parts > 1 {
+		fmt.Fprintf(&b, "\n프로젝트를 %d개 묶음으로 나누어 분석합니다. 이번은 %d번째 묶음입니다.\n", parts, part)
+		b.WriteString("아래 파일의 문제만 보고해주세요. 다른 파일은 필요할 때 직접 읽어볼 수 있습니다.\n")
 	}
 
-	return projectType, language, framework
-}
-
-// getKeyFiles returns the key files to analyze based on project type
-func (pa *ProjectAnalyzer) getKeyFiles(projectPath string, projectType string) []string {
-	var keyFiles []string
-
-	// Common files
-	commonFiles := []string{
-		"README.md",
-		"package.json",
-		"go.mod",
-		"requirements.txt",
-		".env.example",
+	if len(info.Changed) > 0 {
+		fmt.Fprintf(&b, "\n변경된 파일: %s\n", strings.Join(info.Changed, ", "))
+		b.WriteString("변경된 파일을 가져오는(import) 파일은 변경의 영향을 받는 문제를 중심으로 확인해주세요.\n")
 	}
 
-	for _, file := range commonFiles {
-		fullPath := filepath.Join(projectPath, file)
-		if _, err := os.Stat(fullPath); err == nil {
-			keyFiles = append(keyFiles, fullPath)
-		}
+	if len(info.Dependencies) > 0 {
+		fmt.Fprintf(&b, "\n의존성 (%d개): %s\n", len(info.Dependencies), strings.Join(info.Dependencies, ", "))
 	}
 
-	// Add main source files based on project type
-	switch projectType {
-	case "web":
-		candidates := []string{
-			"src/App.js", "src/App.jsx", "src/App.tsx",
-			"src/index.js", "src/index.jsx", "src/index.tsx",
-			"pages/index.js", "pages/index.jsx", "pages/index.tsx",
-		}
-		for _, file := range candidates {
-			fullPath := filepath.Join(projectPath, file)
-			if _, err := os.Stat(fullPath); err == nil {
-				keyFiles = append(keyFiles, fullPath)
-				break
-			}
-		}
-	case "api":
-		candidates := []string{
-			"main.go", "cmd/main.go",
-			"app.js", "server.js", "index.js",
-			"app.py", "main.py",
-		}
-		for _, file := range candidates {
-			fullPath := filepath.Join(projectPath, file)
-			if _, err := os.Stat(fullPath); err == nil {
-				keyFiles = append(keyFiles, fullPath)
-				break
-			}
-		}
+	if findings := static.findingsFor(chunk.Files); findings != "" {
+		b.WriteString("\n정적 분석 결과 (로컬 도구가 찾은 문제):\n")
+		b.WriteString(findings)
+		b.WriteString("실제 문제인 항목은 같은 file, line, rule_id로 보고하고 원인과 개선 방법을 설명해주세요.\n")
+		b.WriteString("오탐으로 판단되는 항목은 보고하지 마세요.\n")
 	}
 
-	return keyFiles
-}
-
-// readKeyFiles reads the content of key files
-func (pa *ProjectAnalyzer) readKeyFiles(files []string) map[string]string {
-	contents := make(map[string]string)
-
-	for _, file := range files {
-		content, err := os.ReadFile(file)
-		if err == nil {
-			// Limit content size to avoid huge prompts
-			if len(content) > 5000 {
-				content = content[:5000]
-			}
-			contents[filepath.Base(file)] = string(content)
+	b.WriteString("\n파일 내용 (줄 번호 포함):\n")
+	for _, file := range chunk.Files {
+		content, truncated, err := readNumbered(filepath.Join(info.Path, file.Path), pa.indexOptions.ChunkTokens)
+		if err != nil {
+			continue
 		}
-	}
-
-	return contents
-}
-
-// buildAnalysisPrompt builds the analysis prompt for Claude
-func (pa *ProjectAnalyzer) buildAnalysisPrompt(projectPath string, structure map[string]int, fileContents map[string]string) string {
-	prompt := fmt.Sprintf(`프로젝트 분석을 수행해주세요:
-
-프로젝트 경로: %s
-
-파일 구조:
-`, projectPath)
-
-	// Add file structure summary
-	for ext, count := range structure {
-		if count > 0 && ext != "" {
-			prompt += fmt.Sprintf("- %s 파일: %d개\n", ext, count)
+		fmt.Fprintf(&b, "\n=== %s ===\n%s", file.Path, content)
+		if truncated {
+			b.WriteString("... (이하 생략)\n")
 		}
 	}
 
-	prompt += "\n주요 파일 내용:\n"
-
-	// Add key file contents
-	for filename, content := range fileContents {
-		prompt += fmt.Sprintf("\n=== %s ===\n%s\n", filename, content)
-	}
-
-	prompt += `
+	b.WriteString(`
 다음 사항을 분석해주세요:
 
 1. 코드 품질 문제점
@@ -297,51 +439,33 @@ func (pa *ProjectAnalyzer) buildAnalysisPrompt(projectPath string, structure map
 6. 의존성 업데이트 필요 사항
 7. 코드 중복 및 리팩토링 대상
 
-각 문제에 대해 다음 형식으로 응답해주세요:
-- 문제 유형 (bug/security/performance/quality)
-- 심각도 (critical/high/medium/low)
-- 파일 위치
-- 문제 설명
-- 개선 제안`
+각 문제는 issues에, 특정 위치가 없는 개선 제안은 improvements에 넣어주세요.
+같은 문제를 중복해서 보고하지 마세요.
 
-	return prompt
-}
-
-// parseAnalysisResults parses the analysis results from Claude
-func (pa *ProjectAnalyzer) parseAnalysisResults(output string, info *ProjectInfo) {
-	// Simple parsing - in production, use more sophisticated parsing
-	lines := strings.Split(output, "\n")
+`)
+	b.WriteString(analysisResponseFormat)
 
-	var currentIssue *Issue
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
+	return b.String()
+}
 
-		if strings.HasPrefix(line, "- 문제 유형:") || strings.HasPrefix(line, "- Type:") {
-			if currentIssue != nil && currentIssue.Description != "" {
-				info.Issues = append(info.Issues, *currentIssue)
-			}
-			currentIssue = &Issue{}
-			currentIssue.Type = strings.TrimSpace(strings.TrimPrefix(line, "- 문제 유형:"))
-		} else if currentIssue != nil {
-			if strings.HasPrefix(line, "- 심각도:") || strings.HasPrefix(line, "- Severity:") {
-				currentIssue.Severity = strings.TrimSpace(strings.TrimPrefix(line, "- 심각도:"))
-			} else if strings.HasPrefix(line, "- 파일:") || strings.HasPrefix(line, "- File:") {
-				currentIssue.File = strings.TrimSpace(strings.TrimPrefix(line, "- 파일:"))
-			} else if strings.HasPrefix(line, "- 설명:") || strings.HasPrefix(line, "- Description:") {
-				currentIssue.Description = strings.TrimSpace(strings.TrimPrefix(line, "- 설명:"))
-			} else if strings.HasPrefix(line, "- 제안:") || strings.HasPrefix(line, "- Suggestion:") {
-				currentIssue.Suggestion = strings.TrimSpace(strings.TrimPrefix(line, "- 제안:"))
-			}
-		}
+// parseAnalysisResults parses the JSON analysis result of a chunk, returning its
+// issues and adding its new improvements and warnings to info
+func (pa *ProjectAnalyzer) parseAnalysisResults(task *types.Task, seen map[string]bool, info *ProjectInfo) ([]Issue, error) {
+	chunkIssues, improvements, warnings, err := ParseIssues(task.Result, info.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse analysis: %w", err)
+	}
 
-		// Collect improvements
-		if strings.Contains(line, "개선") || strings.Contains(line, "improvement") {
-			info.Improvements = append(info.Improvements, line)
-		}
+	for _, warning := range warnings {
+		pa.logger.Warn().Str("task_id", task.ID).Str("warning", warning).Msg("Invalid issue in analysis")
+		info.Warnings = append(info.Warnings, fmt.Sprintf("%s: %s", task.ID, warning))
 	}
 
-	// Add last issue if exists
-	if currentIssue != nil && currentIssue.Description != "" {
-		info.Issues = append(info.Issues, *currentIssue)
+	for _, improvement := range improvements {
+		if !seen[improvement] {
+			seen[improvement] = true
+			info.Improvements = append(info.Improvements, improvement)
+		}
 	}
-}
\ No newline at end of file
+	return chunkIssues, nil
+}
@@ -0,0 +1,297 @@
+package generators
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/nohdol/claude-auto/internal/git"
+)
+
+// bytesPerToken estimates the tokens of source code sent to Claude,
+// including the line numbers added to each line
+const bytesPerToken = 3
+
+// churnCommits is the history depth used to measure churn
+const churnCommits = 500
+
+// IndexOptions limits what the repository index sends to Claude
+type IndexOptions struct {
+	ChunkTokens  int   // Token budget of one chunk
+	MaxChunks    int   // Chunks analyzed per run; the least important files are left out
+	MaxFileBytes int64 // Larger files are not indexed
+}
+
+// DefaultIndexOptions returns the default index limits
+func DefaultIndexOptions() IndexOptions {
+	return IndexOptions{
+		ChunkTokens:  20000,
+		MaxChunks:    8,
+		MaxFileBytes: 200 * 1024,
+	}
+}
+
+// RepositoryFile is an indexed source file
+type RepositoryFile struct {
+	Path  string // Relative to the project root, slash-separated
+	Size  int64
+	Churn int // Recent commits touching the file
+	Score float64
+}
+
+// tokens estimates the tokens of the file, capped at limit
+func (f *RepositoryFile) tokens(limit int) int {
+	tokens := int(f.Size/bytesPerToken) + 1
+	if tokens > limit {
+		return limit
+	}
+	return tokens
+}
+
+// RepositoryIndex lists the files of a project, most important first
+type RepositoryIndex struct {
+	Root    string
+	Files   []RepositoryFile
+	Ignored int // Files skipped as generated, binary or too large
+	// SecretFiles hold environment variables or keys; they are scanned for
+	// secrets but never sent to Claude
+	SecretFiles []string
+}
+
+// Chunk is a group of files analyzed together within the token budget
+type Chunk struct {
+	Files  []RepositoryFile
+	Tokens int
+}
+
+// skippedDirs are never indexed, whatever .gitignore says
+var skippedDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "dist": true, "build": true,
+	"target": true, "__pycache__": true, "coverage": true,
+}
+
+// generatedFiles are lock files and other files not worth reviewing
+var generatedFiles = map[string]bool{
+	"package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+	"go.sum": true, "poetry.lock": true, "Cargo.lock": true, "composer.lock": true,
+}
+
+// manifestFiles describe dependencies and builds
+var manifestFiles = map[string]bool{
+	"package.json": true, "go.mod": true, "requirements.txt": true, "pyproject.toml": true,
+	"Cargo.toml": true, "pom.xml": true, "build.gradle": true, "Dockerfile": true,
+}
+
+// entryNames are file names, without extension, of likely entry points
+var entryNames = map[string]bool{
+	"main": true, "index": true, "app": true, "App": true, "server": true,
+}
+
+// fileWeights gives the importance of a file by extension
+var fileWeights = map[string]float64{
+	".go": 3, ".ts": 3, ".tsx": 3, ".js": 3, ".jsx": 3, ".mjs": 3, ".cjs": 3,
+	".py": 3, ".rb": 3, ".java": 3, ".kt": 3, ".rs": 3, ".c": 3, ".h": 3,
+	".cpp": 3, ".cs": 3, ".php": 3, ".swift": 3, ".vue": 3, ".svelte": 3,
+	".sql": 2, ".prisma": 2, ".sh": 2, ".html": 2, ".css": 1.5, ".scss": 1.5,
+	".yaml": 1.5, ".yml": 1.5, ".toml": 1.5, ".json": 1, ".md": 1,
+}
+
+// IndexRepository walks a project, skipping what .gitignore ignores, and ranks
+// its files by importance, size and churn
+func IndexRepository(root string, opts IndexOptions) (*RepositoryIndex, error) {
+	patterns, err := gitignore.ReadPatterns(osfs.New(root), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .gitignore: %w", err)
+	}
+	matcher := gitignore.NewMatcher(patterns)
+
+	// Churn is optional: a project outside git ranks by importance and size only
+	churn, err := git.FileChurn(root, churnCommits)
+	if err != nil {
+		churn = nil
+	}
+
+	index := &RepositoryIndex{Root: root}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") || skippedDirs[d.Name()] || matcher.Match(parts, true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || matcher.Match(parts, false) {
+			return nil
+		}
+		if isSecretFile(d.Name()) {
+			index.SecretFiles = append(index.SecretFiles, filepath.ToSlash(rel))
+			return nil
+		}
+
+		weight := fileImportance(rel)
+		if weight == 0 {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil || info.Size() == 0 {
+			return nil
+		}
+		if info.Size() > opts.MaxFileBytes || generatedFiles[d.Name()] || isGenerated(d.Name()) || isBinary(path) {
+			index.Ignored++
+			return nil
+		}
+
+		file := RepositoryFile{
+			Path:  filepath.ToSlash(rel),
+			Size:  info.Size(),
+			Churn: churn[filepath.ToSlash(rel)],
+		}
+		file.Score = weight * (1 + math.Log2(1+float64(file.Churn))) / (1 + float64(file.Size)/16384)
+		index.Files = append(index.Files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to index repository: %w", err)
+	}
+
+	sort.SliceStable(index.Files, func(i, j int) bool {
+		if index.Files[i].Score != index.Files[j].Score {
+			return index.Files[i].Score > index.Files[j].Score
+		}
+		return index.Files[i].Path < index.Files[j].Path
+	})
+	return index, nil
+}
+
+// Chunks packs the files into at most opts.MaxChunks chunks, most important
+// files first. Files longer than a chunk are truncated. It also returns the
+// number of files left out.
+func (ri *RepositoryIndex) Chunks(opts IndexOptions) ([]Chunk, int) {
+	var chunks []Chunk
+	omitted := 0
+
+	for _, file := range ri.Files {
+		tokens := file.tokens(opts.ChunkTokens)
+
+		placed := false
+		for i := range chunks {
+			if chunks[i].Tokens+tokens <= opts.ChunkTokens {
+				chunks[i].Files = append(chunks[i].Files, file)
+				chunks[i].Tokens += tokens
+				placed = true
+				break
+			}
+		}
+		if placed {
+			continue
+		}
+
+		if len(chunks) < opts.MaxChunks {
+			chunks = append(chunks, Chunk{Files: []RepositoryFile{file}, Tokens: tokens})
+		} else {
+			omitted++
+		}
+	}
+
+	// Files of the same directory read better together
+	for i := range chunks {
+		sort.Slice(chunks[i].Files, func(a, b int) bool {
+			return chunks[i].Files[a].Path < chunks[i].Files[b].Path
+		})
+	}
+	return chunks, omitted
+}
+
+// fileImportance weighs a file by its role; zero means it is not indexed
+func fileImportance(rel string) float64 {
+	name := filepath.Base(rel)
+	if manifestFiles[name] {
+		return 2.5
+	}
+	if strings.EqualFold(name, "README.md") {
+		return 2
+	}
+
+	ext := filepath.Ext(name)
+	weight := fileWeights[ext]
+	if weight == 0 {
+		return 0
+	}
+
+	lower := strings.ToLower(filepath.ToSlash(rel))
+	switch {
+	case strings.Contains(lower, "_test.") || strings.Contains(lower, ".test.") ||
+		strings.Contains(lower, ".spec.") || strings.HasPrefix(lower, "test/") ||
+		strings.HasPrefix(lower, "tests/") || strings.Contains(lower, "/tests/"):
+		return weight / 2
+	case entryNames[strings.TrimSuffix(name, ext)] && weight >= 3:
+		return weight + 1
+	}
+	return weight
+}
+
+// isGenerated reports whether a file name marks generated or minified code
+func isGenerated(name string) bool {
+	return strings.HasSuffix(name, ".min.js") || strings.HasSuffix(name, ".min.css") ||
+		strings.HasSuffix(name, ".pb.go") || strings.Contains(name, ".generated.")
+}
+
+// isSecretFile reports whether a file holds environment variables or keys
+func isSecretFile(name string) bool {
+	return name == ".env" || strings.HasPrefix(name, ".env.") ||
+		strings.HasSuffix(name, ".pem") || strings.HasSuffix(name, ".key")
+}
+
+// isBinary reports whether a file holds a NUL byte near its start
+func isBinary(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return true
+	}
+	defer file.Close()
+
+	head := make([]byte, 8000)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return true
+	}
+	return bytes.IndexByte(head[:n], 0) != -1
+}
+
+// readNumbered reads a file with line numbers, truncated to about maxTokens
+func readNumbered(path string, maxTokens int) (string, bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, err
+	}
+
+	truncated := false
+	if limit := maxTokens * bytesPerToken; len(content) > limit {
+		content = content[:limit]
+		truncated = true
+	}
+
+	var b strings.Builder
+	for i, line := range strings.Split(strings.TrimRight(string(content), "\n"), "\n") {
+		fmt.Fprintf(&b, "%5d| %s\n", i+1, line)
+	}
+	return b.String(), truncated, nil
+}
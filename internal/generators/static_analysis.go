@@ -0,0 +1,542 @@
+package generators
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SourceStatic marks issues found by local checks
+	SourceStatic = "static"
+	// SourceClaude marks issues reported by Claude
+	SourceClaude = "claude"
+)
+
+// staticToolTimeout limits each external checker
+const staticToolTimeout = 2 * time.Minute
+
+// maxPromptFindings limits the static findings listed in one prompt
+const maxPromptFindings = 40
+
+// StaticResult holds the findings of the local checks run before Claude reviews a project
+type StaticResult struct {
+	Issues       []Issue
+	Tools        []string       // Checks that ran
+	Dependencies []string       // Dependencies declared in the manifests
+	Markers      map[string]int // TODO and FIXME markers per file
+}
+
+var (
+	// goDiagnosticPattern matches "file.go:line:col: message" lines of go vet and staticcheck
+	goDiagnosticPattern = regexp.MustCompile(`^(.+?\.go):(\d+)(?::\d+)?: (.+)$`)
+	// staticcheckCodePattern matches the check code at the end of a staticcheck message
+	staticcheckCodePattern = regexp.MustCompile(`\s\(([A-Z]+\d+)\)$`)
+	// tscDiagnosticPattern matches "file.ts(line,col): error TS1234: message" lines
+	tscDiagnosticPattern = regexp.MustCompile(`^(.+?)\((\d+),\d+\): error (TS\d+): (.+)$`)
+	// markerPattern matches TODO and FIXME comments
+	markerPattern = regexp.MustCompile(`\b(TODO|FIXME)\b`)
+)
+
+// secretPattern matches one kind of credential
+type secretPattern struct {
+	name     string
+	pattern  *regexp.Regexp
+	severity string
+}
+
+// secretPatterns match credentials committed to source files
+var secretPatterns = []secretPattern{
+	{"private key", regexp.MustCompile(`-----BEGIN (?:RSA |EC |OPENSSH |DSA |PGP )?PRIVATE KEY(?: BLOCK)?-----`), "critical"},
+	{"AWS access key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), "critical"},
+	{"GitHub token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`), "critical"},
+	{"Slack token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`), "high"},
+	{"Stripe secret key", regexp.MustCompile(`\bsk_live_[A-Za-z0-9]{20,}\b`), "critical"},
+	{"hard-coded credential", regexp.MustCompile(`(?i)\b(?:password|passwd|secret|api[_-]?key|access[_-]?token|auth[_-]?token)\b["']?\s*(?::=|[:=])\s*["']([^"'\s]{8,})["']`), "high"},
+}
+
+// envSecretPattern matches credential assignments of .env files, whose values
+// need no quotes
+var envSecretPattern = secretPattern{
+	"credential in environment file",
+	regexp.MustCompile(`(?i)^(?:export\s+)?\w*(?:password|passwd|secret|api_?key|token)\w*\s*=\s*["']?([^"'\s#]{8,})`),
+	"high",
+}
+
+// placeholderSecrets are values of credential assignments that are not real secrets
+var placeholderSecrets = regexp.MustCompile(`(?i)^(?:x+|\*+|changeme|change-me|your[_-].*|example.*|placeholder|dummy|test.*|<.*>|\$\{.*\}|%.*%)$`)
+
+// RunStaticChecks runs the cheap local checks of a project before Claude
+// reviews it. Linters check the whole project but only their findings in
+// files are kept; secrets, markers and manifests are scanned in files only.
+// Secret files, such as .env and key files, are scanned for secrets alone.
+func (pa *ProjectAnalyzer) RunStaticChecks(ctx context.Context, root string, files []RepositoryFile, secretFiles []string) *StaticResult {
+	result := &StaticResult{Markers: make(map[string]int)}
+
+	selected := make(map[string]bool, len(files))
+	for _, file := range files {
+		selected[file.Path] = true
+	}
+
+	type checker struct {
+		name string
+		run  func(context.Context, string) ([]Issue, error)
+	}
+	var checkers []checker
+	if fileExists(root, "go.mod") && hasTool("go") {
+		checkers = append(checkers, checker{"go vet", runGoVet})
+	}
+	if fileExists(root, "go.mod") && hasTool("staticcheck") {
+		checkers = append(checkers, checker{"staticcheck", runStaticcheck})
+	}
+	if fileExists(root, "package.json") && nodeTool(root, "eslint") != "" {
+		checkers = append(checkers, checker{"eslint", runESLint})
+	}
+	if fileExists(root, "tsconfig.json") && nodeTool(root, "tsc") != "" {
+		checkers = append(checkers, checker{"tsc", runTSC})
+	}
+
+	for _, c := range checkers {
+		issues, err := c.run(ctx, root)
+		if err != nil {
+			pa.logger.Warn().Err(err).Str("check", c.name).Msg("Static check failed")
+			continue
+		}
+		result.Tools = append(result.Tools, c.name)
+		for _, issue := range issues {
+			if selected[issue.File] {
+				result.Issues = append(result.Issues, issue)
+			}
+		}
+	}
+
+	// Scans of the selected files
+	result.Tools = append(result.Tools, "secrets", "markers", "manifests")
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(root, file.Path))
+		if err != nil {
+			continue
+		}
+		result.Issues = append(result.Issues, scanSecrets(file.Path, content)...)
+		if issue, count := scanMarkers(file.Path, content); count > 0 {
+			result.Markers[file.Path] = count
+			result.Issues = append(result.Issues, issue)
+		}
+		if manifestFiles[filepath.Base(file.Path)] {
+			dependencies, issues := scanManifest(file.Path, content)
+			result.Dependencies = append(result.Dependencies, dependencies...)
+			result.Issues = append(result.Issues, issues...)
+		}
+	}
+	for _, file := range secretFiles {
+		content, err := os.ReadFile(filepath.Join(root, file))
+		if err != nil {
+			continue
+		}
+		result.Issues = append(result.Issues, scanSecrets(file, content)...)
+	}
+
+	for i := range result.Issues {
+		result.Issues[i].Source = SourceStatic
+	}
+	result.Issues = DedupeIssues(result.Issues)
+	sort.Strings(result.Dependencies)
+
+	pa.logger.Info().
+		Strs("checks", result.Tools).
+		Int("issues", len(result.Issues)).
+		Msg("Static checks finished")
+
+	return result
+}
+
+// findingsFor lists the static findings in the given files for a prompt
+func (sr *StaticResult) findingsFor(files []RepositoryFile) string {
+	if sr == nil {
+		return ""
+	}
+	inChunk := make(map[string]bool, len(files))
+	for _, file := range files {
+		inChunk[file.Path] = true
+	}
+
+	var b strings.Builder
+	listed := 0
+	for _, issue := range sr.Issues {
+		if !inChunk[issue.File] {
+			continue
+		}
+		if listed == maxPromptFindings {
+			b.WriteString("- ... (이하 생략)\n")
+			break
+		}
+		fmt.Fprintf(&b, "- %s [%s/%s, rule_id: %s] %s\n", issue.Location(), issue.Type, issue.Severity, issue.RuleID, issue.Description)
+		listed++
+	}
+	return b.String()
+}
+
+// runGoVet runs go vet over the module
+func runGoVet(ctx context.Context, root string) ([]Issue, error) {
+	output, err := runTool(ctx, root, "go", "vet", "./...")
+	issues := parseGoDiagnostics(root, output, func(message string) (string, string, string) {
+		return "go-vet", "bug", "medium"
+	})
+	// go vet exits non-zero when it reports problems
+	if err != nil && len(issues) == 0 && ctx.Err() == nil && !isExitError(err) {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// runStaticcheck runs staticcheck over the module
+func runStaticcheck(ctx context.Context, root string) ([]Issue, error) {
+	output, err := runTool(ctx, root, "staticcheck", "./...")
+	issues := parseGoDiagnostics(root, output, func(message string) (string, string, string) {
+		code := ""
+		if match := staticcheckCodePattern.FindStringSubmatch(message); match != nil {
+			code = match[1]
+		}
+		// SA checks find bugs; S, ST, QF and U checks are style and simplifications
+		if strings.HasPrefix(code, "SA") {
+			return "staticcheck/" + strings.ToLower(code), "bug", "medium"
+		}
+		return "staticcheck/" + strings.ToLower(code), "quality", "low"
+	})
+	if err != nil && len(issues) == 0 && !isExitError(err) {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// parseGoDiagnostics parses "file.go:line:col: message" lines, classifying each message
+func parseGoDiagnostics(root, output string, classify func(message string) (rule, issueType, severity string)) []Issue {
+	var issues []Issue
+	for _, line := range strings.Split(output, "\n") {
+		match := goDiagnosticPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		lineNo, _ := strconv.Atoi(match[2])
+		rule, issueType, severity := classify(match[3])
+		issues = append(issues, Issue{
+			Type:        issueType,
+			Severity:    severity,
+			File:        relativeTo(root, match[1]),
+			Line:        lineNo,
+			EndLine:     lineNo,
+			RuleID:      rule,
+			Description: match[3],
+			Confidence:  0.9,
+		})
+	}
+	return issues
+}
+
+// runESLint runs eslint with JSON output
+func runESLint(ctx context.Context, root string) ([]Issue, error) {
+	output, err := runTool(ctx, root, nodeTool(root, "eslint"), "--format", "json", ".")
+
+	var results []struct {
+		FilePath string `json:"filePath"`
+		Messages []struct {
+			RuleID   string `json:"ruleId"`
+			Severity int    `json:"severity"`
+			Message  string `json:"message"`
+			Line     int    `json:"line"`
+			EndLine  int    `json:"endLine"`
+		} `json:"messages"`
+	}
+	// eslint exits non-zero when it reports problems; its output is JSON either way
+	start := strings.Index(output, "[")
+	if start == -1 {
+		if err != nil {
+			return nil, fmt.Errorf("eslint: %w: %s", err, truncate(output, 200))
+		}
+		return nil, nil
+	}
+	if jsonErr := json.Unmarshal([]byte(output[start:]), &results); jsonErr != nil {
+		return nil, fmt.Errorf("failed to parse eslint output: %w", jsonErr)
+	}
+
+	var issues []Issue
+	for _, result := range results {
+		for _, message := range result.Messages {
+			severity := "low"
+			if message.Severity == 2 {
+				severity = "medium"
+			}
+			rule := "eslint"
+			if message.RuleID != "" {
+				rule += "/" + strings.ToLower(message.RuleID)
+			}
+			issues = append(issues, Issue{
+				Type:        "quality",
+				Severity:    severity,
+				File:        relativeTo(root, result.FilePath),
+				Line:        message.Line,
+				EndLine:     max(message.EndLine, message.Line),
+				RuleID:      rule,
+				Description: message.Message,
+				Confidence:  0.9,
+			})
+		}
+	}
+	return issues, nil
+}
+
+// runTSC type-checks a TypeScript project without emitting files
+func runTSC(ctx context.Context, root string) ([]Issue, error) {
+	output, err := runTool(ctx, root, nodeTool(root, "tsc"), "--noEmit", "--pretty", "false")
+
+	var issues []Issue
+	for _, line := range strings.Split(output, "\n") {
+		match := tscDiagnosticPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		lineNo, _ := strconv.Atoi(match[2])
+		issues = append(issues, Issue{
+			Type:        "bug",
+			Severity:    "high",
+			File:        relativeTo(root, match[1]),
+			Line:        lineNo,
+			EndLine:     lineNo,
+			RuleID:      "tsc/" + strings.ToLower(match[3]),
+			Description: match[4],
+			Confidence:  0.95,
+		})
+	}
+	if err != nil && len(issues) == 0 && !isExitError(err) {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// scanSecrets reports credentials found in a file, without repeating them
+func scanSecrets(path string, content []byte) []Issue {
+	var issues []Issue
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	patterns := secretPatterns
+	if name := filepath.Base(path); name == ".env" || strings.HasPrefix(name, ".env.") {
+		patterns = append(patterns[:len(patterns):len(patterns)], envSecretPattern)
+	}
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		for _, secret := range patterns {
+			match := secret.pattern.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			confidence := 0.9
+			if len(match) > 1 && match[1] != "" {
+				// Generic assignments need a value that looks real
+				if placeholderSecrets.MatchString(match[1]) {
+					continue
+				}
+				confidence = 0.6
+			}
+			issues = append(issues, Issue{
+				Type:        "security",
+				Severity:    secret.severity,
+				File:        path,
+				Line:        lineNo,
+				EndLine:     lineNo,
+				RuleID:      "hardcoded-secret",
+				Description: fmt.Sprintf("Possible %s committed in source", secret.name),
+				Suggestion:  "Move the value to an environment variable or secret store and rotate it",
+				Confidence:  confidence,
+			})
+			break
+		}
+	}
+	return issues
+}
+
+// scanMarkers counts the TODO and FIXME markers of a file, returning an issue at the first
+func scanMarkers(path string, content []byte) (Issue, int) {
+	count, first := 0, 0
+	for i, line := range strings.Split(string(content), "\n") {
+		if markerPattern.MatchString(line) {
+			if count == 0 {
+				first = i + 1
+			}
+			count++
+		}
+	}
+
+	return Issue{
+		Type:        "quality",
+		Severity:    "low",
+		File:        path,
+		Line:        first,
+		EndLine:     first,
+		RuleID:      "todo-comment",
+		Description: fmt.Sprintf("%d TODO/FIXME marker(s) left in the file", count),
+		Confidence:  1,
+	}, count
+}
+
+// scanManifest lists the dependencies of a manifest and reports unpinned ones
+func scanManifest(path string, content []byte) ([]string, []Issue) {
+	var dependencies []string
+	var issues []Issue
+
+	unpinned := func(name string, line int) {
+		issues = append(issues, Issue{
+			Type:        "quality",
+			Severity:    "low",
+			File:        path,
+			Line:        line,
+			EndLine:     line,
+			RuleID:      "unpinned-dependency",
+			Description: fmt.Sprintf("Dependency %s has no pinned version", name),
+			Suggestion:  "Pin the dependency to a version range so builds are reproducible",
+			Confidence:  0.8,
+		})
+	}
+	lines := strings.Split(string(content), "\n")
+	lineOf := func(needle string) int {
+		for i, line := range lines {
+			if strings.Contains(line, needle) {
+				return i + 1
+			}
+		}
+		return 0
+	}
+
+	switch filepath.Base(path) {
+	case "package.json":
+		var pkg struct {
+			Dependencies    map[string]string `json:"dependencies"`
+			DevDependencies map[string]string `json:"devDependencies"`
+		}
+		if json.Unmarshal(content, &pkg) != nil {
+			return nil, nil
+		}
+		for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies} {
+			for name, version := range deps {
+				dependencies = append(dependencies, name)
+				if version == "" || version == "*" || version == "latest" {
+					unpinned(name, lineOf(`"`+name+`"`))
+				}
+			}
+		}
+
+	case "go.mod":
+		inRequire := false
+		for _, line := range lines {
+			line = strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(line, "require ("):
+				inRequire = true
+			case inRequire && line == ")":
+				inRequire = false
+			case inRequire && line != "" && !strings.HasPrefix(line, "//"):
+				dependencies = append(dependencies, strings.Fields(line)[0])
+			case strings.HasPrefix(line, "require "):
+				if fields := strings.Fields(line); len(fields) > 1 {
+					dependencies = append(dependencies, fields[1])
+				}
+			}
+		}
+
+	case "requirements.txt":
+		for i, line := range lines {
+			line = strings.TrimSpace(strings.SplitN(line, "#", 2)[0])
+			if line == "" || strings.HasPrefix(line, "-") {
+				continue
+			}
+			fields := strings.FieldsFunc(line, func(r rune) bool {
+				return strings.ContainsRune("=<>!~;[ ", r)
+			})
+			if len(fields) == 0 {
+				continue
+			}
+			name := fields[0]
+			dependencies = append(dependencies, name)
+			if !strings.ContainsAny(line, "=<>~") {
+				unpinned(name, i+1)
+			}
+		}
+	}
+
+	sort.Strings(dependencies)
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+	return dependencies, issues
+}
+
+// runTool runs a checker in the project directory, returning its combined output
+func runTool(ctx context.Context, dir, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, staticToolTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CI=true")
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return output.String(), fmt.Errorf("%s timed out", name)
+	}
+	return output.String(), err
+}
+
+// isExitError reports whether a checker ran and exited with a non-zero status
+func isExitError(err error) bool {
+	_, ok := err.(*exec.ExitError)
+	return ok
+}
+
+// nodeTool returns the project-local or global path of a Node.js tool, or ""
+func nodeTool(root, name string) string {
+	local := filepath.Join(root, "node_modules", ".bin", name)
+	if _, err := os.Stat(local); err == nil {
+		return local
+	}
+	if path, err := exec.LookPath(name); err == nil {
+		return path
+	}
+	return ""
+}
+
+// hasTool reports whether a command is on PATH
+func hasTool(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// fileExists reports whether a file exists below root
+func fileExists(root, name string) bool {
+	_, err := os.Stat(filepath.Join(root, name))
+	return err == nil
+}
+
+// relativeTo returns path relative to root, slash-separated
+func relativeTo(root, path string) string {
+	if filepath.IsAbs(path) {
+		if absRoot, err := filepath.Abs(root); err == nil {
+			if rel, err := filepath.Rel(absRoot, path); err == nil {
+				path = rel
+			}
+		}
+	}
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "./")
+}
@@ -0,0 +1,236 @@
+package generators
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nohdol/claude-auto/internal/core"
+	"github.com/nohdol/claude-auto/internal/detect"
+	"github.com/nohdol/claude-auto/pkg/types"
+)
+
+// Packages returns a ProjectInfo for each workspace package of a project, or
+// nil when the project is not a monorepo. Packages are detected, not analyzed.
+func (pa *ProjectAnalyzer) Packages(projectPath string) []*ProjectInfo {
+	return packageInfos(projectPath, detect.Detect(projectPath))
+}
+
+// FindPackage returns the workspace package matching name by its manifest
+// name, its path or the last element of its path
+func (pa *ProjectAnalyzer) FindPackage(projectPath, name string) (*ProjectInfo, error) {
+	return findPackage(projectPath, name)
+}
+
+// findPackage returns the workspace package of a project matching name
+func findPackage(projectPath, name string) (*ProjectInfo, error) {
+	packages := packageInfos(projectPath, detect.Detect(projectPath))
+	if len(packages) == 0 {
+		return nil, fmt.Errorf("%s has no workspace packages", projectPath)
+	}
+
+	name = strings.TrimSuffix(filepath.ToSlash(filepath.Clean(name)), "/")
+	var matches []*ProjectInfo
+	for _, pkg := range packages {
+		if pkg.Package == name || pkg.Dir == name {
+			return pkg, nil
+		}
+		if filepath.Base(pkg.Dir) == name {
+			matches = append(matches, pkg)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+
+	var names []string
+	for _, pkg := range packages {
+		names = append(names, fmt.Sprintf("%s (%s)", pkg.Package, pkg.Dir))
+	}
+	if len(matches) > 1 {
+		return nil, fmt.Errorf("package %q is ambiguous, use its path: %s", name, strings.Join(names, ", "))
+	}
+	return nil, fmt.Errorf("no package %q in %s: %s", name, projectPath, strings.Join(names, ", "))
+}
+
+// packageInfos describes the workspace packages of a detection
+func packageInfos(projectPath string, detection *detect.Detection) []*ProjectInfo {
+	var packages []*ProjectInfo
+	for _, pkg := range detection.Packages {
+		projectType, language, framework := detection.PrimaryIn(pkg.Path)
+		packages = append(packages, &ProjectInfo{
+			Path:       filepath.Join(projectPath, filepath.FromSlash(pkg.Path)),
+			Package:    pkg.Name,
+			Dir:        pkg.Path,
+			Type:       projectType,
+			Language:   language,
+			Framework:  framework,
+			Components: detection.In(pkg.Path),
+		})
+	}
+	return packages
+}
+
+// assignIssues adds each issue to the package containing its file, with the
+// file made relative to the package
+func assignIssues(issues []Issue, packages []*ProjectInfo) {
+	// Nested packages take their own files
+	sorted := append([]*ProjectInfo(nil), packages...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i].Dir) > len(sorted[j].Dir) })
+
+	for _, issue := range issues {
+		for _, pkg := range sorted {
+			if rel, ok := strings.CutPrefix(issue.File, pkg.Dir+"/"); ok {
+				issue.File = rel
+				pkg.Issues = append(pkg.Issues, issue)
+				break
+			}
+		}
+	}
+}
+
+// packageByDir returns the package in dir, or nil
+func packageByDir(packages []*ProjectInfo, dir string) *ProjectInfo {
+	dir = strings.TrimSuffix(filepath.ToSlash(filepath.Clean(dir)), "/")
+	for _, pkg := range packages {
+		if pkg.Dir == dir || pkg.Package == dir {
+			return pkg
+		}
+	}
+	return nil
+}
+
+// workspacePlan is a feature plan of a monorepo, assigning the work to packages
+type workspacePlan struct {
+	Summary  string        `json:"summary"`
+	Packages []packagePlan `json:"packages"`
+}
+
+// packagePlan is the work of a feature in one package
+type packagePlan struct {
+	Package string `json:"package"` // package path
+	Type    string `json:"type"`    // api, ui, fullstack, generic
+	Changes string `json:"changes"`
+}
+
+// featureTypeOrder runs the packages providing APIs before their clients
+var featureTypeOrder = map[string]int{"api": 0, "fullstack": 1, "generic": 2, "ui": 3}
+
+// createWorkspaceTasks plans a feature across the packages of a monorepo and
+// creates the tasks of each package, run in its directory
+func (fg *FeatureGenerator) createWorkspaceTasks(ctx context.Context, request *FeatureRequest, projectInfo *ProjectInfo) ([]*types.Task, error) {
+	plan, err := fg.generateWorkspacePlan(ctx, request, projectInfo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate feature plan: %w", err)
+	}
+
+	sort.SliceStable(plan.Packages, func(i, j int) bool {
+		return featureTypeOrder[plan.Packages[i].Type] < featureTypeOrder[plan.Packages[j].Type]
+	})
+
+	var tasks []*types.Task
+	for _, entry := range plan.Packages {
+		pkg := packageByDir(projectInfo.Packages, entry.Package)
+
+		packageRequest := *request
+		packageRequest.Package = pkg.Dir
+		packageRequest.Type = entry.Type
+
+		// Each package sees the work of the others so APIs and clients agree
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s\n\n이 패키지(%s)에서 할 작업:\n%s\n", plan.Summary, pkg.Dir, entry.Changes)
+		for _, other := range plan.Packages {
+			if other.Package != entry.Package {
+				fmt.Fprintf(&b, "\n다른 패키지(%s)에서 할 작업:\n%s\n", other.Package, other.Changes)
+			}
+		}
+
+		for _, task := range fg.createFeatureTasks(&packageRequest, pkg, b.String()) {
+			task.WorkDir = pkg.Dir
+			task.Prompt = fmt.Sprintf("작업 디렉토리: 모노레포의 %s 패키지 (%s)\n다른 패키지는 수정하지 마세요.\n\n%s", pkg.Package, pkg.Dir, task.Prompt)
+			tasks = append(tasks, task)
+		}
+	}
+
+	fg.logger.Info().
+		Int("packages", len(plan.Packages)).
+		Int("tasks", len(tasks)).
+		Msg("Feature planned across packages")
+
+	return tasks, nil
+}
+
+// generateWorkspacePlan asks Claude which packages a feature changes and how,
+// dropping entries for unknown packages
+func (fg *FeatureGenerator) generateWorkspacePlan(ctx context.Context, request *FeatureRequest, projectInfo *ProjectInfo) (*workspacePlan, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, `모노레포에 새로운 기능을 추가하려고 합니다.
+
+추가할 기능:
+- 이름: %s
+- 설명: %s
+
+워크스페이스 패키지:
+`, request.Name, request.Description)
+	for _, pkg := range projectInfo.Packages {
+		fmt.Fprintf(&b, "- %s (경로: %s, 타입: %s, 언어: %s, 프레임워크: %s)\n", pkg.Package, pkg.Dir, pkg.Type, pkg.Language, pkg.Framework)
+	}
+	b.WriteString(`
+기능에 필요한 변경을 패키지별로 나누어 계획해주세요. 예를 들어 API 변경은 API 패키지에,
+이를 호출하는 클라이언트 변경은 웹 패키지에 배정합니다. 변경이 필요 없는 패키지는 넣지 마세요.
+
+다른 설명 없이 다음 형식의 JSON만 응답해주세요:
+{
+  "summary": "전체 구현 계획 (API 계약, 데이터 모델, 통합 포인트 포함)",
+  "packages": [
+    {
+      "package": "패키지 경로",
+      "type": "api | ui | fullstack | generic",
+      "changes": "이 패키지에서 할 작업"
+    }
+  ]
+}`)
+
+	response, err := fg.claudeExecutor.Execute(ctx, b.String(), &core.ClaudeOptions{
+		Role:         "software-architect",
+		SystemPrompt: "You are an expert software architect who specializes in adding features to monorepos.",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var plan workspacePlan
+	jsonStr, err := extractJSON(response.Output, func(candidate string) error {
+		plan = workspacePlan{}
+		return json.Unmarshal([]byte(candidate), &plan)
+	})
+	if jsonStr == "" && err == nil {
+		err = fmt.Errorf("no JSON found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid plan: %w: %s", err, truncate(response.Output, 200))
+	}
+
+	valid := plan.Packages[:0]
+	for _, entry := range plan.Packages {
+		pkg := packageByDir(projectInfo.Packages, entry.Package)
+		if pkg == nil {
+			fg.logger.Warn().Str("package", entry.Package).Msg("Plan names an unknown package, skipping")
+			continue
+		}
+		entry.Package = pkg.Dir
+		entry.Type = strings.ToLower(strings.TrimSpace(entry.Type))
+		if _, ok := featureTypeOrder[entry.Type]; !ok {
+			entry.Type = fg.determineFeatureType(entry.Changes, pkg)
+		}
+		valid = append(valid, entry)
+	}
+	if len(valid) == 0 {
+		return nil, fmt.Errorf("plan assigns no work to a known package")
+	}
+	plan.Packages = valid
+	return &plan, nil
+}
@@ -0,0 +1,157 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DirtyMode selects what happens to uncommitted work before Claude edits a project
+type DirtyMode string
+
+const (
+	DirtyRefuse   DirtyMode = "refuse"   // Stop until the user commits or stashes
+	DirtyStash    DirtyMode = "stash"    // Move the work to the stash list
+	DirtySnapshot DirtyMode = "snapshot" // Commit the work on a side branch
+)
+
+// WIPBranchPrefix prefixes the side branches holding snapshots of uncommitted work
+const WIPBranchPrefix = "claude-auto/wip/"
+
+// maxListedFiles limits the files named when refusing a dirty tree
+const maxListedFiles = 5
+
+// ErrDirtyWorktree is returned when the working tree has uncommitted changes
+var ErrDirtyWorktree = errors.New("working tree has uncommitted changes")
+
+// ProtectedWork describes where uncommitted work was moved before a run
+type ProtectedWork struct {
+	Files []string
+	// Stash is the stash entry holding the work in stash mode
+	Stash string
+	// Branch is the side branch holding the work in snapshot mode
+	Branch string
+}
+
+// ParseDirtyMode validates a dirty-worktree mode
+func ParseDirtyMode(mode string) (DirtyMode, error) {
+	switch DirtyMode(mode) {
+	case DirtyRefuse, DirtyStash, DirtySnapshot:
+		return DirtyMode(mode), nil
+	}
+	return "", fmt.Errorf("invalid dirty mode %q (refuse, stash, snapshot)", mode)
+}
+
+// ProtectWorkingTree keeps uncommitted work out of the commits of a run. A clean
+// tree is left alone and nil is returned. A dirty tree is refused with
+// ErrDirtyWorktree, stashed, or committed on a side branch, and the tree is left clean.
+func (gm *GitManager) ProtectWorkingTree(mode DirtyMode, label string) (*ProtectedWork, error) {
+	// Run records must not count as the user's work
+	if err := gm.excludeStateDir(); err != nil {
+		return nil, err
+	}
+
+	status, err := gm.GetStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+	if !status.HasChanges {
+		return nil, nil
+	}
+
+	work := &ProtectedWork{Files: status.Files()}
+
+	switch mode {
+	case DirtyStash:
+		if err := gm.EnsureInitialCommit(); err != nil {
+			return nil, err
+		}
+		message := fmt.Sprintf("claude-auto: before %s", label)
+		if _, err := gm.runGit("stash", "push", "--include-untracked", "-m", message); err != nil {
+			return nil, fmt.Errorf("failed to stash changes: %w", err)
+		}
+		work.Stash = "stash@{0}"
+
+	case DirtySnapshot:
+		if err := gm.EnsureInitialCommit(); err != nil {
+			return nil, err
+		}
+		branch, err := gm.snapshotToBranch(label)
+		if err != nil {
+			return nil, err
+		}
+		work.Branch = branch
+		if err := gm.discardWorkingTree(); err != nil {
+			return nil, err
+		}
+
+	default:
+		files := work.Files
+		if len(files) > maxListedFiles {
+			files = append(files[:maxListedFiles:maxListedFiles], fmt.Sprintf("and %d more", len(work.Files)-maxListedFiles))
+		}
+		return work, fmt.Errorf("%w: %s", ErrDirtyWorktree, strings.Join(files, ", "))
+	}
+
+	gm.logger.Info().
+		Str("mode", string(mode)).
+		Int("files", len(work.Files)).
+		Msg("Uncommitted changes set aside")
+
+	return work, nil
+}
+
+// snapshotToBranch commits the whole working tree, untracked files included,
+// on a new side branch from HEAD without touching the index or current branch
+func (gm *GitManager) snapshotToBranch(label string) (string, error) {
+	index, err := os.CreateTemp("", "claude-auto-index-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	index.Close()
+	defer os.Remove(index.Name())
+
+	// A separate index keeps the user's staging area as it was
+	env := []string{"GIT_INDEX_FILE=" + index.Name()}
+	if _, err := gm.runGitEnv(env, "read-tree", "HEAD"); err != nil {
+		return "", fmt.Errorf("failed to read HEAD tree: %w", err)
+	}
+	if _, err := gm.runGitEnv(env, "add", "--all"); err != nil {
+		return "", fmt.Errorf("failed to stage snapshot: %w", err)
+	}
+	tree, err := gm.runGitEnv(env, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to write snapshot tree: %w", err)
+	}
+
+	message := fmt.Sprintf("chore: snapshot uncommitted work before %s", label)
+	commit, err := gm.runGit("commit-tree", strings.TrimSpace(tree), "-p", "HEAD", "-m", message)
+	if err != nil {
+		return "", fmt.Errorf("failed to commit snapshot: %w", err)
+	}
+
+	base := WIPBranchPrefix + time.Now().Format("20060102-150405")
+	branch := base
+	for i := 2; gm.HasBranch(branch); i++ {
+		branch = fmt.Sprintf("%s-%d", base, i)
+	}
+	if _, err := gm.runGit("branch", branch, strings.TrimSpace(commit)); err != nil {
+		return "", fmt.Errorf("failed to create snapshot branch: %w", err)
+	}
+
+	return branch, nil
+}
+
+// Files returns every changed file, sorted
+func (s *GitStatus) Files() []string {
+	var files []string
+	files = append(files, s.Modified...)
+	files = append(files, s.Added...)
+	files = append(files, s.Deleted...)
+	files = append(files, s.Untracked...)
+	sort.Strings(files)
+	return files
+}
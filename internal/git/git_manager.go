@@ -3,9 +3,11 @@ package git
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -22,6 +24,18 @@ type GitManager struct {
 	author     *object.Signature
 	logger     zerolog.Logger
 	projectDir string
+	push       PushConfig
+	// messageWriter writes commit messages; template messages are used when nil
+	messageWriter MessageWriter
+	// committer is the human running the tool; the author is used when nil
+	committer *object.Signature
+	// signKey signs commits made with go-git, signer any other commit
+	signKey *openpgp.Entity
+	signer  signer
+	// trailers are the enabled trailer keys; DefaultTrailers when nil
+	trailers map[string]bool
+	runID    string
+	model    string
 }
 
 // NewGitManager creates a new Git manager
@@ -75,21 +89,30 @@ func (gm *GitManager) InitRepo() error {
 	return nil
 }
 
+// Trailer represents a git trailer appended to a commit message
+type Trailer struct {
+	Key   string
+	Value string
+}
+
 // SmartCommit creates commits based on task type and changes
 func (gm *GitManager) SmartCommit(files []string, taskType types.TaskType) error {
+	return gm.CommitWithTrailers(files, taskType, "")
+}
+
+// CommitWithTrailers creates a commit of the given files whose message ends with trailers.
+// An empty message is generated from the task type and changes.
+func (gm *GitManager) CommitWithTrailers(files []string, taskType types.TaskType, message string, trailers ...Trailer) error {
 	if len(files) == 0 {
 		return nil // Nothing to commit
 	}
 
-	// Analyze changes
+	// Analyze changes before staging hides untracked files
 	changes := gm.analyzeChanges(files)
 
-	// Generate commit message
-	message := gm.generateCommitMessage(taskType, changes)
-
 	// Stage files
 	for _, file := range files {
-		relPath, err := filepath.Rel(gm.projectDir, file)
+		relPath, err := gm.relativePath(file)
 		if err != nil {
 			gm.logger.Warn().
 				Str("file", file).
@@ -106,10 +129,19 @@ func (gm *GitManager) SmartCommit(files []string, taskType types.TaskType) error
 		}
 	}
 
+	if !gm.hasStagedChanges() {
+		gm.logger.Debug().Strs("files", files).Msg("No staged changes, skipping commit")
+		return nil
+	}
+
+	// Generate commit message
+	if message == "" {
+		message = gm.commitMessage(taskType, changes)
+	}
+	message = appendTrailers(message, gm.commitTrailers(trailers))
+
 	// Create commit
-	commit, err := gm.worktree.Commit(message, &git.CommitOptions{
-		Author: gm.author,
-	})
+	commit, err := gm.commit(message)
 	if err != nil {
 		return fmt.Errorf("failed to create commit: %w", err)
 	}
@@ -119,9 +151,69 @@ func (gm *GitManager) SmartCommit(files []string, taskType types.TaskType) error
 		Str("message", message).
 		Msg("Commit created")
 
+	gm.pushAfterCommit()
 	return nil
 }
 
+// hasStagedChanges checks if the index differs from HEAD
+func (gm *GitManager) hasStagedChanges() bool {
+	status, err := gm.worktree.Status()
+	if err != nil {
+		return true // Let the commit report the problem
+	}
+
+	for _, fileStatus := range status {
+		if fileStatus.Staging != git.Unmodified && fileStatus.Staging != git.Untracked {
+			return true
+		}
+	}
+	return false
+}
+
+// commit commits the index as the author and committer, signed when signing is on
+func (gm *GitManager) commit(message string) (plumbing.Hash, error) {
+	now := time.Now()
+	hash, err := gm.worktree.Commit(message, &git.CommitOptions{
+		Author:    withTime(gm.author, now),
+		Committer: withTime(gm.committerSignature(), now),
+		SignKey:   gm.signKey,
+	})
+	if err != nil {
+		return hash, err
+	}
+
+	// go-git signs with OpenPGP keys only
+	if gm.signKey == nil && gm.signer != nil {
+		if err := gm.signHead(); err != nil {
+			return hash, err
+		}
+		head, err := gm.repo.Head()
+		if err != nil {
+			return hash, err
+		}
+		hash = head.Hash()
+	}
+
+	return hash, nil
+}
+
+// relativePath converts a file path to a path relative to the project directory.
+// Relative paths are taken to be relative to the project directory already.
+func (gm *GitManager) relativePath(file string) (string, error) {
+	if !filepath.IsAbs(file) {
+		return filepath.ToSlash(filepath.Clean(file)), nil
+	}
+	projectDir, err := filepath.Abs(gm.projectDir)
+	if err != nil {
+		return "", err
+	}
+	relPath, err := filepath.Rel(projectDir, file)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(relPath), nil
+}
+
 // AtomicCommit creates small, atomic commits for each file
 func (gm *GitManager) AtomicCommit(file string, taskType types.TaskType) error {
 	return gm.SmartCommit([]string{file}, taskType)
@@ -135,7 +227,7 @@ func (gm *GitManager) BatchCommit(files []string, taskType types.TaskType, messa
 
 	// Stage all files
 	for _, file := range files {
-		relPath, err := filepath.Rel(gm.projectDir, file)
+		relPath, err := gm.relativePath(file)
 		if err != nil {
 			continue
 		}
@@ -147,22 +239,24 @@ func (gm *GitManager) BatchCommit(files []string, taskType types.TaskType, messa
 		changes := gm.analyzeChanges(files)
 		message = gm.generateCommitMessage(taskType, changes)
 	}
+	message = appendTrailers(message, gm.commitTrailers(nil))
 
 	// Create commit
-	_, err := gm.worktree.Commit(message, &git.CommitOptions{
-		Author: gm.author,
-	})
+	if _, err := gm.commit(message); err != nil {
+		return err
+	}
 
-	return err
+	gm.pushAfterCommit()
+	return nil
 }
 
 // analyzeChanges analyzes the changes in files
 func (gm *GitManager) analyzeChanges(files []string) *ChangeAnalysis {
 	analysis := &ChangeAnalysis{
-		Files:      files,
-		AddedFiles: 0,
+		Files:         files,
+		AddedFiles:    0,
 		ModifiedFiles: 0,
-		DeletedFiles: 0,
+		DeletedFiles:  0,
 	}
 
 	status, err := gm.worktree.Status()
@@ -171,13 +265,17 @@ func (gm *GitManager) analyzeChanges(files []string) *ChangeAnalysis {
 	}
 
 	for _, file := range files {
-		relPath, err := filepath.Rel(gm.projectDir, file)
+		relPath, err := gm.relativePath(file)
 		if err != nil {
 			continue
 		}
 
 		fileStatus := status.File(relPath)
-		switch fileStatus.Staging {
+		code := fileStatus.Staging
+		if code == git.Unmodified {
+			code = fileStatus.Worktree // Not staged yet
+		}
+		switch code {
 		case git.Added, git.Untracked:
 			analysis.AddedFiles++
 		case git.Modified:
@@ -226,6 +324,10 @@ func (gm *GitManager) generateCommitMessage(taskType types.TaskType, changes *Ch
 		return fmt.Sprintf("%s: update documentation", prefix)
 	case types.TaskTypeDevOps:
 		return fmt.Sprintf("%s: %s", prefix, changes.Summary)
+	case types.TaskTypeFix:
+		return fmt.Sprintf("%s: %s", prefix, changes.Summary)
+	case types.TaskTypeRefactor:
+		return fmt.Sprintf("%s: %s", prefix, changes.Summary)
 	default:
 		return fmt.Sprintf("chore: %s", changes.Summary)
 	}
@@ -240,6 +342,8 @@ func (gm *GitManager) getCommitPrefix(taskType types.TaskType) string {
 		types.TaskTypeTesting:       "test",
 		types.TaskTypeDocumentation: "docs",
 		types.TaskTypeDevOps:        "ci",
+		types.TaskTypeFix:           "fix",
+		types.TaskTypeRefactor:      "refactor",
 	}
 
 	if prefix, exists := prefixes[taskType]; exists {
@@ -248,67 +352,134 @@ func (gm *GitManager) getCommitPrefix(taskType types.TaskType) string {
 	return "chore"
 }
 
-// Push pushes commits to remote repository
-func (gm *GitManager) Push(remote, branch string) error {
-	// Get remote
-	r, err := gm.repo.Remote(remote)
-	if err != nil {
-		return fmt.Errorf("failed to get remote: %w", err)
+// CreateBranch creates a branch starting at base, or at HEAD when base is empty,
+// and checks it out. Uncommitted changes are kept when branching from HEAD.
+func (gm *GitManager) CreateBranch(name, base string) error {
+	var hash plumbing.Hash
+	if base == "" {
+		head, err := gm.repo.Head()
+		if err != nil {
+			return fmt.Errorf("failed to get HEAD: %w", err)
+		}
+		hash = head.Hash()
+	} else {
+		resolved, err := gm.repo.ResolveRevision(plumbing.Revision(base))
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", base, err)
+		}
+		hash = *resolved
 	}
 
-	// Push
-	err = r.Push(&git.PushOptions{
-		RemoteName: remote,
-		RefSpecs: []config.RefSpec{
-			config.RefSpec(fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, branch)),
-		},
-	})
+	refName := plumbing.NewBranchReferenceName(name)
+	if _, err := gm.repo.Reference(refName, false); err == nil {
+		return fmt.Errorf("branch %s already exists", name)
+	}
 
-	if err != nil && err != git.NoErrAlreadyUpToDate {
-		return fmt.Errorf("failed to push: %w", err)
+	if err := gm.repo.Storer.SetReference(plumbing.NewHashReference(refName, hash)); err != nil {
+		return fmt.Errorf("failed to create branch: %w", err)
 	}
 
-	gm.logger.Info().
-		Str("remote", remote).
-		Str("branch", branch).
-		Msg("Pushed to remote")
+	err := gm.worktree.Checkout(&git.CheckoutOptions{
+		Branch: refName,
+		Keep:   base == "",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to checkout branch: %w", err)
+	}
 
+	gm.logger.Info().
+		Str("branch", name).
+		Str("base", hash.String()).
+		Msg("Branch created")
 	return nil
 }
 
-// CreateBranch creates a new branch
-func (gm *GitManager) CreateBranch(name string) error {
-	// Get HEAD reference
-	head, err := gm.repo.Head()
+// CheckoutBranch checks out an existing branch.
+// Uncommitted changes to tracked files make the checkout fail.
+func (gm *GitManager) CheckoutBranch(name string) error {
+	err := gm.worktree.Checkout(&git.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName(name),
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get HEAD: %w", err)
+		return fmt.Errorf("failed to checkout branch: %w", err)
 	}
 
-	// Create new branch
-	ref := fmt.Sprintf("refs/heads/%s", name)
+	gm.logger.Info().Str("branch", name).Msg("Branch checked out")
+	return nil
+}
 
-	err = gm.repo.CreateBranch(&config.Branch{
-		Name:   name,
-		Remote: "origin",
-		Merge:  plumbing.ReferenceName(ref),
-	})
+// DeleteBranch deletes a branch other than the current one
+func (gm *GitManager) DeleteBranch(name string) error {
+	current, err := gm.GetCurrentBranch()
+	if err == nil && current == name {
+		return fmt.Errorf("cannot delete the current branch %s", name)
+	}
 
+	refName := plumbing.NewBranchReferenceName(name)
+	if _, err := gm.repo.Reference(refName, false); err != nil {
+		return fmt.Errorf("branch %s not found: %w", name, err)
+	}
+
+	if err := gm.repo.Storer.RemoveReference(refName); err != nil {
+		return fmt.Errorf("failed to delete branch: %w", err)
+	}
+
+	// Drop the tracking configuration as well, if any
+	if err := gm.repo.DeleteBranch(name); err != nil && err != git.ErrBranchNotFound {
+		gm.logger.Warn().Err(err).Str("branch", name).Msg("Failed to delete branch configuration")
+	}
+
+	gm.logger.Info().Str("branch", name).Msg("Branch deleted")
+	return nil
+}
+
+// ListBranches returns the names of all local branches, sorted
+func (gm *GitManager) ListBranches() ([]string, error) {
+	iter, err := gm.repo.Branches()
 	if err != nil {
-		return fmt.Errorf("failed to create branch: %w", err)
+		return nil, fmt.Errorf("failed to list branches: %w", err)
 	}
+	defer iter.Close()
 
-	// Checkout new branch
-	err = gm.worktree.Checkout(&git.CheckoutOptions{
-		Branch: head.Name(),
-		Create: false,
+	var branches []string
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		branches = append(branches, ref.Name().Short())
+		return nil
 	})
-
 	if err != nil {
-		return fmt.Errorf("failed to checkout branch: %w", err)
+		return nil, fmt.Errorf("failed to list branches: %w", err)
 	}
 
-	gm.logger.Info().Str("branch", name).Msg("Branch created")
-	return nil
+	sort.Strings(branches)
+	return branches, nil
+}
+
+// FeatureBranchPrefix prefixes the branches idea and add runs work on
+const FeatureBranchPrefix = "claude-auto/"
+
+// FeatureBranch returns the branch name for a feature, e.g. claude-auto/user-auth
+func FeatureBranch(feature string) string {
+	var b strings.Builder
+	for _, ch := range strings.ToLower(feature) {
+		switch {
+		case (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9'):
+			b.WriteRune(ch)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteRune('-')
+		}
+	}
+
+	slug := strings.Trim(b.String(), "-")
+	if slug == "" {
+		slug = fmt.Sprintf("feature-%d", time.Now().Unix())
+	}
+	return FeatureBranchPrefix + slug
+}
+
+// HasBranch checks if a local branch exists
+func (gm *GitManager) HasBranch(name string) bool {
+	_, err := gm.repo.Reference(plumbing.NewBranchReferenceName(name), false)
+	return err == nil
 }
 
 // GetCurrentBranch returns the current branch name
@@ -321,6 +492,34 @@ func (gm *GitManager) GetCurrentBranch() (string, error) {
 	return head.Name().Short(), nil
 }
 
+// CommitCount returns the number of commits reachable from HEAD
+func (gm *GitManager) CommitCount() (int, error) {
+	head, err := gm.repo.Head()
+	if err != nil {
+		if err == plumbing.ErrReferenceNotFound {
+			return 0, nil // No commits yet
+		}
+		return 0, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	iter, err := gm.repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read log: %w", err)
+	}
+	defer iter.Close()
+
+	count := 0
+	err = iter.ForEach(func(*object.Commit) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits: %w", err)
+	}
+
+	return count, nil
+}
+
 // GetStatus returns the current Git status
 func (gm *GitManager) GetStatus() (*GitStatus, error) {
 	status, err := gm.worktree.Status()
@@ -329,14 +528,20 @@ func (gm *GitManager) GetStatus() (*GitStatus, error) {
 	}
 
 	gitStatus := &GitStatus{
-		Modified: []string{},
-		Added:    []string{},
-		Deleted:  []string{},
+		Modified:  []string{},
+		Added:     []string{},
+		Deleted:   []string{},
 		Untracked: []string{},
 	}
 
 	for file, s := range status {
-		switch s.Staging {
+		// Unstaged changes only show in the worktree status
+		code := s.Staging
+		if code == git.Unmodified {
+			code = s.Worktree
+		}
+
+		switch code {
 		case git.Modified:
 			gitStatus.Modified = append(gitStatus.Modified, file)
 		case git.Added:
@@ -387,10 +592,10 @@ type ChangeAnalysis struct {
 
 // GitStatus represents the current Git status
 type GitStatus struct {
-	Modified  []string
-	Added     []string
-	Deleted   []string
-	Untracked []string
+	Modified   []string
+	Added      []string
+	Deleted    []string
+	Untracked  []string
 	HasChanges bool
 }
 
@@ -403,6 +608,42 @@ func (gm *GitManager) SetAuthor(name, email string) {
 	}
 }
 
+// SetCommitter sets the commit committer. An empty name uses the user.name and
+// user.email of the git configuration, so the human running the tool commits
+// what the author identity wrote; without either the author commits.
+func (gm *GitManager) SetCommitter(name, email string) {
+	if name == "" {
+		configName, _ := gm.runGit("config", "user.name")
+		configEmail, _ := gm.runGit("config", "user.email")
+		name, email = strings.TrimSpace(configName), strings.TrimSpace(configEmail)
+	}
+	if name == "" || email == "" {
+		gm.committer = nil
+		return
+	}
+
+	gm.committer = &object.Signature{
+		Name:  name,
+		Email: email,
+		When:  time.Now(),
+	}
+}
+
+// committerSignature returns the committer, or the author when none is set
+func (gm *GitManager) committerSignature() *object.Signature {
+	if gm.committer != nil {
+		return gm.committer
+	}
+	return gm.author
+}
+
+// withTime returns a copy of a signature dated when
+func withTime(sig *object.Signature, when time.Time) *object.Signature {
+	dated := *sig
+	dated.When = when
+	return &dated
+}
+
 // AddRemote adds a remote repository
 func (gm *GitManager) AddRemote(name, url string) error {
 	_, err := gm.repo.CreateRemote(&config.RemoteConfig{
@@ -425,4 +666,4 @@ func (gm *GitManager) AddRemote(name, url string) error {
 // GetProjectDir returns the project directory
 func (gm *GitManager) GetProjectDir() string {
 	return gm.projectDir
-}
\ No newline at end of file
+}
@@ -0,0 +1,123 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// FileChurn counts how many of the last maxCommits commits touched each file
+// under dir. Paths are relative to dir. It fails when dir is not in a git repository.
+func FileChurn(dir string, maxCommits int) (map[string]int, error) {
+	cmd := exec.Command("git", "log", fmt.Sprintf("--max-count=%d", maxCommits),
+		"--format=", "--name-only", "--relative", "--no-renames", "--", ".")
+	cmd.Dir = dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git log: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	churn := make(map[string]int)
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			churn[line]++
+		}
+	}
+	return churn, nil
+}
+
+// ChangedFiles lists the files changed since ref, committed or not, including
+// untracked files. Deleted files are left out; paths are relative to the project.
+func (gm *GitManager) ChangedFiles(ref string) ([]string, error) {
+	if _, err := gm.runGit("rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("unknown revision: %s", ref)
+	}
+
+	changed, err := gm.runGit("diff", "--name-only", "--relative", "--diff-filter=d", "-z", ref, "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files: %w", err)
+	}
+	untracked, err := gm.runGit("ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	return splitPaths(changed + untracked), nil
+}
+
+// StagedFiles lists the files added or modified in the index
+func (gm *GitManager) StagedFiles() ([]string, error) {
+	staged, err := gm.runGit("diff", "--cached", "--name-only", "--relative", "--diff-filter=d", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staged files: %w", err)
+	}
+	return splitPaths(staged), nil
+}
+
+// DiscardSince restores the files changed since before, throwing away an
+// attempt described by what, such as "the failed fix". Failures are logged
+// and leave the changes in place.
+func (gm *GitManager) DiscardSince(before Snapshot, what string) {
+	after, err := gm.TakeSnapshot()
+	if err != nil {
+		gm.logger.Warn().Err(err).Msg("Failed to snapshot project, " + what + " is left in place")
+		return
+	}
+	files := DiffSnapshots(before, after).Files()
+	if len(files) == 0 {
+		return
+	}
+	if err := gm.RestoreFiles(files); err != nil {
+		gm.logger.Warn().Err(err).Msg("Failed to discard " + what)
+		return
+	}
+	gm.logger.Info().Strs("files", files).Msg("Discarded " + what)
+}
+
+// RestoreFiles returns files, relative to the project, to their content at HEAD.
+// Files HEAD does not have are removed.
+func (gm *GitManager) RestoreFiles(files []string) error {
+	if _, err := gm.runGit("rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		return fmt.Errorf("no commit to restore files from")
+	}
+
+	var tracked []string
+	for _, file := range files {
+		if _, err := gm.runGit("cat-file", "-e", "HEAD:./"+file); err == nil {
+			tracked = append(tracked, file)
+			continue
+		}
+		if _, err := gm.runGit("rm", "--cached", "--quiet", "--ignore-unmatch", "--", file); err != nil {
+			return fmt.Errorf("failed to unstage %s: %w", file, err)
+		}
+		if err := os.Remove(filepath.Join(gm.projectDir, filepath.FromSlash(file))); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", file, err)
+		}
+	}
+
+	if len(tracked) > 0 {
+		if _, err := gm.runGit(append([]string{"checkout", "HEAD", "--"}, tracked...)...); err != nil {
+			return fmt.Errorf("failed to restore files: %w", err)
+		}
+	}
+	return nil
+}
+
+// splitPaths splits NUL-separated git output into unique paths
+func splitPaths(output string) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, path := range strings.Split(output, "\x00") {
+		if path != "" && !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
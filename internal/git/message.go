@@ -0,0 +1,264 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nohdol/claude-auto/internal/core"
+	"github.com/nohdol/claude-auto/pkg/types"
+)
+
+// CommitMessageMode selects how commit messages are written
+type CommitMessageMode string
+
+const (
+	CommitMessageTemplate CommitMessageMode = "template" // Generated from the task type
+	CommitMessageAI       CommitMessageMode = "ai"       // Written by Claude from the staged diff
+)
+
+const (
+	// maxDiffSize limits the staged diff sent to the message writer
+	maxDiffSize = 12000
+	// minFileDiffSize is the share of the diff each file keeps when truncating
+	minFileDiffSize = 400
+	// messageTimeout limits how long a message may take to write
+	messageTimeout = 2 * time.Minute
+	// maxHeaderLength limits the header line of a commit message
+	maxHeaderLength = 100
+)
+
+// conventionalHeader matches a Conventional Commits header: type(scope)!: description
+var conventionalHeader = regexp.MustCompile(`^(feat|fix|docs|style|refactor|perf|test|build|ci|chore|revert)(\([\w./-]+\))?!?: \S.*$`)
+
+// MessageWriter writes a commit message for a staged diff
+type MessageWriter interface {
+	WriteCommitMessage(ctx context.Context, diff string, taskType types.TaskType) (string, error)
+}
+
+// SetMessageWriter makes commits without an explicit message ask w for one.
+// Invalid messages fall back to the template; nil restores template messages.
+func (gm *GitManager) SetMessageWriter(w MessageWriter) {
+	gm.messageWriter = w
+}
+
+// CommitMessage writes the message a commit of files would get without staging
+// them. Writing a message may take Claude minutes, so callers serializing their
+// commits write it first and pass it to CommitWithTrailers under their lock.
+func (gm *GitManager) CommitMessage(files []string, taskType types.TaskType) string {
+	changes := gm.analyzeChanges(files)
+	if gm.messageWriter == nil || len(files) == 0 {
+		return gm.generateCommitMessage(taskType, changes)
+	}
+
+	// Stage the files in a scratch index so the real index is left alone
+	dir, err := os.MkdirTemp("", "claude-auto-index-")
+	if err != nil {
+		gm.logger.Warn().Err(err).Msg("Failed to create scratch index, using template commit message")
+		return gm.generateCommitMessage(taskType, changes)
+	}
+	defer os.RemoveAll(dir)
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(dir, "index")}
+
+	if _, err := gm.runGit("rev-parse", "--verify", "HEAD"); err == nil {
+		if _, err := gm.runGitEnv(env, "read-tree", "HEAD"); err != nil {
+			gm.logger.Warn().Err(err).Msg("Failed to read HEAD into scratch index, using template commit message")
+			return gm.generateCommitMessage(taskType, changes)
+		}
+	}
+	args := []string{"add", "-A", "--"}
+	for _, file := range files {
+		if relPath, err := gm.relativePath(file); err == nil {
+			args = append(args, relPath)
+		}
+	}
+	if _, err := gm.runGitEnv(env, args...); err != nil {
+		gm.logger.Warn().Err(err).Msg("Failed to stage scratch index, using template commit message")
+		return gm.generateCommitMessage(taskType, changes)
+	}
+
+	return gm.writeMessage(taskType, changes, env)
+}
+
+// commitMessage writes the message for the staged changes
+func (gm *GitManager) commitMessage(taskType types.TaskType, changes *ChangeAnalysis) string {
+	if gm.messageWriter == nil {
+		return gm.generateCommitMessage(taskType, changes)
+	}
+	return gm.writeMessage(taskType, changes, nil)
+}
+
+// writeMessage asks the message writer about the changes staged in the index
+// selected by env, falling back to the template
+func (gm *GitManager) writeMessage(taskType types.TaskType, changes *ChangeAnalysis, env []string) string {
+	fallback := gm.generateCommitMessage(taskType, changes)
+
+	diff, err := gm.stagedDiff(env, maxDiffSize)
+	if err != nil {
+		gm.logger.Warn().Err(err).Msg("Failed to read staged diff, using template commit message")
+		return fallback
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), messageTimeout)
+	defer cancel()
+
+	message, err := gm.messageWriter.WriteCommitMessage(ctx, diff, taskType)
+	if err != nil {
+		gm.logger.Warn().Err(err).Msg("Failed to write commit message, using template")
+		return fallback
+	}
+
+	message = cleanCommitMessage(message)
+	if err := ValidateConventionalCommit(message); err != nil {
+		gm.logger.Warn().Err(err).Str("commit_message", message).Msg("Invalid commit message, using template")
+		return fallback
+	}
+
+	return message
+}
+
+// StagedDiff returns the staged diff truncated to about maxSize bytes.
+// Truncated diffs start with the stat summary and keep the start of every file.
+func (gm *GitManager) StagedDiff(maxSize int) (string, error) {
+	return gm.stagedDiff(nil, maxSize)
+}
+
+// stagedDiff returns the truncated diff of the index selected by env
+func (gm *GitManager) stagedDiff(env []string, maxSize int) (string, error) {
+	diff, err := gm.runGitEnv(env, "diff", "--cached", "--no-color")
+	if err != nil {
+		return "", err
+	}
+	if len(diff) <= maxSize {
+		return diff, nil
+	}
+
+	stat, err := gm.runGitEnv(env, "diff", "--cached", "--no-color", "--stat")
+	if err != nil {
+		return "", err
+	}
+
+	files := splitFileDiffs(diff)
+	perFile := (maxSize - len(stat)) / len(files)
+	if perFile < minFileDiffSize {
+		perFile = minFileDiffSize
+	}
+
+	var b strings.Builder
+	b.WriteString(stat)
+	b.WriteString("\n")
+	for _, file := range files {
+		if b.Len() >= maxSize {
+			b.WriteString("... (remaining files omitted)\n")
+			break
+		}
+		if len(file) > perFile {
+			// Cut on a rune boundary so the prompt stays valid UTF-8
+			cut := perFile
+			for cut > 0 && !utf8.RuneStart(file[cut]) {
+				cut--
+			}
+			file = file[:cut] + "\n... (truncated)\n"
+		}
+		b.WriteString(file)
+	}
+
+	return b.String(), nil
+}
+
+// splitFileDiffs splits a diff into the diffs of each file
+func splitFileDiffs(diff string) []string {
+	var files []string
+	for _, part := range strings.SplitAfter(diff, "\n") {
+		if strings.HasPrefix(part, "diff --git ") || len(files) == 0 {
+			files = append(files, part)
+			continue
+		}
+		files[len(files)-1] += part
+	}
+	return files
+}
+
+// ValidateConventionalCommit validates a message against the Conventional Commits grammar
+func ValidateConventionalCommit(message string) error {
+	lines := strings.Split(message, "\n")
+	header := lines[0]
+
+	if !conventionalHeader.MatchString(header) {
+		return fmt.Errorf("header %q is not in type(scope): description form", header)
+	}
+	if len(header) > maxHeaderLength {
+		return fmt.Errorf("header is longer than %d characters", maxHeaderLength)
+	}
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		return fmt.Errorf("header must be followed by a blank line")
+	}
+
+	return nil
+}
+
+// cleanCommitMessage strips code fences and surrounding whitespace from a written message
+func cleanCommitMessage(message string) string {
+	message = strings.TrimSpace(message)
+	if strings.HasPrefix(message, "```") {
+		lines := strings.Split(message, "\n")
+		lines = lines[1:]
+		if len(lines) > 0 && strings.HasPrefix(strings.TrimSpace(lines[len(lines)-1]), "```") {
+			lines = lines[:len(lines)-1]
+		}
+		message = strings.TrimSpace(strings.Join(lines, "\n"))
+	}
+	return message
+}
+
+// ClaudeMessageWriter writes commit messages with Claude
+type ClaudeMessageWriter struct {
+	executor *core.ClaudeExecutor
+	workDir  string
+}
+
+// NewClaudeMessageWriter creates a new Claude commit message writer running in
+// the project directory
+func NewClaudeMessageWriter(ce *core.ClaudeExecutor, workDir string) *ClaudeMessageWriter {
+	return &ClaudeMessageWriter{executor: ce, workDir: workDir}
+}
+
+// WriteCommitMessage asks Claude for a Conventional Commits message describing the diff
+func (w *ClaudeMessageWriter) WriteCommitMessage(ctx context.Context, diff string, taskType types.TaskType) (string, error) {
+	prompt := fmt.Sprintf(`다음 staged diff에 대한 커밋 메시지를 Conventional Commits 형식의 영어로 작성해주세요.
+
+형식:
+<type>(<scope>): <description>
+
+<body>
+
+규칙:
+- type은 feat, fix, docs, style, refactor, perf, test, build, ci, chore, revert 중 하나
+- scope는 변경된 모듈이나 영역 (예: api, ui, db, auth)
+- 첫 줄은 72자 이내, 명령형, 마침표 없음
+- 본문에는 무엇을 왜 변경했는지 간단히 설명
+- 커밋 메시지만 출력하고 다른 설명이나 코드 블록은 쓰지 마세요
+
+작업 유형: %s
+
+`+"```diff\n%s\n```", taskType, diff)
+
+	response, err := w.executor.Execute(ctx, prompt, &core.ClaudeOptions{
+		Role:         "technical-writer",
+		SystemPrompt: "You write concise, accurate Conventional Commits messages from diffs.",
+		WorkDir:      w.workDir,
+		// The diff is in the prompt, so Claude needs no tools; the = form keeps
+		// the variadic flag from taking the prompt as a tool name
+		AdditionalFlags: []string{"--tools="},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return response.Output, nil
+}
@@ -0,0 +1,75 @@
+package git
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestValidateConventionalCommit(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		wantErr bool
+	}{
+		{"type only", "fix: handle nil config", false},
+		{"scope and body", "feat(auth): add login endpoint\n\nAdds POST /login.", false},
+		{"breaking", "refactor(api)!: rename handlers", false},
+		{"no type", "handle nil config", true},
+		{"no blank line", "fix: handle nil config\nbody", true},
+		{"long header", "fix: " + strings.Repeat("x", 100), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateConventionalCommit(tt.message)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateConventionalCommit(%q) = %v, wantErr %v", tt.message, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCleanCommitMessage(t *testing.T) {
+	got := cleanCommitMessage("\n```text\nfix: handle nil config\n\nbody\n```\n")
+	if want := "fix: handle nil config\n\nbody"; got != want {
+		t.Errorf("cleanCommitMessage = %q, want %q", got, want)
+	}
+}
+
+func TestSplitFileDiffs(t *testing.T) {
+	diff := "diff --git a/a.go b/a.go\n+a\ndiff --git a/b.go b/b.go\n+b\n"
+	files := splitFileDiffs(diff)
+	if len(files) != 2 || !strings.HasPrefix(files[1], "diff --git a/b.go") {
+		t.Fatalf("splitFileDiffs = %q, want the diffs of a.go and b.go", files)
+	}
+	if strings.Join(files, "") != diff {
+		t.Errorf("splitFileDiffs lost part of the diff: %q", files)
+	}
+}
+
+func TestStagedDiffTruncatesOnRuneBoundary(t *testing.T) {
+	gm, _ := newPushRepo(t, PushConfig{})
+	dir := gm.GetProjectDir()
+
+	// Every line is made of three-byte runes, so most cuts land inside one
+	for _, name := range []string{"a.md", "b.md", "c.md"} {
+		commitFile(t, dir, name, "# 문서\n")
+		commitFile(t, dir, name, strings.Repeat("한국어 문서 내용입니다\n", 200))
+		gitCmd(t, dir, "reset", "-q", "--soft", "HEAD~1")
+	}
+
+	// Sizes a few bytes apart move the cut across every byte of a rune
+	for maxSize := 2000; maxSize < 2012; maxSize++ {
+		diff, err := gm.stagedDiff(nil, maxSize)
+		if err != nil {
+			t.Fatalf("stagedDiff: %v", err)
+		}
+		if !strings.Contains(diff, "... (truncated)") {
+			t.Fatalf("stagedDiff(%d) was not truncated:\n%s", maxSize, diff)
+		}
+		if !utf8.ValidString(diff) {
+			t.Errorf("stagedDiff(%d) cut a rune in half", maxSize)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+// PushStrategy controls when commits are pushed
+type PushStrategy string
+
+const (
+	PushImmediate PushStrategy = "immediate" // After every commit
+	PushBatch     PushStrategy = "batch"     // At the end of a run
+	PushManual    PushStrategy = "manual"    // Never
+)
+
+// ErrProtectedBranch is returned when pushing to a protected branch
+var ErrProtectedBranch = errors.New("refusing to push to protected branch")
+
+// PushConfig configures pushing and authentication
+type PushConfig struct {
+	Strategy          PushStrategy
+	Remote            string
+	ProtectedBranches []string
+	Retries           int
+	// SSHKeyPath is a private key used instead of the SSH agent
+	SSHKeyPath string
+	// SSHKeyPassphraseEnv names the environment variable holding the key passphrase
+	SSHKeyPassphraseEnv string
+	// TokenEnv names the environment variable holding an HTTPS access token
+	TokenEnv string
+}
+
+// SetPushConfig configures pushing for the manager
+func (gm *GitManager) SetPushConfig(cfg PushConfig) {
+	if cfg.Remote == "" {
+		cfg.Remote = "origin"
+	}
+	gm.push = cfg
+}
+
+// Push pushes a branch to a remote, retrying transient failures
+func (gm *GitManager) Push(remote, branch string) error {
+	if gm.IsProtectedBranch(branch) {
+		return fmt.Errorf("%w: %s", ErrProtectedBranch, branch)
+	}
+
+	r, err := gm.repo.Remote(remote)
+	if err != nil {
+		return fmt.Errorf("failed to get remote: %w", err)
+	}
+
+	if len(r.Config().URLs) == 0 {
+		return fmt.Errorf("remote %s has no URL", remote)
+	}
+
+	auth, err := gm.authFor(r.Config().URLs[0])
+	if err != nil {
+		return fmt.Errorf("failed to set up authentication: %w", err)
+	}
+
+	options := &git.PushOptions{
+		RemoteName: remote,
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, branch)),
+		},
+		Auth: auth,
+	}
+
+	retries := gm.push.Retries
+	if retries <= 0 {
+		retries = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = r.Push(options)
+		if err == nil || err == git.NoErrAlreadyUpToDate {
+			break
+		}
+		if attempt >= retries || !isTransientPushError(err) {
+			return fmt.Errorf("failed to push: %w", err)
+		}
+
+		backoff := time.Duration(1<<uint(attempt-1)) * time.Second
+		gm.logger.Warn().
+			Err(err).
+			Int("attempt", attempt).
+			Dur("backoff", backoff).
+			Msg("Push failed, retrying")
+		time.Sleep(backoff)
+	}
+
+	gm.logger.Info().
+		Str("remote", remote).
+		Str("branch", branch).
+		Msg("Pushed to remote")
+
+	return nil
+}
+
+// PushCurrentBranch pushes the current branch to the configured remote.
+// Repositories without the remote are skipped.
+func (gm *GitManager) PushCurrentBranch() error {
+	if !gm.HasRemote(gm.push.Remote) {
+		gm.logger.Debug().Str("remote", gm.push.Remote).Msg("No remote configured, skipping push")
+		return nil
+	}
+
+	branch, err := gm.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	return gm.Push(gm.push.Remote, branch)
+}
+
+// pushAfterCommit pushes right after a commit when the strategy is immediate
+func (gm *GitManager) pushAfterCommit() {
+	if gm.push.Strategy != PushImmediate {
+		return
+	}
+	if err := gm.PushCurrentBranch(); err != nil {
+		gm.logger.Warn().Err(err).Msg("Failed to push commit")
+	}
+}
+
+// RemoteURL returns the first URL of a remote
+func (gm *GitManager) RemoteURL(name string) (string, error) {
+	r, err := gm.repo.Remote(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get remote: %w", err)
+	}
+	if len(r.Config().URLs) == 0 {
+		return "", fmt.Errorf("remote %s has no URL", name)
+	}
+	return r.Config().URLs[0], nil
+}
+
+// HasRemote checks if a remote is configured
+func (gm *GitManager) HasRemote(name string) bool {
+	_, err := gm.repo.Remote(name)
+	return err == nil
+}
+
+// IsProtectedBranch checks if a branch is listed as protected
+func (gm *GitManager) IsProtectedBranch(branch string) bool {
+	for _, protected := range gm.push.ProtectedBranches {
+		if protected == branch {
+			return true
+		}
+	}
+	return false
+}
+
+// authFor selects the authentication for a remote URL: an SSH key or the SSH
+// agent for SSH remotes, a token for HTTPS remotes and none for local remotes
+func (gm *GitManager) authFor(url string) (transport.AuthMethod, error) {
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		return nil, err
+	}
+
+	switch endpoint.Protocol {
+	case "ssh":
+		user := endpoint.User
+		if user == "" {
+			user = "git"
+		}
+		if gm.push.SSHKeyPath != "" {
+			passphrase := ""
+			if gm.push.SSHKeyPassphraseEnv != "" {
+				passphrase = os.Getenv(gm.push.SSHKeyPassphraseEnv)
+			}
+			return ssh.NewPublicKeysFromFile(user, expandHome(gm.push.SSHKeyPath), passphrase)
+		}
+		return ssh.NewSSHAgentAuth(user)
+
+	case "http", "https":
+		if gm.push.TokenEnv == "" {
+			return nil, nil
+		}
+		token := os.Getenv(gm.push.TokenEnv)
+		if token == "" {
+			return nil, nil // Public or credential-less remote
+		}
+		user := endpoint.User
+		if user == "" {
+			user = "x-access-token"
+		}
+		return &http.BasicAuth{Username: user, Password: token}, nil
+	}
+
+	return nil, nil
+}
+
+// isTransientPushError reports whether a push failure may succeed when retried
+func isTransientPushError(err error) bool {
+	permanent := []error{
+		transport.ErrAuthenticationRequired,
+		transport.ErrAuthorizationFailed,
+		transport.ErrRepositoryNotFound,
+		git.ErrForceNeeded,
+		ErrProtectedBranch,
+	}
+	for _, target := range permanent {
+		if errors.Is(err, target) {
+			return false
+		}
+	}
+	return !strings.Contains(err.Error(), "non-fast-forward")
+}
+
+// expandHome expands a leading ~ to the home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return home + path[1:]
+		}
+	}
+	return path
+}
@@ -0,0 +1,162 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/nohdol/claude-auto/pkg/types"
+	"github.com/rs/zerolog"
+)
+
+// gitCmd runs the git CLI in dir and returns its trimmed output
+func gitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// commitFile writes a file in dir and commits it with the git CLI
+func commitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd(t, dir, "add", name)
+	gitCmd(t, dir, "commit", "-q", "-m", "add "+name)
+}
+
+// newPushRepo creates a repository on branch main with one commit and a bare
+// origin remote, and returns its manager and the remote directory
+func newPushRepo(t *testing.T, cfg PushConfig) (*GitManager, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	gitCmd(t, filepath.Dir(remote), "init", "-q", "--bare", remote)
+
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q", "-b", "main")
+	commitFile(t, dir, "README.md", "# test\n")
+	gitCmd(t, dir, "remote", "add", "origin", remote)
+
+	gm, err := NewGitManager(dir, types.CommitSizeSmall, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gm.SetPushConfig(cfg)
+	return gm, remote
+}
+
+// remoteBranch returns the commit of a branch in the remote, or "" when it has none
+func remoteBranch(t *testing.T, remote, branch string) string {
+	t.Helper()
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+	cmd.Dir = remote
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func TestPushRefusesProtectedBranch(t *testing.T) {
+	gm, remote := newPushRepo(t, PushConfig{ProtectedBranches: []string{"main"}})
+
+	err := gm.Push("origin", "main")
+	if !errors.Is(err, ErrProtectedBranch) {
+		t.Fatalf("Push to main = %v, want ErrProtectedBranch", err)
+	}
+	if isTransientPushError(err) {
+		t.Errorf("refusal to push %v is retried", err)
+	}
+	if commit := remoteBranch(t, remote, "main"); commit != "" {
+		t.Errorf("protected branch was pushed to the remote at %s", commit)
+	}
+}
+
+func TestPushFeatureBranch(t *testing.T) {
+	gm, remote := newPushRepo(t, PushConfig{ProtectedBranches: []string{"main"}})
+	gitCmd(t, gm.GetProjectDir(), "checkout", "-q", "-b", "feature/login")
+	commitFile(t, gm.GetProjectDir(), "login.go", "package main\n")
+
+	if err := gm.PushCurrentBranch(); err != nil {
+		t.Fatalf("PushCurrentBranch: %v", err)
+	}
+	want := gitCmd(t, gm.GetProjectDir(), "rev-parse", "HEAD")
+	if got := remoteBranch(t, remote, "feature/login"); got != want {
+		t.Errorf("remote feature/login = %q, want %q", got, want)
+	}
+
+	// Pushing again has nothing to send
+	if err := gm.Push("origin", "feature/login"); err != nil {
+		t.Errorf("Push of an up-to-date branch: %v", err)
+	}
+}
+
+func TestPushDoesNotRetryRejectedPush(t *testing.T) {
+	gm, remote := newPushRepo(t, PushConfig{Retries: 3})
+	gitCmd(t, gm.GetProjectDir(), "push", "-q", "origin", "main")
+
+	// Someone else pushes first
+	other := t.TempDir()
+	gitCmd(t, other, "clone", "-q", "-b", "main", remote, ".")
+	commitFile(t, other, "other.txt", "other\n")
+	gitCmd(t, other, "push", "-q", "origin", "main")
+
+	commitFile(t, gm.GetProjectDir(), "mine.txt", "mine\n")
+
+	start := time.Now()
+	err := gm.Push("origin", "main")
+	if err == nil {
+		t.Fatal("Push over a diverged remote branch succeeded")
+	}
+	if isTransientPushError(err) {
+		t.Errorf("rejected push %v is classified as transient", err)
+	}
+	// A retry would have waited at least a second first
+	if elapsed := time.Since(start); elapsed >= time.Second {
+		t.Errorf("rejected push took %v, it was retried", elapsed)
+	}
+}
+
+func TestIsTransientPushError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection reset", errors.New("read tcp: connection reset by peer"), true},
+		{"timeout", fmt.Errorf("push: %w", errors.New("i/o timeout")), true},
+		{"authentication", fmt.Errorf("push: %w", transport.ErrAuthenticationRequired), false},
+		{"authorization", transport.ErrAuthorizationFailed, false},
+		{"missing repository", transport.ErrRepositoryNotFound, false},
+		{"protected branch", fmt.Errorf("%w: main", ErrProtectedBranch), false},
+		{"non-fast-forward", errors.New("non-fast-forward update: refs/heads/main"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientPushError(tt.err); got != tt.want {
+				t.Errorf("isTransientPushError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,555 @@
+package git
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// stateDir holds claude-auto state inside a project
+	stateDir = ".claude-auto"
+	// runRefPrefix prefixes the refs keeping run start points and stashes alive
+	runRefPrefix = "refs/claude-auto/runs/"
+)
+
+// ErrRunNotFound is returned when no run record matches
+var ErrRunNotFound = errors.New("run not found")
+
+// RunRecord records a run so its changes can be undone
+type RunRecord struct {
+	ID      string   `json:"id"`
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	// StartBranch is the branch checked out when the run started
+	StartBranch string `json:"start_branch"`
+	StartCommit string `json:"start_commit"`
+	// Stash holds the uncommitted and untracked files present before the run
+	Stash string `json:"stash,omitempty"`
+	// Branch is the branch the run committed to
+	Branch    string   `json:"branch,omitempty"`
+	EndCommit string   `json:"end_commit,omitempty"`
+	Commits   []string `json:"commits,omitempty"`
+	// Tasks maps task IDs to their commits, oldest first
+	Tasks map[string][]string `json:"tasks,omitempty"`
+	// UndoneTasks lists the tasks undone on their own
+	UndoneTasks []string   `json:"undone_tasks,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	UndoneAt    *time.Time `json:"undone_at,omitempty"`
+}
+
+// StartRun records the starting point of a run: the current branch and commit,
+// and a stash of any uncommitted work. The working tree is left as it was.
+func (gm *GitManager) StartRun(command string, args []string) (*RunRecord, error) {
+	if err := gm.excludeStateDir(); err != nil {
+		return nil, err
+	}
+
+	// A run needs a commit to return to
+	if err := gm.EnsureInitialCommit(); err != nil {
+		return nil, err
+	}
+
+	run := &RunRecord{
+		ID:        gm.newRunID(),
+		Command:   command,
+		Args:      args,
+		StartedAt: time.Now(),
+	}
+
+	head, err := gm.runGit("rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	run.StartCommit = strings.TrimSpace(head)
+	if branch, err := gm.GetCurrentBranch(); err == nil {
+		run.StartBranch = branch
+	}
+
+	if _, err := gm.runGit("update-ref", runRefPrefix+run.ID+"/start", run.StartCommit); err != nil {
+		return nil, fmt.Errorf("failed to record run start: %w", err)
+	}
+
+	stash, err := gm.stashWorkingTree(run.ID)
+	if err != nil {
+		return nil, err
+	}
+	run.Stash = stash
+
+	if err := gm.saveRun(run); err != nil {
+		return nil, err
+	}
+	gm.runID = run.ID
+
+	gm.logger.Info().
+		Str("run_id", run.ID).
+		Str("command", command).
+		Str("start_commit", run.StartCommit).
+		Msg("Run started")
+
+	return run, nil
+}
+
+// FinishRun records the commits a run made and which task made each of them
+func (gm *GitManager) FinishRun(run *RunRecord) error {
+	head, err := gm.runGit("rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	run.EndCommit = strings.TrimSpace(head)
+	if branch, err := gm.GetCurrentBranch(); err == nil {
+		run.Branch = branch
+	}
+
+	// Commits oldest first, each with its Task-Id trailers
+	log, err := gm.runGit("log", "--reverse", "--topo-order",
+		"--format=%H%x09%(trailers:key="+TrailerTaskID+",valueonly,separator=%x2C)",
+		run.StartCommit+".."+run.EndCommit)
+	if err != nil {
+		return fmt.Errorf("failed to read run commits: %w", err)
+	}
+
+	run.Commits = nil
+	run.Tasks = make(map[string][]string)
+	for _, line := range strings.Split(strings.TrimSpace(log), "\n") {
+		if line == "" {
+			continue
+		}
+		hash, taskIDs, _ := strings.Cut(line, "\t")
+		run.Commits = append(run.Commits, hash)
+		for _, taskID := range strings.Split(taskIDs, ",") {
+			if taskID = strings.TrimSpace(taskID); taskID != "" {
+				run.Tasks[taskID] = append(run.Tasks[taskID], hash)
+			}
+		}
+	}
+
+	now := time.Now()
+	run.FinishedAt = &now
+
+	gm.logger.Info().
+		Str("run_id", run.ID).
+		Int("commits", len(run.Commits)).
+		Int("tasks", len(run.Tasks)).
+		Msg("Run finished")
+
+	return gm.saveRun(run)
+}
+
+// UndoRun returns the project to its state before a run. Unpushed runs that are
+// still the tip of their branch are hard-reset; otherwise their commits are reverted.
+// The working tree must be clean; the pre-run stash is restored afterwards.
+func (gm *GitManager) UndoRun(run *RunRecord) error {
+	if run.UndoneAt != nil {
+		return fmt.Errorf("run %s was already undone", run.ID)
+	}
+
+	if err := gm.requireClean(); err != nil {
+		return err
+	}
+
+	branch := run.Branch
+	if branch == "" {
+		// Interrupted run; its commits end wherever its branch is now
+		current, err := gm.GetCurrentBranch()
+		if err != nil {
+			return fmt.Errorf("failed to get current branch: %w", err)
+		}
+		branch = current
+	}
+	if err := gm.checkoutForUndo(branch); err != nil {
+		return err
+	}
+
+	commits, err := gm.runCommits(run)
+	if err != nil {
+		return err
+	}
+	if err := gm.undoCommits(commits, run.StartCommit, fmt.Sprintf("revert: undo claude-auto run %s", run.ID)); err != nil {
+		return err
+	}
+
+	if run.StartBranch != "" && run.StartBranch != branch {
+		if err := gm.checkoutForUndo(run.StartBranch); err != nil {
+			return err
+		}
+	}
+
+	if run.Stash != "" {
+		if _, err := gm.runGit("stash", "apply", "--index", run.Stash); err != nil {
+			return fmt.Errorf("failed to restore pre-run stash %s: %w", run.Stash, err)
+		}
+	}
+
+	now := time.Now()
+	run.UndoneAt = &now
+	return gm.saveRun(run)
+}
+
+// UndoTask reverts the commits of a single task of a run, or hard-resets them
+// when they are unpushed and still the tip of the current branch
+func (gm *GitManager) UndoTask(run *RunRecord, taskID string) error {
+	commits := run.Tasks[taskID]
+	if len(commits) == 0 {
+		return fmt.Errorf("task %s has no commits in run %s", taskID, run.ID)
+	}
+	if run.UndoneAt != nil || run.taskUndone(taskID) {
+		return fmt.Errorf("task %s of run %s was already undone", taskID, run.ID)
+	}
+
+	if err := gm.requireClean(); err != nil {
+		return err
+	}
+
+	if run.Branch != "" {
+		if err := gm.checkoutForUndo(run.Branch); err != nil {
+			return err
+		}
+	}
+
+	message := fmt.Sprintf("revert: undo task %s of claude-auto run %s", taskID, run.ID)
+	if err := gm.undoCommits(commits, commits[0]+"^", message); err != nil {
+		return err
+	}
+
+	run.UndoneTasks = append(run.UndoneTasks, taskID)
+	return gm.saveRun(run)
+}
+
+// requireClean refuses a working tree with uncommitted changes, which an undo
+// would otherwise reset or mix into its revert commit
+func (gm *GitManager) requireClean() error {
+	status, err := gm.runGit("status", "--porcelain")
+	if err != nil {
+		return fmt.Errorf("failed to get status: %w", err)
+	}
+	if strings.TrimSpace(status) != "" {
+		return ErrDirtyWorktree
+	}
+	return nil
+}
+
+// taskUndone reports whether a task of the run was undone on its own
+func (run *RunRecord) taskUndone(taskID string) bool {
+	for _, undone := range run.UndoneTasks {
+		if undone == taskID {
+			return true
+		}
+	}
+	return false
+}
+
+// undoCommits removes commits from the current branch. When none of them was
+// pushed and they are exactly the commits after base, the branch is reset to base;
+// otherwise they are reverted newest first in a single commit.
+func (gm *GitManager) undoCommits(commits []string, base, message string) error {
+	if len(commits) == 0 {
+		return nil
+	}
+
+	pushed, err := gm.anyPushed(commits)
+	if err != nil {
+		return err
+	}
+
+	if !pushed {
+		tip, err := gm.runGit("rev-list", base+"..HEAD")
+		if err != nil {
+			return fmt.Errorf("failed to list commits: %w", err)
+		}
+		if sameCommits(strings.Fields(tip), commits) {
+			if _, err := gm.runGit("reset", "--hard", base); err != nil {
+				return fmt.Errorf("failed to reset to %s: %w", base, err)
+			}
+			gm.logger.Info().Str("base", base).Int("commits", len(commits)).Msg("Commits reset")
+			return nil
+		}
+	}
+
+	// Merge commits are undone through the commits they merged
+	merges, err := gm.runGit(append([]string{"rev-list", "--no-walk", "--merges"}, commits...)...)
+	if err != nil {
+		return fmt.Errorf("failed to list merge commits: %w", err)
+	}
+	isMerge := make(map[string]bool)
+	for _, commit := range strings.Fields(merges) {
+		isMerge[commit] = true
+	}
+
+	for i := len(commits) - 1; i >= 0; i-- {
+		if isMerge[commits[i]] {
+			continue
+		}
+		if _, err := gm.runGit("revert", "--no-commit", commits[i]); err != nil {
+			gm.runGit("revert", "--abort")
+			return fmt.Errorf("failed to revert %s: %w", commits[i], err)
+		}
+	}
+
+	if _, err := gm.runGit("commit", "--allow-empty", "-m", message); err != nil {
+		return fmt.Errorf("failed to commit revert: %w", err)
+	}
+	if err := gm.signHead(); err != nil {
+		return err
+	}
+	gm.logger.Info().Int("commits", len(commits)).Msg("Commits reverted")
+
+	gm.pushAfterCommit()
+	return nil
+}
+
+// anyPushed reports whether any of the commits is on a remote-tracking branch
+func (gm *GitManager) anyPushed(commits []string) (bool, error) {
+	unpushed, err := gm.runGit(append(append([]string{"rev-list"}, commits...), "--not", "--remotes")...)
+	if err != nil {
+		return false, fmt.Errorf("failed to check pushed commits: %w", err)
+	}
+
+	local := make(map[string]bool)
+	for _, commit := range strings.Fields(unpushed) {
+		local[commit] = true
+	}
+	for _, commit := range commits {
+		if !local[commit] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// runCommits returns the commits of a run not undone with their task, reading
+// them from the branch when the run was interrupted before it finished
+func (gm *GitManager) runCommits(run *RunRecord) ([]string, error) {
+	commits := run.Commits
+	if run.FinishedAt == nil {
+		log, err := gm.runGit("rev-list", "--reverse", "--topo-order", run.StartCommit+"..HEAD")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read run commits: %w", err)
+		}
+		commits = strings.Fields(log)
+	}
+
+	undone := make(map[string]bool)
+	for _, taskID := range run.UndoneTasks {
+		for _, commit := range run.Tasks[taskID] {
+			undone[commit] = true
+		}
+	}
+
+	var remaining []string
+	for _, commit := range commits {
+		if !undone[commit] {
+			remaining = append(remaining, commit)
+		}
+	}
+	return remaining, nil
+}
+
+// checkoutForUndo checks out a branch unless it is already checked out
+func (gm *GitManager) checkoutForUndo(branch string) error {
+	if current, err := gm.GetCurrentBranch(); err == nil && current == branch {
+		return nil
+	}
+	if _, err := gm.runGit("checkout", branch); err != nil {
+		return fmt.Errorf("failed to check out %s: %w", branch, err)
+	}
+	return nil
+}
+
+// stashWorkingTree stores uncommitted and untracked files in a stash commit
+// kept under the run's refs and returns its hash, leaving the working tree as it was
+func (gm *GitManager) stashWorkingTree(runID string) (string, error) {
+	status, err := gm.runGit("status", "--porcelain")
+	if err != nil {
+		return "", fmt.Errorf("failed to get status: %w", err)
+	}
+	if strings.TrimSpace(status) == "" {
+		return "", nil
+	}
+
+	if _, err := gm.runGit("stash", "push", "--include-untracked", "-m", "claude-auto: before run "+runID); err != nil {
+		return "", fmt.Errorf("failed to stash working tree: %w", err)
+	}
+	hash, err := gm.runGit("rev-parse", "stash@{0}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve stash: %w", err)
+	}
+	stash := strings.TrimSpace(hash)
+
+	if _, err := gm.runGit("stash", "apply", "--index", stash); err != nil {
+		return "", fmt.Errorf("failed to restore working tree from stash %s: %w", stash, err)
+	}
+
+	// The ref keeps the stash after it leaves the stash list
+	if _, err := gm.runGit("update-ref", runRefPrefix+runID+"/stash", stash); err != nil {
+		return "", fmt.Errorf("failed to record stash: %w", err)
+	}
+	if _, err := gm.runGit("stash", "drop", "--quiet"); err != nil {
+		gm.logger.Warn().Err(err).Msg("Failed to drop pre-run stash entry")
+	}
+
+	return stash, nil
+}
+
+// discardWorkingTree drops uncommitted changes and untracked files.
+// Ignored files, including the run records, are kept.
+func (gm *GitManager) discardWorkingTree() error {
+	if _, err := gm.runGit("reset", "--hard", "HEAD"); err != nil {
+		return fmt.Errorf("failed to discard changes: %w", err)
+	}
+	if _, err := gm.runGit("clean", "-fd"); err != nil {
+		return fmt.Errorf("failed to remove untracked files: %w", err)
+	}
+	return nil
+}
+
+// excludeStateDir keeps the state directory out of stashes, cleans and commits
+// through the repository's info/exclude, without touching the project's .gitignore
+func (gm *GitManager) excludeStateDir() error {
+	path, err := gm.runGit("rev-parse", "--git-path", "info/exclude")
+	if err != nil {
+		return fmt.Errorf("failed to locate info/exclude: %w", err)
+	}
+	path = strings.TrimSpace(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(gm.projectDir, path)
+	}
+
+	pattern := "/" + stateDir + "/"
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read info/exclude: %w", err)
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		content = append(content, '\n')
+	}
+	content = append(content, []byte(pattern+"\n")...)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create info directory: %w", err)
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// runsDir returns the directory holding run records
+func (gm *GitManager) runsDir() string {
+	return filepath.Join(gm.projectDir, stateDir, "runs")
+}
+
+// newRunID returns a time-based run ID not used by an existing record
+func (gm *GitManager) newRunID() string {
+	base := time.Now().Format("20060102-150405")
+	id := base
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(gm.runsDir(), id+".json")); os.IsNotExist(err) {
+			return id
+		}
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// saveRun writes a run record
+func (gm *GitManager) saveRun(run *RunRecord) error {
+	if err := os.MkdirAll(gm.runsDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create runs directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal run record: %w", err)
+	}
+
+	path := filepath.Join(gm.runsDir(), run.ID+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write run record: %w", err)
+	}
+	return nil
+}
+
+// LoadRun reads a run record; an empty ID selects the latest run not yet undone
+func (gm *GitManager) LoadRun(id string) (*RunRecord, error) {
+	if id == "" {
+		runs, err := gm.ListRuns()
+		if err != nil {
+			return nil, err
+		}
+		for i := len(runs) - 1; i >= 0; i-- {
+			if runs[i].UndoneAt == nil {
+				return runs[i], nil
+			}
+		}
+		return nil, ErrRunNotFound
+	}
+
+	data, err := os.ReadFile(filepath.Join(gm.runsDir(), id+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to read run record: %w", err)
+	}
+
+	var run RunRecord
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse run record %s: %w", id, err)
+	}
+	return &run, nil
+}
+
+// ListRuns returns the recorded runs, oldest first
+func (gm *GitManager) ListRuns() ([]*RunRecord, error) {
+	entries, err := os.ReadDir(gm.runsDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read runs directory: %w", err)
+	}
+
+	var runs []*RunRecord
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		run, err := gm.LoadRun(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			gm.logger.Warn().Err(err).Str("file", entry.Name()).Msg("Skipping unreadable run record")
+			continue
+		}
+		runs = append(runs, run)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.Before(runs[j].StartedAt)
+	})
+	return runs, nil
+}
+
+// sameCommits reports whether two commit lists hold the same commits
+func sameCommits(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]bool, len(a))
+	for _, commit := range a {
+		seen[commit] = true
+	}
+	for _, commit := range b {
+		if !seen[commit] {
+			return false
+		}
+	}
+	return true
+}
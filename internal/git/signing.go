@@ -0,0 +1,193 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// SigningFormat selects how commits are signed
+type SigningFormat string
+
+const (
+	SigningNone    SigningFormat = ""        // Unsigned commits
+	SigningOpenPGP SigningFormat = "openpgp" // Armored OpenPGP private key
+	SigningSSH     SigningFormat = "ssh"     // SSH key through ssh-keygen -Y sign
+)
+
+// SigningConfig configures commit signing
+type SigningConfig struct {
+	Format SigningFormat
+	// KeyPath is an armored OpenPGP private key, or an SSH private key or the
+	// public key of a key held by the SSH agent
+	KeyPath string
+	// PassphraseEnv names the environment variable holding the OpenPGP key passphrase
+	PassphraseEnv string
+}
+
+// signer signs the encoded form of a commit
+type signer interface {
+	Sign(payload []byte) (string, error)
+}
+
+// SetSigning makes every commit signed; SigningNone turns signing off
+func (gm *GitManager) SetSigning(cfg SigningConfig) error {
+	switch cfg.Format {
+	case SigningNone:
+		gm.signKey = nil
+		gm.signer = nil
+		return nil
+
+	case SigningOpenPGP:
+		entity, err := readOpenPGPKey(expandHome(cfg.KeyPath), os.Getenv(cfg.PassphraseEnv))
+		if err != nil {
+			return err
+		}
+		gm.signKey = entity
+		gm.signer = &openPGPSigner{entity: entity}
+		return nil
+
+	case SigningSSH:
+		keyPath := expandHome(cfg.KeyPath)
+		if _, err := os.Stat(keyPath); err != nil {
+			return fmt.Errorf("failed to read SSH signing key: %w", err)
+		}
+		gm.signKey = nil
+		gm.signer = &sshSigner{keyPath: keyPath}
+		return nil
+	}
+
+	return fmt.Errorf("unknown signing format: %s", cfg.Format)
+}
+
+// signHead signs the commit at HEAD when signing is on, replacing it with the
+// signed commit. Commits made by the git CLI are signed this way.
+func (gm *GitManager) signHead() error {
+	if gm.signer == nil {
+		return nil
+	}
+
+	head, err := gm.repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	commit, err := gm.repo.CommitObject(head.Hash())
+	if err != nil {
+		return fmt.Errorf("failed to read commit: %w", err)
+	}
+	if commit.PGPSignature != "" {
+		return nil // Already signed
+	}
+
+	unsigned := gm.repo.Storer.NewEncodedObject()
+	if err := commit.EncodeWithoutSignature(unsigned); err != nil {
+		return fmt.Errorf("failed to encode commit: %w", err)
+	}
+	reader, err := unsigned.Reader()
+	if err != nil {
+		return fmt.Errorf("failed to encode commit: %w", err)
+	}
+	payload, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return fmt.Errorf("failed to encode commit: %w", err)
+	}
+
+	signature, err := gm.signer.Sign(payload)
+	if err != nil {
+		return fmt.Errorf("failed to sign commit: %w", err)
+	}
+	commit.PGPSignature = signature
+
+	signed := gm.repo.Storer.NewEncodedObject()
+	if err := commit.Encode(signed); err != nil {
+		return fmt.Errorf("failed to encode signed commit: %w", err)
+	}
+	hash, err := gm.repo.Storer.SetEncodedObject(signed)
+	if err != nil {
+		return fmt.Errorf("failed to store signed commit: %w", err)
+	}
+
+	// head is resolved, so this moves the branch rather than detaching HEAD
+	if err := gm.repo.Storer.SetReference(plumbing.NewHashReference(head.Name(), hash)); err != nil {
+		return fmt.Errorf("failed to update %s: %w", head.Name().Short(), err)
+	}
+	return nil
+}
+
+// readOpenPGPKey reads an armored OpenPGP private key, decrypting it with passphrase
+func readOpenPGPKey(path, passphrase string) (*openpgp.Entity, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open OpenPGP key: %w", err)
+	}
+	defer file.Close()
+
+	entities, err := openpgp.ReadArmoredKeyRing(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenPGP key: %w", err)
+	}
+	entity := entities[0]
+	if entity.PrivateKey == nil {
+		return nil, fmt.Errorf("%s holds no private key", path)
+	}
+
+	if entity.PrivateKey.Encrypted {
+		if passphrase == "" {
+			return nil, fmt.Errorf("OpenPGP key is encrypted and no passphrase is set")
+		}
+		if err := entity.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("failed to decrypt OpenPGP key: %w", err)
+		}
+		for _, subkey := range entity.Subkeys {
+			if subkey.PrivateKey != nil && subkey.PrivateKey.Encrypted {
+				if err := subkey.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+					return nil, fmt.Errorf("failed to decrypt OpenPGP subkey: %w", err)
+				}
+			}
+		}
+	}
+
+	return entity, nil
+}
+
+// openPGPSigner signs with an OpenPGP key
+type openPGPSigner struct {
+	entity *openpgp.Entity
+}
+
+// Sign returns an armored detached signature of payload
+func (s *openPGPSigner) Sign(payload []byte) (string, error) {
+	var b bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&b, s.entity, bytes.NewReader(payload), nil); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// sshSigner signs with an SSH key through ssh-keygen, as git does for gpg.format=ssh
+type sshSigner struct {
+	keyPath string
+}
+
+// Sign returns an SSH signature of payload in the git namespace
+func (s *sshSigner) Sign(payload []byte) (string, error) {
+	cmd := exec.Command("ssh-keygen", "-Y", "sign", "-n", "git", "-f", s.keyPath)
+	cmd.Stdin = bytes.NewReader(payload)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("ssh-keygen: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,121 @@
+package git
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/nohdol/claude-auto/pkg/types"
+)
+
+// alwaysSkippedDirs are never part of a snapshot, even when not ignored
+var alwaysSkippedDirs = map[string]bool{
+	".git":         true,
+	".claude-auto": true,
+	"node_modules": true,
+}
+
+// Snapshot maps worktree files, relative to the project directory, to their content hash
+type Snapshot map[string]plumbing.Hash
+
+// TakeSnapshot hashes every file of the worktree that is not ignored by .gitignore
+func (gm *GitManager) TakeSnapshot() (Snapshot, error) {
+	patterns, err := gitignore.ReadPatterns(gm.worktree.Filesystem, nil)
+	if err != nil {
+		gm.logger.Warn().Err(err).Msg("Failed to read .gitignore patterns")
+	}
+	patterns = append(patterns, gm.worktree.Excludes...)
+	matcher := gitignore.NewMatcher(patterns)
+
+	snapshot := make(Snapshot)
+	err = filepath.WalkDir(gm.projectDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Files may disappear while walking
+		}
+		if path == gm.projectDir {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(gm.projectDir, path)
+		if err != nil {
+			return nil
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if d.IsDir() {
+			if alwaysSkippedDirs[d.Name()] || matcher.Match(strings.Split(relPath, "/"), true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if matcher.Match(strings.Split(relPath, "/"), false) {
+			return nil
+		}
+
+		hash, err := hashFile(path, d)
+		if err != nil {
+			return nil
+		}
+		snapshot[relPath] = hash
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot worktree: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// hashFile computes the git blob hash of a file, or of the target of a symlink
+func hashFile(path string, d fs.DirEntry) (plumbing.Hash, error) {
+	if d.Type()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		return plumbing.ComputeHash(plumbing.BlobObject, []byte(target)), nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return plumbing.ComputeHash(plumbing.BlobObject, content), nil
+}
+
+// DiffSnapshots computes the files added, modified and deleted between two snapshots
+func DiffSnapshots(before, after Snapshot) *types.ChangeSet {
+	changes := &types.ChangeSet{
+		Added:    []string{},
+		Modified: []string{},
+		Deleted:  []string{},
+	}
+
+	for path, hash := range after {
+		previous, existed := before[path]
+		switch {
+		case !existed:
+			changes.Added = append(changes.Added, path)
+		case previous != hash:
+			changes.Modified = append(changes.Modified, path)
+		}
+	}
+
+	for path := range before {
+		if _, exists := after[path]; !exists {
+			changes.Deleted = append(changes.Deleted, path)
+		}
+	}
+
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Modified)
+	sort.Strings(changes.Deleted)
+
+	return changes
+}
@@ -0,0 +1,106 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// TrailerTaskID links a commit to the task that made it
+	TrailerTaskID = "Task-Id"
+	// TrailerRunID links a commit to the run that made it
+	TrailerRunID = "Run-Id"
+	// TrailerModel names the Claude model that wrote the change
+	TrailerModel = "Model"
+	// TrailerCoAuthoredBy credits the human operator when the author is the bot identity
+	TrailerCoAuthoredBy = "Co-authored-by"
+)
+
+// DefaultTrailers are the trailers added to commits unless configured otherwise
+var DefaultTrailers = []string{TrailerTaskID, TrailerRunID}
+
+// knownTrailers maps lower-case trailer names to their canonical keys
+var knownTrailers = map[string]string{
+	"task-id":        TrailerTaskID,
+	"run-id":         TrailerRunID,
+	"model":          TrailerModel,
+	"co-authored-by": TrailerCoAuthoredBy,
+}
+
+// ParseTrailer returns the canonical key of a trailer name such as "run-id"
+func ParseTrailer(name string) (string, error) {
+	if key, ok := knownTrailers[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return key, nil
+	}
+	return "", fmt.Errorf("unknown trailer %q (task-id, run-id, model, co-authored-by)", name)
+}
+
+// SetTrailers selects the trailers added to commits. Task-Id is written on
+// every per-task commit whether or not it is selected, since undoing a single
+// task finds its commits by that trailer.
+func (gm *GitManager) SetTrailers(names []string) error {
+	enabled := make(map[string]bool, len(names))
+	for _, name := range names {
+		key, err := ParseTrailer(name)
+		if err != nil {
+			return err
+		}
+		enabled[key] = true
+	}
+	gm.trailers = enabled
+	return nil
+}
+
+// SetModel sets the model named by the Model trailer
+func (gm *GitManager) SetModel(model string) {
+	gm.model = model
+}
+
+// commitTrailers returns the trailers of a commit: those given by the caller,
+// filtered by the enabled trailers except for Task-Id, followed by the run,
+// model and co-author trailers
+func (gm *GitManager) commitTrailers(trailers []Trailer) []Trailer {
+	enabled := gm.trailers
+	if enabled == nil {
+		enabled = make(map[string]bool)
+		for _, key := range DefaultTrailers {
+			enabled[key] = true
+		}
+	}
+
+	var result []Trailer
+	for _, trailer := range trailers {
+		key, known := knownTrailers[strings.ToLower(trailer.Key)]
+		if !known || key == TrailerTaskID || enabled[key] {
+			result = append(result, trailer)
+		}
+	}
+
+	if enabled[TrailerRunID] && gm.runID != "" {
+		result = append(result, Trailer{Key: TrailerRunID, Value: gm.runID})
+	}
+	if enabled[TrailerModel] && gm.model != "" {
+		result = append(result, Trailer{Key: TrailerModel, Value: gm.model})
+	}
+	if enabled[TrailerCoAuthoredBy] && gm.committer != nil && gm.committer.Email != gm.author.Email {
+		result = append(result, Trailer{
+			Key:   TrailerCoAuthoredBy,
+			Value: fmt.Sprintf("%s <%s>", gm.committer.Name, gm.committer.Email),
+		})
+	}
+
+	return result
+}
+
+// appendTrailers appends trailers to a commit message as a final paragraph
+func appendTrailers(message string, trailers []Trailer) string {
+	if len(trailers) == 0 {
+		return message
+	}
+
+	lines := make([]string, 0, len(trailers))
+	for _, trailer := range trailers {
+		lines = append(lines, fmt.Sprintf("%s: %s", trailer.Key, trailer.Value))
+	}
+	return strings.TrimRight(message, "\n") + "\n\n" + strings.Join(lines, "\n")
+}
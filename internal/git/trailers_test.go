@@ -0,0 +1,89 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nohdol/claude-auto/pkg/types"
+	"github.com/rs/zerolog"
+)
+
+func TestParseTrailer(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"task-id", TrailerTaskID, false},
+		{" Run-Id ", TrailerRunID, false},
+		{"MODEL", TrailerModel, false},
+		{"co-authored-by", TrailerCoAuthoredBy, false},
+		{"signed-off-by", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTrailer(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTrailer(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTrailer(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitTrailers(t *testing.T) {
+	taskTrailer := Trailer{Key: TrailerTaskID, Value: "task-1"}
+	custom := Trailer{Key: "Reviewed-by", Value: "someone"}
+
+	tests := []struct {
+		name     string
+		trailers []string // nil keeps the defaults
+		want     []Trailer
+	}{
+		{"defaults", nil, []Trailer{taskTrailer, custom, {TrailerRunID, "run-1"}}},
+		{"all", []string{"task-id", "run-id", "model", "co-authored-by"}, []Trailer{
+			taskTrailer, custom,
+			{TrailerRunID, "run-1"},
+			{TrailerModel, "sonnet"},
+			{TrailerCoAuthoredBy, "Dev <dev@example.com>"},
+		}},
+		{"task id is always written", []string{"model"}, []Trailer{taskTrailer, custom, {TrailerModel, "sonnet"}}},
+		{"none", []string{}, []Trailer{taskTrailer, custom}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm, err := NewGitManager(t.TempDir(), types.CommitSizeSmall, zerolog.Nop())
+			if err != nil {
+				t.Fatal(err)
+			}
+			gm.runID = "run-1"
+			gm.SetModel("sonnet")
+			gm.SetCommitter("Dev", "dev@example.com")
+			if tt.trailers != nil {
+				if err := gm.SetTrailers(tt.trailers); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got := gm.commitTrailers([]Trailer{taskTrailer, custom})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commitTrailers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendTrailers(t *testing.T) {
+	got := appendTrailers("fix: handle nil config\n\nbody\n", []Trailer{{TrailerTaskID, "task-1"}, {TrailerRunID, "run-1"}})
+	want := "fix: handle nil config\n\nbody\n\nTask-Id: task-1\nRun-Id: run-1"
+	if got != want {
+		t.Errorf("appendTrailers = %q, want %q", got, want)
+	}
+	if got := appendTrailers("fix: x", nil); got != "fix: x" {
+		t.Errorf("appendTrailers without trailers = %q", got)
+	}
+}
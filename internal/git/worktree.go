@@ -0,0 +1,200 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// ErrMergeConflict is returned when a merge stops on conflicting changes
+var ErrMergeConflict = errors.New("merge conflict")
+
+// MergeResult represents the outcome of a merge
+type MergeResult struct {
+	Branch    string
+	Conflicts []string
+}
+
+// EnsureInitialCommit creates an empty initial commit when the repository has no commits.
+// Worktrees and branches need a commit to start from.
+func (gm *GitManager) EnsureInitialCommit() error {
+	if _, err := gm.repo.Head(); err == nil {
+		return nil
+	} else if err != plumbing.ErrReferenceNotFound {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	// Commit an empty tree so files the user staged stay out of the commit
+	tree, err := gm.runGit("mktree")
+	if err != nil {
+		return fmt.Errorf("failed to create empty tree: %w", err)
+	}
+	commit, err := gm.runGit("commit-tree", strings.TrimSpace(tree), "-m", "chore: initial commit")
+	if err != nil {
+		return fmt.Errorf("failed to create initial commit: %w", err)
+	}
+	if _, err := gm.runGit("update-ref", "HEAD", strings.TrimSpace(commit)); err != nil {
+		return fmt.Errorf("failed to update HEAD: %w", err)
+	}
+	return gm.signHead()
+}
+
+// AddWorktree creates a linked worktree at path on a new branch starting at base,
+// or at HEAD when base is empty, and returns a manager for it
+func (gm *GitManager) AddWorktree(path, branch, base string) (*GitManager, error) {
+	args := []string{"worktree", "add", "-b", branch, path}
+	if base != "" {
+		args = append(args, base)
+	}
+	if _, err := gm.runGit(args...); err != nil {
+		return nil, fmt.Errorf("failed to add worktree: %w", err)
+	}
+
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open worktree: %w", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	gm.logger.Debug().
+		Str("path", path).
+		Str("branch", branch).
+		Msg("Worktree added")
+
+	return &GitManager{
+		repo:       repo,
+		worktree:   worktree,
+		commitSize: gm.commitSize,
+		author:     gm.author,
+		logger:     gm.logger,
+		projectDir: path,
+		// Push settings are not inherited; task branches are merged, not pushed
+		messageWriter: gm.messageWriter,
+		committer:     gm.committer,
+		signKey:       gm.signKey,
+		signer:        gm.signer,
+		trailers:      gm.trailers,
+		runID:         gm.runID,
+		model:         gm.model,
+	}, nil
+}
+
+// RemoveWorktree removes a linked worktree and, when branch is set, its branch
+func (gm *GitManager) RemoveWorktree(path, branch string) error {
+	if _, err := gm.runGit("worktree", "remove", "--force", path); err != nil {
+		// The directory may already be gone; drop the stale entry
+		if _, pruneErr := gm.runGit("worktree", "prune"); pruneErr != nil {
+			return fmt.Errorf("failed to remove worktree: %w", err)
+		}
+	}
+
+	if branch != "" {
+		if _, err := gm.runGit("branch", "-D", branch); err != nil {
+			return fmt.Errorf("failed to delete branch: %w", err)
+		}
+	}
+
+	gm.logger.Debug().Str("path", path).Msg("Worktree removed")
+	return nil
+}
+
+// MergeBranch merges a branch into the current branch, fast-forwarding when possible
+// unless noFF is set. On conflict the merge is left in progress for resolution and
+// ErrMergeConflict is returned with the conflicting files.
+func (gm *GitManager) MergeBranch(branch string, noFF bool) (*MergeResult, error) {
+	result := &MergeResult{Branch: branch}
+
+	args := []string{"merge", "--no-edit"}
+	if noFF {
+		args = append(args, "--no-ff")
+	}
+	args = append(args, branch)
+
+	if _, err := gm.runGit(args...); err != nil {
+		conflicts, diffErr := gm.runGit("diff", "--name-only", "--diff-filter=U")
+		if diffErr != nil || strings.TrimSpace(conflicts) == "" {
+			return result, fmt.Errorf("failed to merge %s: %w", branch, err)
+		}
+
+		result.Conflicts = strings.Fields(conflicts)
+		return result, ErrMergeConflict
+	}
+
+	// Fast-forwarded commits are signed already and left as they are
+	if err := gm.signHead(); err != nil {
+		return result, err
+	}
+
+	gm.logger.Info().
+		Str("branch", branch).
+		Bool("no_ff", noFF).
+		Msg("Branch merged")
+
+	gm.pushAfterCommit()
+	return result, nil
+}
+
+// ContinueMerge stages resolved files and concludes a merge in progress
+func (gm *GitManager) ContinueMerge(files []string) error {
+	if len(files) > 0 {
+		args := append([]string{"add", "--"}, files...)
+		if _, err := gm.runGit(args...); err != nil {
+			return fmt.Errorf("failed to stage resolved files: %w", err)
+		}
+	}
+
+	if _, err := gm.runGit("commit", "--no-edit", "--cleanup=strip"); err != nil {
+		return fmt.Errorf("failed to conclude merge: %w", err)
+	}
+	if err := gm.signHead(); err != nil {
+		return err
+	}
+
+	gm.pushAfterCommit()
+	return nil
+}
+
+// AbortMerge aborts a merge in progress
+func (gm *GitManager) AbortMerge() error {
+	if _, err := gm.runGit("merge", "--abort"); err != nil {
+		return fmt.Errorf("failed to abort merge: %w", err)
+	}
+	return nil
+}
+
+// runGit runs the git CLI for operations go-git does not support,
+// committing as the configured author and committer
+func (gm *GitManager) runGit(args ...string) (string, error) {
+	return gm.runGitEnv(nil, args...)
+}
+
+// runGitEnv runs the git CLI with additional environment variables
+func (gm *GitManager) runGitEnv(env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = gm.projectDir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+gm.author.Name,
+		"GIT_AUTHOR_EMAIL="+gm.author.Email,
+		"GIT_COMMITTER_NAME="+gm.committerSignature().Name,
+		"GIT_COMMITTER_EMAIL="+gm.committerSignature().Email,
+	)
+	cmd.Env = append(cmd.Env, env...)
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	if err := cmd.Run(); err != nil {
+		return output.String(), fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(output.String()))
+	}
+	return output.String(), nil
+}
@@ -0,0 +1,133 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nohdol/claude-auto/internal/git"
+	"github.com/nohdol/claude-auto/pkg/types"
+	"github.com/rs/zerolog"
+)
+
+// languageByExtension maps source file extensions to languages
+var languageByExtension = map[string]string{
+	".go":     "Go",
+	".ts":     "TypeScript",
+	".tsx":    "TypeScript",
+	".js":     "JavaScript",
+	".jsx":    "JavaScript",
+	".mjs":    "JavaScript",
+	".cjs":    "JavaScript",
+	".py":     "Python",
+	".rs":     "Rust",
+	".java":   "Java",
+	".kt":     "Kotlin",
+	".rb":     "Ruby",
+	".php":    "PHP",
+	".swift":  "Swift",
+	".dart":   "Dart",
+	".vue":    "Vue",
+	".svelte": "Svelte",
+	".css":    "CSS",
+	".scss":   "SCSS",
+	".html":   "HTML",
+	".sql":    "SQL",
+	".prisma": "Prisma",
+	".sh":     "Shell",
+	".tf":     "Terraform",
+}
+
+// Collector collects project metrics
+type Collector struct {
+	projectDir string
+	gitManager *git.GitManager
+	logger     zerolog.Logger
+}
+
+// NewCollector creates a new metrics collector.
+// gitManager may be nil, in which case commits are not counted.
+func NewCollector(projectDir string, gitManager *git.GitManager, logger zerolog.Logger) *Collector {
+	return &Collector{
+		projectDir: projectDir,
+		gitManager: gitManager,
+		logger:     logger,
+	}
+}
+
+// Collect measures lines of code, commits and test coverage
+func (c *Collector) Collect() *types.ProjectMetrics {
+	metrics := &types.ProjectMetrics{
+		Timestamp:       time.Now(),
+		LinesByLanguage: c.CountLines(),
+	}
+
+	for _, lines := range metrics.LinesByLanguage {
+		metrics.LinesOfCode += lines
+	}
+
+	if c.gitManager != nil {
+		count, err := c.gitManager.CommitCount()
+		if err != nil {
+			c.logger.Warn().Err(err).Msg("Failed to count commits")
+		}
+		metrics.CommitCount = count
+	}
+
+	coverage, sources := CollectCoverage(c.projectDir)
+	metrics.TestCoverage = coverage
+	metrics.CoverageSources = sources
+
+	return metrics
+}
+
+// CountLines counts non-blank lines of code per language
+func (c *Collector) CountLines() map[string]int {
+	lines := make(map[string]int)
+
+	filepath.WalkDir(c.projectDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		// Skip hidden directories and dependencies
+		if d.IsDir() {
+			if path != c.projectDir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules" || d.Name() == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		language, known := languageByExtension[strings.ToLower(filepath.Ext(path))]
+		if !known {
+			return nil
+		}
+
+		lines[language] += countNonBlankLines(path)
+		return nil
+	})
+
+	return lines
+}
+
+// countNonBlankLines counts the non-blank lines of a file
+func countNonBlankLines(path string) int {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+
+	count := 0
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) > 0 {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,231 @@
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// coverageReport is the number of covered and total lines or statements of a report
+type coverageReport struct {
+	covered int
+	total   int
+}
+
+// coverageParser parses a coverage report file
+type coverageParser func(path string) (*coverageReport, error)
+
+// coverageFiles maps well-known coverage report locations to their parser and
+// the tool writing them, in order of preference
+var coverageFiles = []struct {
+	path   string
+	tool   string
+	parser coverageParser
+}{
+	{"coverage.out", "go", parseGoCoverProfile},
+	{"cover.out", "go", parseGoCoverProfile},
+	{"coverage.txt", "go", parseGoCoverProfile},
+	{"coverage/lcov.info", "istanbul", parseLcov},
+	{"lcov.info", "istanbul", parseLcov},
+	{"coverage/coverage-summary.json", "istanbul", parseIstanbulSummary},
+	{"coverage/coverage-final.json", "istanbul", parseIstanbulFinal},
+	{"coverage.xml", "coverage.py", parseCoveragePyXML},
+}
+
+// CollectCoverage parses the coverage reports found in the project and returns
+// the combined coverage percentage and the reports that were used. A tool may
+// write the same run in several formats, so only the first report of each tool
+// is counted.
+func CollectCoverage(projectDir string) (float64, []string) {
+	var combined coverageReport
+	var sources []string
+	counted := make(map[string]bool)
+
+	for _, candidate := range coverageFiles {
+		if counted[candidate.tool] {
+			continue
+		}
+
+		path := filepath.Join(projectDir, filepath.FromSlash(candidate.path))
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		report, err := candidate.parser(path)
+		if err != nil || report.total == 0 {
+			continue
+		}
+
+		counted[candidate.tool] = true
+		combined.covered += report.covered
+		combined.total += report.total
+		sources = append(sources, candidate.path)
+	}
+
+	if combined.total == 0 {
+		return 0, sources
+	}
+	return float64(combined.covered) / float64(combined.total) * 100, sources
+}
+
+// parseGoCoverProfile parses a Go cover profile (go test -coverprofile)
+func parseGoCoverProfile(path string) (*coverageReport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// The same block may be listed several times with -coverpkg
+	type block struct {
+		statements int
+		covered    bool
+	}
+	blocks := make(map[string]*block)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+
+		// Format: name.go:line.column,line.column numberOfStatements count
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		statements, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+
+		b, exists := blocks[fields[0]]
+		if !exists {
+			b = &block{statements: statements}
+			blocks[fields[0]] = b
+		}
+		if count > 0 {
+			b.covered = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &coverageReport{}
+	for _, b := range blocks {
+		report.total += b.statements
+		if b.covered {
+			report.covered += b.statements
+		}
+	}
+	return report, nil
+}
+
+// parseLcov parses an lcov tracefile using the LF/LH line counters
+func parseLcov(path string) (*coverageReport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	report := &coverageReport{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if value, ok := strings.CutPrefix(line, "LF:"); ok {
+			n, _ := strconv.Atoi(value)
+			report.total += n
+		} else if value, ok := strings.CutPrefix(line, "LH:"); ok {
+			n, _ := strconv.Atoi(value)
+			report.covered += n
+		}
+	}
+	return report, scanner.Err()
+}
+
+// parseIstanbulSummary parses an Istanbul json-summary report
+func parseIstanbulSummary(path string) (*coverageReport, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var summary struct {
+		Total struct {
+			Lines struct {
+				Total   int `json:"total"`
+				Covered int `json:"covered"`
+			} `json:"lines"`
+		} `json:"total"`
+	}
+	if err := json.Unmarshal(content, &summary); err != nil {
+		return nil, err
+	}
+
+	return &coverageReport{
+		covered: summary.Total.Lines.Covered,
+		total:   summary.Total.Lines.Total,
+	}, nil
+}
+
+// parseIstanbulFinal parses an Istanbul json report using statement hit counts
+func parseIstanbulFinal(path string) (*coverageReport, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files map[string]struct {
+		S map[string]int `json:"s"`
+	}
+	if err := json.Unmarshal(content, &files); err != nil {
+		return nil, err
+	}
+
+	report := &coverageReport{}
+	for _, file := range files {
+		for _, hits := range file.S {
+			report.total++
+			if hits > 0 {
+				report.covered++
+			}
+		}
+	}
+	return report, nil
+}
+
+// parseCoveragePyXML parses a coverage.py (Cobertura) XML report
+func parseCoveragePyXML(path string) (*coverageReport, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var root struct {
+		XMLName      xml.Name `xml:"coverage"`
+		LineRate     float64  `xml:"line-rate,attr"`
+		LinesValid   int      `xml:"lines-valid,attr"`
+		LinesCovered int      `xml:"lines-covered,attr"`
+	}
+	if err := xml.Unmarshal(content, &root); err != nil {
+		return nil, err
+	}
+
+	if root.LinesValid > 0 {
+		return &coverageReport{covered: root.LinesCovered, total: root.LinesValid}, nil
+	}
+
+	// Older reports only carry the rate; weight them as a single report of 1000 lines
+	return &coverageReport{covered: int(root.LineRate * 1000), total: 1000}, nil
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const (
+	goProfile = `mode: set
+pkg/a.go:1.1,3.2 3 1
+pkg/a.go:5.1,6.2 1 0
+pkg/a.go:1.1,3.2 3 0
+`
+	lcovReport = `SF:src/a.js
+LF:10
+LH:8
+end_of_record
+SF:src/b.js
+LF:10
+LH:2
+end_of_record
+`
+	istanbulSummary = `{"total":{"lines":{"total":20,"covered":10}}}`
+	istanbulFinal   = `{"/src/a.js":{"s":{"0":1,"1":0,"2":3,"3":0}}}`
+	coveragePyXML   = `<?xml version="1.0"?><coverage line-rate="0.5" lines-valid="40" lines-covered="30"></coverage>`
+)
+
+// writeReports writes coverage reports into a new project directory
+func writeReports(t *testing.T, reports map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range reports {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestCoverageParsers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		parser  coverageParser
+		want    coverageReport
+	}{
+		{"go profile", goProfile, parseGoCoverProfile, coverageReport{covered: 3, total: 4}},
+		{"lcov", lcovReport, parseLcov, coverageReport{covered: 10, total: 20}},
+		{"istanbul summary", istanbulSummary, parseIstanbulSummary, coverageReport{covered: 10, total: 20}},
+		{"istanbul final", istanbulFinal, parseIstanbulFinal, coverageReport{covered: 2, total: 4}},
+		{"coverage.py", coveragePyXML, parseCoveragePyXML, coverageReport{covered: 30, total: 40}},
+		{"coverage.py rate only", `<coverage line-rate="0.25"></coverage>`, parseCoveragePyXML, coverageReport{covered: 250, total: 1000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeReports(t, map[string]string{"report": tt.content})
+			got, err := tt.parser(filepath.Join(dir, "report"))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("parse = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectCoverage(t *testing.T) {
+	tests := []struct {
+		name        string
+		reports     map[string]string
+		wantPercent float64
+		wantSources []string
+	}{
+		{"none", nil, 0, nil},
+		{"go", map[string]string{"coverage.out": goProfile}, 75, []string{"coverage.out"}},
+		{
+			"istanbul formats of one run",
+			map[string]string{
+				"coverage/lcov.info":             lcovReport,
+				"coverage/coverage-summary.json": istanbulSummary,
+				"coverage/coverage-final.json":   istanbulFinal,
+			},
+			50, []string{"coverage/lcov.info"},
+		},
+		{
+			"summary without lcov",
+			map[string]string{
+				"coverage/coverage-summary.json": istanbulSummary,
+				"coverage/coverage-final.json":   istanbulFinal,
+			},
+			50, []string{"coverage/coverage-summary.json"},
+		},
+		{
+			"one report per tool",
+			map[string]string{
+				"coverage.out": goProfile,
+				"cover.out":    goProfile,
+				"lcov.info":    lcovReport,
+				"coverage.xml": coveragePyXML,
+			},
+			// (3 + 10 + 30) / (4 + 20 + 40)
+			43.0 / 64 * 100, []string{"coverage.out", "lcov.info", "coverage.xml"},
+		},
+		{"empty report", map[string]string{"coverage.out": "mode: set\n"}, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			percent, sources := CollectCoverage(writeReports(t, tt.reports))
+			if percent != tt.wantPercent {
+				t.Errorf("coverage = %v, want %v", percent, tt.wantPercent)
+			}
+			if !reflect.DeepEqual(sources, tt.wantSources) {
+				t.Errorf("sources = %q, want %q", sources, tt.wantSources)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package refactor
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// skippedDirs hold no packages of the module
+var skippedDirs = map[string]bool{"vendor": true, "testdata": true, "node_modules": true}
+
+// ExportedAPI returns the exported API of the Go module in dir: every exported
+// declaration, struct field and method by its qualified name, mapped to its type.
+// It returns nil when dir is not a Go module. Packages that do not type-check
+// contribute what could be resolved.
+func ExportedAPI(dir string) (map[string]string, error) {
+	module := modulePath(filepath.Join(dir, "go.mod"))
+	if module == "" {
+		return nil, nil
+	}
+
+	imp := newModuleImporter(dir, module)
+	api := make(map[string]string)
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if p != dir && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || skippedDirs[name]) {
+			return filepath.SkipDir
+		}
+		if p != dir && fileExists(filepath.Join(p, "go.mod")) {
+			return filepath.SkipDir // Nested modules have their own API
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return nil
+		}
+		importPath := module
+		if rel != "." {
+			importPath = path.Join(module, filepath.ToSlash(rel))
+		}
+		packageAPI(imp, p, importPath, api)
+		return nil
+	})
+	return api, err
+}
+
+// packageAPI adds the exported API of the package in dir
+func packageAPI(imp *moduleImporter, dir, importPath string, api map[string]string) {
+	pkg := imp.load(dir, importPath)
+	if pkg == nil || pkg.Name() == "main" {
+		return
+	}
+
+	qualifier := types.RelativeTo(pkg)
+	for _, name := range pkg.Scope().Names() {
+		obj := pkg.Scope().Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		key := importPath + "." + name
+
+		typeName, isType := obj.(*types.TypeName)
+		if !isType {
+			api[key] = types.ObjectString(obj, qualifier)
+			continue
+		}
+		if typeName.IsAlias() {
+			api[key] = "type " + name + " = " + types.TypeString(unalias(obj.Type()), qualifier)
+			continue
+		}
+
+		// Unexported fields are not part of the API, so structs are listed by field
+		switch underlying := obj.Type().Underlying().(type) {
+		case *types.Struct:
+			api[key] = "type " + name + " struct"
+			for i := 0; i < underlying.NumFields(); i++ {
+				if field := underlying.Field(i); field.Exported() {
+					api[key+"."+field.Name()] = types.TypeString(field.Type(), qualifier)
+				}
+			}
+		default:
+			api[key] = "type " + name + " " + types.TypeString(underlying, qualifier)
+		}
+
+		if _, isInterface := obj.Type().Underlying().(*types.Interface); isInterface {
+			continue
+		}
+		methods := types.NewMethodSet(types.NewPointer(obj.Type()))
+		for i := 0; i < methods.Len(); i++ {
+			if method := methods.At(i).Obj(); method.Exported() {
+				api[key+"."+method.Name()] = types.TypeString(method.Type(), qualifier)
+			}
+		}
+	}
+}
+
+// moduleImporter type-checks the packages of a module from their source. The
+// source importer resolves module paths from the working directory, so it is
+// only used for packages outside the module.
+type moduleImporter struct {
+	fset     *token.FileSet
+	dir      string // module root
+	module   string // module path
+	fallback types.Importer
+	packages map[string]*types.Package // by import path, nil while checking
+}
+
+// newModuleImporter creates the importer of the module in dir
+func newModuleImporter(dir, module string) *moduleImporter {
+	fset := token.NewFileSet()
+	return &moduleImporter{
+		fset:     fset,
+		dir:      dir,
+		module:   module,
+		fallback: importer.ForCompiler(fset, "source", nil),
+		packages: make(map[string]*types.Package),
+	}
+}
+
+// Import imports a package of the module from its directory and other
+// packages with the source importer
+func (m *moduleImporter) Import(importPath string) (*types.Package, error) {
+	rel, ok := strings.CutPrefix(importPath, m.module)
+	if !ok || (rel != "" && !strings.HasPrefix(rel, "/")) {
+		return m.fallback.Import(importPath)
+	}
+	if pkg := m.load(filepath.Join(m.dir, filepath.FromSlash(rel)), importPath); pkg != nil {
+		return pkg, nil
+	}
+	return nil, fmt.Errorf("cannot import %s", importPath)
+}
+
+// load type-checks the package in dir without its tests. It returns nil when
+// dir holds no package, a file does not parse or the package imports itself.
+func (m *moduleImporter) load(dir, importPath string) *types.Package {
+	if pkg, seen := m.packages[importPath]; seen {
+		return pkg
+	}
+	m.packages[importPath] = nil
+
+	pkgInfo, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil
+	}
+	var files []*ast.File
+	for _, name := range pkgInfo.GoFiles {
+		file, err := parser.ParseFile(m.fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil
+		}
+		files = append(files, file)
+	}
+
+	conf := types.Config{Importer: m, Error: func(error) {}}
+	pkg, _ := conf.Check(importPath, m.fset, files, nil)
+	m.packages[importPath] = pkg
+	return pkg
+}
+
+// unalias returns the type an alias denotes. Newer toolchains represent
+// aliases as types of their own, which print as the alias name.
+func unalias(t types.Type) types.Type {
+	for {
+		alias, ok := t.(interface{ Rhs() types.Type })
+		if !ok {
+			return t
+		}
+		t = alias.Rhs()
+	}
+}
+
+// diffAPI lists the symbols of before that were removed or changed in after,
+// and those added. The renamed symbol is expected to change.
+func diffAPI(before, after map[string]string, r *renamer) (changed, added []string) {
+	packages := apiPackages(before)
+	expected := make(map[string]string, len(before))
+	for key, value := range before {
+		expected[r.Key(key, packages)] = r.Name(value)
+	}
+
+	for key, value := range expected {
+		current, exists := after[key]
+		switch {
+		case !exists:
+			changed = append(changed, "removed "+key)
+		case current != value:
+			changed = append(changed, "changed "+key+": "+value+" → "+current)
+		}
+	}
+	for key := range after {
+		if _, exists := expected[key]; !exists {
+			added = append(added, key)
+		}
+	}
+	return changed, added
+}
+
+// apiPackages returns the import paths of the packages declaring the API.
+// Package-level declarations are listed with their keyword; fields and
+// methods with their type alone.
+func apiPackages(api map[string]string) map[string]bool {
+	packages := make(map[string]bool)
+	for key, value := range api {
+		for _, keyword := range []string{"type ", "func ", "var ", "const "} {
+			if strings.HasPrefix(value, keyword) {
+				packages[key[:strings.LastIndex(key, ".")]] = true
+				break
+			}
+		}
+	}
+	return packages
+}
+
+// renamer renames the symbol of a rename-symbol request. A nil renamer
+// renames nothing.
+type renamer struct {
+	from string         // the symbol, such as ParseConfig or Server.Start
+	to   string         // the symbol after the rename
+	word *regexp.Regexp // the renamed identifier as a whole word or in a test name
+	name string         // the new identifier
+}
+
+// newRenamer creates the renamer of symbol to the new name to, which may
+// repeat the qualifier of the symbol
+func newRenamer(symbol, to string) *renamer {
+	old := symbol[strings.LastIndex(symbol, ".")+1:]
+	name := to[strings.LastIndex(to, ".")+1:]
+	return &renamer{
+		from: symbol,
+		to:   strings.TrimSuffix(symbol, old) + name,
+		word: regexp.MustCompile(`\b((?:Test|Benchmark|Example|Fuzz)(?:\w*_)?)?` + regexp.QuoteMeta(old) + `\b`),
+		name: name,
+	}
+}
+
+// Name replaces the renamed identifier in types and test names, which follow
+// the TestName and TestType_Method conventions
+func (r *renamer) Name(s string) string {
+	if r == nil {
+		return s
+	}
+	return r.word.ReplaceAllString(s, "${1}"+r.name)
+}
+
+// Key renames an API key declared in one of packages when it is the symbol or
+// a field or method of it; other keys sharing the name are left alone
+func (r *renamer) Key(key string, packages map[string]bool) string {
+	if r == nil {
+		return key
+	}
+	for dot := strings.LastIndex(key, "."); dot != -1; dot = strings.LastIndex(key[:dot], ".") {
+		pkg, rest := key[:dot], key[dot+1:]
+		if !packages[pkg] {
+			continue
+		}
+		if rest == r.from || strings.HasPrefix(rest, r.from+".") {
+			return pkg + "." + r.to + rest[len(r.from):]
+		}
+		break
+	}
+	return key
+}
+
+// modulePath returns the module path declared in a go.mod file
+func modulePath(goMod string) string {
+	file, err := os.Open(goMod)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(module), `"`)
+		}
+	}
+	return ""
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,184 @@
+package refactor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// writeModule creates a Go module below a temporary directory
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/lib\n\ngo 1.21\n"
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestExportedAPI(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"lib.go": `package lib
+
+const Version = "1"
+
+type Server struct {
+	Addr string
+	port int
+}
+
+func (s *Server) Start() error { return nil }
+
+func ParseConfig(path string) (*Server, error) { return nil, nil }
+
+func helper() {}
+`,
+		"lib_test.go":      "package lib\n\nfunc TestOnly() {}\n",
+		"store/store.go":   "package store\n\nimport \"example.com/lib\"\n\ntype Alias = lib.Server\n\nvar Default lib.Server\n",
+		"cmd/tool/main.go": "package main\n\nfunc Exported() {}\n\nfunc main() {}\n",
+		"testdata/x/x.go":  "package x\n\nfunc Skipped() {}\n",
+		"nested/go.mod":    "module example.com/nested\n",
+		"nested/nested.go": "package nested\n\nfunc Other() {}\n",
+	})
+
+	api, err := ExportedAPI(dir)
+	if err != nil {
+		t.Fatalf("ExportedAPI: %v", err)
+	}
+
+	want := map[string]string{
+		"example.com/lib.Version":       `const Version untyped string`,
+		"example.com/lib.Server":        "type Server struct",
+		"example.com/lib.Server.Addr":   "string",
+		"example.com/lib.Server.Start":  "func() error",
+		"example.com/lib.ParseConfig":   "func ParseConfig(path string) (*Server, error)",
+		"example.com/lib/store.Alias":   "type Alias = example.com/lib.Server",
+		"example.com/lib/store.Default": "var Default example.com/lib.Server",
+	}
+	if !reflect.DeepEqual(api, want) {
+		t.Errorf("ExportedAPI =\n%q\nwant\n%q", api, want)
+	}
+
+	if api, err := ExportedAPI(t.TempDir()); err != nil || api != nil {
+		t.Errorf("ExportedAPI outside a module = %v, %v, want nil", api, err)
+	}
+}
+
+func TestRenamer(t *testing.T) {
+	packages := map[string]bool{"example.com/lib": true, "example.com/lib/store": true}
+
+	tests := []struct {
+		name   string
+		symbol string
+		to     string
+		key    string
+		want   string
+	}{
+		{"function", "ParseConfig", "LoadConfig", "example.com/lib.ParseConfig", "example.com/lib.LoadConfig"},
+		{"method", "Server.Start", "Run", "example.com/lib.Server.Start", "example.com/lib.Server.Run"},
+		{"qualified new name", "Server.Start", "Server.Run", "example.com/lib.Server.Start", "example.com/lib.Server.Run"},
+		{"field of a renamed type", "Server", "Host", "example.com/lib.Server.Addr", "example.com/lib.Host.Addr"},
+		{"subpackage", "Default", "Fallback", "example.com/lib/store.Default", "example.com/lib/store.Fallback"},
+		{"same name elsewhere", "Start", "Run", "example.com/lib.Server.Start", "example.com/lib.Server.Start"},
+		{"prefix only", "Server", "Host", "example.com/lib.ServerOption", "example.com/lib.ServerOption"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newRenamer(tt.symbol, tt.to).Key(tt.key, packages); got != tt.want {
+				t.Errorf("Key(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+
+	r := newRenamer("Server", "Host")
+	if got := r.Name("func() (*Server, ServerOption)"); got != "func() (*Host, ServerOption)" {
+		t.Errorf("Name = %q, want whole words renamed", got)
+	}
+
+	names := []struct {
+		symbol string
+		to     string
+		name   string
+		want   string
+	}{
+		{"ParseConfig", "LoadConfig", "example.com/lib TestParseConfig/empty", "example.com/lib TestLoadConfig/empty"},
+		{"Server.Start", "Run", "example.com/lib TestServer_Start", "example.com/lib TestServer_Run"},
+		{"Start", "Run", "example.com/lib BenchmarkStart", "example.com/lib BenchmarkRun"},
+		{"Start", "Run", "example.com/lib TestStartup", "example.com/lib TestStartup"},
+		{"Start", "Run", "example.com/lib TestRestart", "example.com/lib TestRestart"},
+	}
+	for _, tt := range names {
+		if got := newRenamer(tt.symbol, tt.to).Name(tt.name); got != tt.want {
+			t.Errorf("renaming %s to %s in %q = %q, want %q", tt.symbol, tt.to, tt.name, got, tt.want)
+		}
+	}
+
+	var none *renamer
+	if none.Name("Server") != "Server" || none.Key("example.com/lib.Server", packages) != "example.com/lib.Server" {
+		t.Error("a nil renamer renamed a symbol")
+	}
+}
+
+func TestDiffAPI(t *testing.T) {
+	before := map[string]string{
+		"example.com/lib.Server":      "type Server struct",
+		"example.com/lib.Server.Addr": "string",
+		"example.com/lib.ParseConfig": "func ParseConfig(path string) (*Server, error)",
+		"example.com/lib.Version":     "const Version untyped string",
+	}
+
+	tests := []struct {
+		name        string
+		after       map[string]string
+		r           *renamer
+		wantChanged []string
+		wantAdded   []string
+	}{
+		{
+			name:  "unchanged",
+			after: before,
+		},
+		{
+			name: "removed, changed and added",
+			after: map[string]string{
+				"example.com/lib.Server":      "type Server struct",
+				"example.com/lib.Server.Addr": "int",
+				"example.com/lib.ParseConfig": "func ParseConfig(path string) (*Server, error)",
+				"example.com/lib.NewServer":   "func NewServer() *Server",
+			},
+			wantChanged: []string{"changed example.com/lib.Server.Addr: string → int", "removed example.com/lib.Version"},
+			wantAdded:   []string{"example.com/lib.NewServer"},
+		},
+		{
+			name: "renamed type",
+			after: map[string]string{
+				"example.com/lib.Host":        "type Host struct",
+				"example.com/lib.Host.Addr":   "string",
+				"example.com/lib.ParseConfig": "func ParseConfig(path string) (*Host, error)",
+				"example.com/lib.Version":     "const Version untyped string",
+			},
+			r: newRenamer("Server", "Host"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed, added := diffAPI(before, tt.after, tt.r)
+			sort.Strings(changed)
+			sort.Strings(added)
+			if !reflect.DeepEqual(changed, tt.wantChanged) || !reflect.DeepEqual(added, tt.wantAdded) {
+				t.Errorf("diffAPI = %q, %q, want %q, %q", changed, added, tt.wantChanged, tt.wantAdded)
+			}
+		})
+	}
+}
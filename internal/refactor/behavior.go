@@ -0,0 +1,204 @@
+package refactor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nohdol/claude-auto/internal/validation"
+	"github.com/nohdol/claude-auto/pkg/types"
+)
+
+// goTestTimeout limits a run of the Go test suite
+const goTestTimeout = 10 * time.Minute
+
+// goTestCheck is the validation check replaced by per-test Go results
+const goTestCheck = "go test"
+
+// Behavior is what a refactoring must preserve: the outcome of every check,
+// the failing tests of failed checks, each Go test and the exported Go API
+type Behavior struct {
+	Checks  map[string]bool     // check name → passed
+	Failing map[string][]string // check name → failing tests
+	Tests   map[string]string   // Go package and test → pass, fail or skip
+	API     map[string]string   // exported Go API, nil outside Go modules
+}
+
+// recordBehavior runs the checks of the project and records their results
+func recordBehavior(ctx context.Context, v *validation.Validator) (*Behavior, error) {
+	dir := v.Dir()
+	behavior := &Behavior{
+		Checks:  make(map[string]bool),
+		Failing: make(map[string][]string),
+	}
+
+	isGo := fileExists(filepath.Join(dir, "go.mod"))
+	if isGo {
+		tests, err := goTests(ctx, dir)
+		if err != nil {
+			return nil, err
+		}
+		behavior.Tests = tests
+
+		if behavior.API, err = ExportedAPI(dir); err != nil {
+			return nil, fmt.Errorf("failed to read the exported API: %w", err)
+		}
+	}
+
+	var checks []validation.CheckCommand
+	for _, check := range v.DetectChecks() {
+		// Go tests are compared one by one instead
+		if isGo && check.Name == goTestCheck {
+			continue
+		}
+		checks = append(checks, check)
+	}
+
+	result := v.Run(ctx, checks)
+	for _, check := range result.Checks {
+		behavior.Checks[check.Name] = check.Passed
+		if !check.Passed {
+			failing := append([]string(nil), check.FailingTests...)
+			sort.Strings(failing)
+			behavior.Failing[check.Name] = failing
+		}
+	}
+	return behavior, nil
+}
+
+// Diff lists how after behaves differently from b, and the exported symbols it
+// adds. The renamed symbol is expected to change.
+func (b *Behavior) Diff(after *Behavior, r *renamer) (changes, added []string) {
+	for name, passed := range b.Checks {
+		switch now, ran := after.Checks[name]; {
+		case !ran:
+			changes = append(changes, fmt.Sprintf("%s no longer runs", name))
+		case now != passed:
+			changes = append(changes, fmt.Sprintf("%s: %s → %s", name, outcome(passed), outcome(now)))
+		case !passed && strings.Join(b.Failing[name], ",") != strings.Join(after.Failing[name], ","):
+			changes = append(changes, fmt.Sprintf("%s: failing tests changed from [%s] to [%s]",
+				name, strings.Join(b.Failing[name], ", "), strings.Join(after.Failing[name], ", ")))
+		}
+	}
+
+	for test, result := range b.Tests {
+		test = r.Name(test)
+		switch now, ran := after.Tests[test]; {
+		case !ran:
+			changes = append(changes, fmt.Sprintf("%s no longer runs", test))
+		case now != result:
+			changes = append(changes, fmt.Sprintf("%s: %s → %s", test, result, now))
+		}
+	}
+	for test := range after.Tests {
+		if _, ran := b.Tests[test]; !ran && !containsRenamed(b.Tests, test, r) {
+			changes = append(changes, fmt.Sprintf("%s is a new test", test))
+		}
+	}
+
+	apiChanges, added := diffAPI(b.API, after.API, r)
+	changes = append(changes, apiChanges...)
+
+	sort.Strings(changes)
+	sort.Strings(added)
+	return changes, added
+}
+
+// containsRenamed reports whether test is the renamed form of a test in tests
+func containsRenamed(tests map[string]string, test string, r *renamer) bool {
+	for old := range tests {
+		if r.Name(old) == test {
+			return true
+		}
+	}
+	return false
+}
+
+// outcome names the result of a check
+func outcome(passed bool) string {
+	if passed {
+		return "passed"
+	}
+	return "failed"
+}
+
+// testEvent is a line of go test -json output
+type testEvent struct {
+	Action  string
+	Package string
+	Test    string
+}
+
+// goTests runs the Go test suite and returns the result of each test, and of
+// each package that failed to build or had no tests
+func goTests(ctx context.Context, dir string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, goTestTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "go", "test", "-json", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CI=true")
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	runErr := cmd.Run()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("go test timed out: %w", ctx.Err())
+	}
+
+	tests := make(map[string]string)
+	scanner := bufio.NewScanner(&stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event testEvent
+		if json.Unmarshal(scanner.Bytes(), &event) != nil {
+			continue
+		}
+		switch event.Action {
+		case "pass", "fail", "skip":
+			key := event.Package
+			if event.Test != "" {
+				key += " " + event.Test
+			}
+			tests[key] = event.Action
+		}
+	}
+
+	// Failing tests exit non-zero; a run without any result did not run at all
+	if runErr != nil && len(tests) == 0 {
+		if _, isExit := runErr.(*exec.ExitError); !isExit {
+			return nil, fmt.Errorf("failed to run go test: %w", runErr)
+		}
+		tests["go test"] = "fail" // The module does not build
+	}
+	return tests, nil
+}
+
+// isTestFile reports whether a path holds tests
+func isTestFile(path string) bool {
+	lower := strings.ToLower(filepath.ToSlash(path))
+	name := filepath.Base(lower)
+	return strings.Contains(name, "_test.") || strings.Contains(name, ".test.") ||
+		strings.Contains(name, ".spec.") || strings.HasPrefix(name, "test_") ||
+		strings.HasPrefix(lower, "test/") || strings.HasPrefix(lower, "tests/") ||
+		strings.Contains(lower, "/tests/") || strings.Contains(lower, "/__tests__/")
+}
+
+// testFiles returns the changed files holding tests
+func testFiles(changes *types.ChangeSet) []string {
+	var files []string
+	for _, file := range changes.Files() {
+		if isTestFile(file) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
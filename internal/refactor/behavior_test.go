@@ -0,0 +1,108 @@
+package refactor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBehaviorDiff(t *testing.T) {
+	before := &Behavior{
+		Checks:  map[string]bool{"lint": true, "npm test": false, "build": true},
+		Failing: map[string][]string{"npm test": {"adds numbers"}},
+		Tests: map[string]string{
+			"example.com/lib TestParseConfig": "pass",
+			"example.com/lib TestServer":      "pass",
+			"example.com/lib TestSlow":        "skip",
+		},
+		API: map[string]string{"example.com/lib.ParseConfig": "func ParseConfig(path string) error"},
+	}
+
+	tests := []struct {
+		name        string
+		after       *Behavior
+		r           *renamer
+		wantChanges []string
+		wantAdded   []string
+	}{
+		{
+			name:  "same behavior",
+			after: before,
+		},
+		{
+			name: "checks and tests changed",
+			after: &Behavior{
+				Checks:  map[string]bool{"lint": false, "npm test": false},
+				Failing: map[string][]string{"lint": {"x"}, "npm test": {"adds numbers", "subtracts"}},
+				Tests: map[string]string{
+					"example.com/lib TestParseConfig": "fail",
+					"example.com/lib TestSlow":        "skip",
+					"example.com/lib TestNew":         "pass",
+				},
+				API: map[string]string{
+					"example.com/lib.ParseConfig": "func ParseConfig(path string) error",
+					"example.com/lib.Load":        "func Load() error",
+				},
+			},
+			wantChanges: []string{
+				"build no longer runs",
+				"example.com/lib TestNew is a new test",
+				"example.com/lib TestParseConfig: pass → fail",
+				"example.com/lib TestServer no longer runs",
+				"lint: passed → failed",
+				"npm test: failing tests changed from [adds numbers] to [adds numbers, subtracts]",
+			},
+			wantAdded: []string{"example.com/lib.Load"},
+		},
+		{
+			name: "renamed symbol and its test",
+			after: &Behavior{
+				Checks:  before.Checks,
+				Failing: before.Failing,
+				Tests: map[string]string{
+					"example.com/lib TestLoadConfig": "pass",
+					"example.com/lib TestServer":     "pass",
+					"example.com/lib TestSlow":       "skip",
+				},
+				API: map[string]string{"example.com/lib.LoadConfig": "func LoadConfig(path string) error"},
+			},
+			r: newRenamer("ParseConfig", "LoadConfig"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, added := before.Diff(tt.after, tt.r)
+			if !reflect.DeepEqual(changes, tt.wantChanges) {
+				t.Errorf("changes = %q\nwant %q", changes, tt.wantChanges)
+			}
+			if !reflect.DeepEqual(added, tt.wantAdded) {
+				t.Errorf("added = %q, want %q", added, tt.wantAdded)
+			}
+		})
+	}
+}
+
+func TestIsTestFile(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"internal/git/git_test.go", true},
+		{"src/app.test.ts", true},
+		{"src/App.Spec.jsx", true},
+		{"pkg/test_utils.py", true},
+		{"tests/conftest.py", true},
+		{"Test/Fixture.cs", true},
+		{"service/tests/helpers.rs", true},
+		{"web/__tests__/button.js", true},
+		{"src/contest.go", false},
+		{"internal/testing/runner.go", false},
+		{"src/latest/app.go", false},
+	}
+
+	for _, tt := range tests {
+		if got := isTestFile(tt.path); got != tt.want {
+			t.Errorf("isTestFile(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package refactor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Operation is a named refactoring
+type Operation struct {
+	Name        string
+	Description string
+	// Requires lists the request fields the operation needs: file, symbol or to
+	Requires []string
+	// Planned operations are split into steps by Claude; the others are one step
+	Planned bool
+	// EditsTests allows the operation to change test files, e.g. to follow a rename
+	EditsTests bool
+	// Goal describes the refactoring of a request to Claude
+	Goal func(r *Request) string
+	// Title summarizes the single step of an operation that is not planned
+	Title func(r *Request) string
+}
+
+// Request selects an operation and what it applies to
+type Request struct {
+	Operation string
+	Dir       string // directory the operation is limited to, relative to the project
+	File      string // file to extract from or split
+	Lines     string // line range to extract, such as "40-75"
+	Symbol    string // function to extract from, or symbol to rename
+	To        string // new name of the renamed symbol
+	Name      string // name of the extracted function
+}
+
+var registry = make(map[string]*Operation)
+
+// register adds an operation to the registry
+func register(op *Operation) {
+	registry[op.Name] = op
+}
+
+// Get returns the operation with the given name
+func Get(name string) (*Operation, bool) {
+	op, exists := registry[strings.ToLower(strings.TrimSpace(name))]
+	return op, exists
+}
+
+// List returns all registered operations sorted by name
+func List() []*Operation {
+	ops := make([]*Operation, 0, len(registry))
+	for _, op := range registry {
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].Name < ops[j].Name
+	})
+	return ops
+}
+
+// Names returns the names of all registered operations
+func Names() []string {
+	var names []string
+	for _, op := range List() {
+		names = append(names, op.Name)
+	}
+	return names
+}
+
+// Validate checks that the request names a known operation and has what it requires
+func (r *Request) Validate() (*Operation, error) {
+	op, exists := Get(r.Operation)
+	if !exists {
+		return nil, fmt.Errorf("unknown operation %q (available: %s)", r.Operation, strings.Join(Names(), ", "))
+	}
+
+	values := map[string]string{"file": r.File, "symbol": r.Symbol, "to": r.To}
+	for _, field := range op.Requires {
+		if strings.TrimSpace(values[field]) == "" {
+			return nil, fmt.Errorf("%s needs --%s", op.Name, field)
+		}
+	}
+	return op, nil
+}
+
+// renamer returns the renamer of a rename-symbol request, nil for other operations
+func (r *Request) renamer() *renamer {
+	if r.Operation != "rename-symbol" {
+		return nil
+	}
+	return newRenamer(strings.TrimSpace(r.Symbol), strings.TrimSpace(r.To))
+}
+
+// scope describes the directory a request is limited to
+func (r *Request) scope() string {
+	if r.Dir == "" || r.Dir == "." {
+		return "프로젝트 전체"
+	}
+	return r.Dir
+}
+
+func init() {
+	register(&Operation{
+		Name:        "extract-function",
+		Description: "Extract a block of a long function into a new function",
+		Requires:    []string{"file"},
+		Goal: func(r *Request) string {
+			target := "긴 함수"
+			switch {
+			case r.Symbol != "" && r.Lines != "":
+				target = fmt.Sprintf("%s 함수의 %s줄", r.Symbol, r.Lines)
+			case r.Symbol != "":
+				target = fmt.Sprintf("%s 함수", r.Symbol)
+			case r.Lines != "":
+				target = fmt.Sprintf("%s줄", r.Lines)
+			}
+			goal := fmt.Sprintf("%s 파일의 %s에서 응집된 코드 블록을 별도의 함수로 추출해 원래 함수를 짧고 읽기 쉽게 만듭니다.", r.File, target)
+			if r.Name != "" {
+				goal += fmt.Sprintf(" 새 함수의 이름은 %s입니다.", r.Name)
+			}
+			return goal
+		},
+		Title: func(r *Request) string {
+			if r.Symbol != "" {
+				return fmt.Sprintf("extract function from %s in %s", r.Symbol, r.File)
+			}
+			return "extract function in " + r.File
+		},
+	})
+
+	register(&Operation{
+		Name:        "split-file",
+		Description: "Split a large file into files by responsibility",
+		Requires:    []string{"file"},
+		Planned:     true,
+		Goal: func(r *Request) string {
+			return fmt.Sprintf("%s 파일을 책임별로 여러 파일로 나눕니다. 나눈 파일은 같은 패키지(모듈)에 두고 공개 API는 그대로 유지합니다.", r.File)
+		},
+	})
+
+	register(&Operation{
+		Name:        "dedupe",
+		Description: "Merge duplicated code into shared functions or types",
+		Planned:     true,
+		Goal: func(r *Request) string {
+			return fmt.Sprintf("%s에서 중복된 코드를 찾아 공통 함수나 타입으로 합칩니다.", r.scope())
+		},
+	})
+
+	register(&Operation{
+		Name:        "rename-symbol",
+		Description: "Rename a symbol and every reference to it",
+		Requires:    []string{"symbol", "to"},
+		EditsTests:  true,
+		Goal: func(r *Request) string {
+			goal := fmt.Sprintf("%s 심볼의 이름을 %s(으)로 바꾸고 테스트와 문서를 포함한 모든 참조를 함께 수정합니다.", r.Symbol, r.To)
+			if r.File != "" {
+				goal += fmt.Sprintf(" 심볼은 %s 파일에 선언되어 있습니다.", r.File)
+			}
+			return goal
+		},
+		Title: func(r *Request) string {
+			return fmt.Sprintf("rename %s to %s", r.Symbol, r.To)
+		},
+	})
+
+	register(&Operation{
+		Name:        "solid",
+		Description: "Apply the SOLID principles to a package",
+		Planned:     true,
+		Goal: func(r *Request) string {
+			return fmt.Sprintf("%s에 SOLID 원칙(단일 책임, 개방-폐쇄, 리스코프 치환, 인터페이스 분리, 의존성 역전)을 적용합니다. 공개 API는 그대로 유지합니다.", r.scope())
+		},
+	})
+}
@@ -0,0 +1,269 @@
+package refactor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nohdol/claude-auto/internal/core"
+	"github.com/nohdol/claude-auto/internal/git"
+	"github.com/nohdol/claude-auto/internal/validation"
+	"github.com/nohdol/claude-auto/pkg/types"
+	"github.com/rs/zerolog"
+)
+
+// defaultMaxSteps limits the steps of a planned operation
+const defaultMaxSteps = 5
+
+// Step is one behavior-preserving change of a refactoring
+type Step struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// StepResult is what happened to a step
+type StepResult struct {
+	Step     Step
+	Applied  bool     // the step was verified and committed
+	Reason   string   // why the step was not applied
+	Changes  []string // how the step changed behavior or tests, when it was rejected
+	Files    []string // files the step changed
+	APIAdded []string // exported symbols the step added
+}
+
+// Result is the outcome of a refactoring
+type Result struct {
+	Steps []StepResult
+}
+
+// Applied counts the steps that were committed
+func (r *Result) Applied() int {
+	applied := 0
+	for _, step := range r.Steps {
+		if step.Applied {
+			applied++
+		}
+	}
+	return applied
+}
+
+// Refactorer applies refactoring operations step by step. Before the first step
+// it records the behavior of the project; each step must leave that behavior
+// unchanged, and is committed on its own or discarded.
+type Refactorer struct {
+	claudeExecutor *core.ClaudeExecutor
+	validator      *validation.Validator
+	gitManager     *git.GitManager
+	logger         zerolog.Logger
+	maxSteps       int
+}
+
+// NewRefactorer creates a refactorer for the project of the validator and git manager
+func NewRefactorer(ce *core.ClaudeExecutor, v *validation.Validator, gm *git.GitManager, logger zerolog.Logger) *Refactorer {
+	return &Refactorer{
+		claudeExecutor: ce,
+		validator:      v,
+		gitManager:     gm,
+		logger:         logger,
+		maxSteps:       defaultMaxSteps,
+	}
+}
+
+// SetMaxSteps limits the steps of planned operations
+func (r *Refactorer) SetMaxSteps(n int) {
+	if n > 0 {
+		r.maxSteps = n
+	}
+}
+
+// Plan splits the refactoring of a request into steps
+func (r *Refactorer) Plan(ctx context.Context, req *Request) ([]Step, error) {
+	op, err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if !op.Planned {
+		title := op.Description
+		if op.Title != nil {
+			title = op.Title(req)
+		}
+		return []Step{{Title: title, Description: op.Goal(req)}}, nil
+	}
+
+	prompt := fmt.Sprintf(`다음 리팩토링을 동작을 바꾸지 않는 작은 단계로 나누어 계획해주세요.
+
+목표: %s
+범위: %s
+
+각 단계는 독립적으로 커밋할 수 있어야 하며, 단계가 끝날 때마다 빌드와 모든 테스트 결과가 이전과 같아야 합니다.
+단계는 최대 %d개이고 효과가 큰 순서로 나열합니다. 코드를 수정하지 말고 계획만 작성하세요.
+
+다른 설명 없이 다음 형식의 JSON만 응답해주세요:
+{
+  "steps": [
+    {"title": "짧은 단계 제목 (커밋 메시지로 사용)", "description": "바꿀 파일과 구체적인 변경 내용"}
+  ]
+}`, op.Goal(req), req.scope(), r.maxSteps)
+
+	response, err := r.claudeExecutor.Execute(ctx, prompt, &core.ClaudeOptions{
+		Role:         "code-refactorer",
+		SystemPrompt: "You are an expert software engineer who plans safe, behavior-preserving refactorings.",
+		WorkDir:      r.gitManager.GetProjectDir(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan refactoring: %w", err)
+	}
+
+	var plan struct {
+		Steps []Step `json:"steps"`
+	}
+	output := response.Output
+	if start, end := strings.Index(output, "{"), strings.LastIndex(output, "}"); start != -1 && end > start {
+		output = output[start : end+1]
+	}
+	if err := json.Unmarshal([]byte(output), &plan); err != nil {
+		return nil, fmt.Errorf("invalid refactoring plan: %w", err)
+	}
+
+	var steps []Step
+	for _, step := range plan.Steps {
+		step.Title = strings.TrimSpace(step.Title)
+		if step.Title == "" {
+			continue
+		}
+		steps = append(steps, step)
+		if len(steps) == r.maxSteps {
+			break
+		}
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("the refactoring plan has no steps")
+	}
+	return steps, nil
+}
+
+// Apply runs the steps of a refactoring, committing each step whose changes
+// keep the recorded behavior and discarding the others
+func (r *Refactorer) Apply(ctx context.Context, req *Request, steps []Step) (*Result, error) {
+	op, err := req.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	baseline, err := recordBehavior(ctx, r.validator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record behavior before refactoring: %w", err)
+	}
+	r.logger.Info().
+		Int("checks", len(baseline.Checks)).
+		Int("tests", len(baseline.Tests)).
+		Int("api", len(baseline.API)).
+		Msg("Recorded behavior before refactoring")
+
+	result := &Result{}
+	for i, step := range steps {
+		stepResult, after, err := r.applyStep(ctx, req, op, baseline, step, i+1, len(steps))
+		if err != nil {
+			return result, err
+		}
+		result.Steps = append(result.Steps, *stepResult)
+		if after != nil {
+			baseline = after
+		}
+	}
+	return result, nil
+}
+
+// applyStep runs one step and verifies it, returning the behavior after an applied step
+func (r *Refactorer) applyStep(ctx context.Context, req *Request, op *Operation, baseline *Behavior, step Step, number, total int) (*StepResult, *Behavior, error) {
+	r.logger.Info().Int("step", number).Str("title", step.Title).Msg("Applying refactoring step")
+	result := &StepResult{Step: step}
+
+	before, err := r.gitManager.TakeSnapshot()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to snapshot project: %w", err)
+	}
+
+	_, err = r.claudeExecutor.Execute(ctx, buildStepPrompt(req, op, step, number, total), &core.ClaudeOptions{
+		Role:         "code-refactorer",
+		SystemPrompt: "You are an expert software engineer who refactors code without changing its behavior.",
+		WorkDir:      r.gitManager.GetProjectDir(),
+	})
+	if err != nil {
+		r.gitManager.DiscardSince(before, "the rejected step")
+		result.Reason = err.Error()
+		return result, nil, nil
+	}
+
+	snapshot, err := r.gitManager.TakeSnapshot()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to snapshot project: %w", err)
+	}
+	changes := git.DiffSnapshots(before, snapshot)
+	result.Files = changes.Files()
+	if changes.IsEmpty() {
+		result.Reason = "no changes were made"
+		return result, nil, nil
+	}
+
+	// Tests define the behavior, so a step may not rewrite them
+	if tests := testFiles(changes); len(tests) > 0 && !op.EditsTests {
+		r.gitManager.DiscardSince(before, "the rejected step")
+		result.Reason = "tests changed"
+		for _, test := range tests {
+			result.Changes = append(result.Changes, "edited "+test)
+		}
+		return result, nil, nil
+	}
+
+	after, err := recordBehavior(ctx, r.validator)
+	if err != nil {
+		r.gitManager.DiscardSince(before, "the rejected step")
+		return nil, nil, fmt.Errorf("failed to record behavior after step %d: %w", number, err)
+	}
+	result.Changes, result.APIAdded = baseline.Diff(after, req.renamer())
+	if len(result.Changes) > 0 {
+		r.gitManager.DiscardSince(before, "the rejected step")
+		result.Reason = "behavior changed"
+		r.logger.Warn().Int("step", number).Strs("changes", result.Changes).Msg("Refactoring step changed behavior, discarded")
+		return result, nil, nil
+	}
+
+	if err := r.gitManager.CommitWithTrailers(result.Files, types.TaskTypeRefactor, commitMessage(step.Title)); err != nil {
+		r.gitManager.DiscardSince(before, "the rejected step")
+		return nil, nil, fmt.Errorf("failed to commit step %d: %w", number, err)
+	}
+	result.Applied = true
+	return result, after, nil
+}
+
+// commitMessage returns the commit message of a step
+func commitMessage(title string) string {
+	title, _, _ = strings.Cut(strings.TrimSpace(title), "\n")
+	if runes := []rune(title); len(runes) > 72 {
+		title = string(runes[:72])
+	}
+	return "refactor: " + title
+}
+
+// buildStepPrompt builds the prompt of one refactoring step
+func buildStepPrompt(req *Request, op *Operation, step Step, number, total int) string {
+	var b strings.Builder
+	b.WriteString("동작을 바꾸지 않는 리팩토링의 한 단계를 수행해주세요.\n\n")
+	fmt.Fprintf(&b, "전체 목표: %s\n범위: %s\n", op.Goal(req), req.scope())
+	fmt.Fprintf(&b, "\n이번 단계 (%d/%d): %s\n%s\n", number, total, step.Title, step.Description)
+
+	b.WriteString("\n규칙:\n")
+	b.WriteString("- 외부에서 관찰할 수 있는 동작을 바꾸지 마세요. 모든 빌드, 검사, 테스트 결과가 이전과 같아야 합니다.\n")
+	if op.EditsTests {
+		b.WriteString("- 테스트는 이름 변경을 따르는 수정만 하세요.\n")
+		b.WriteString("- 이름을 바꾸는 심볼 외에 공개(exported) API의 이름과 시그니처를 바꾸지 마세요.\n")
+	} else {
+		b.WriteString("- 테스트 파일은 수정하지 마세요. 테스트를 바꾼 단계는 거부됩니다.\n")
+		b.WriteString("- 공개(exported) API의 이름과 시그니처를 바꾸지 마세요. 새 공개 심볼은 꼭 필요할 때만 추가하세요.\n")
+	}
+	b.WriteString("- 이번 단계의 범위만 수정하세요. 다음 단계는 따로 실행됩니다.\n")
+	b.WriteString("- git 명령은 실행하지 마세요.\n")
+	return b.String()
+}
@@ -0,0 +1,120 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/nohdol/claude-auto/internal/generators"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+// renderJUnit writes one test suite per file with a failing test case per
+// issue, so CI test reports list the issues. A clean analysis is one passing case.
+func renderJUnit(w io.Writer, info *generators.ProjectInfo, tool Tool) error {
+	byFile := make(map[string][]generators.Issue)
+	for _, issue := range info.Issues {
+		file := issue.File
+		if file == "" {
+			file = "(project)"
+		}
+		byFile[file] = append(byFile[file], issue)
+	}
+
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	doc := junitTestSuites{Name: tool.Name + " analyze"}
+	for _, file := range files {
+		suite := junitTestSuite{Name: file}
+		for _, issue := range byFile[file] {
+			issue := issue
+			suite.Cases = append(suite.Cases, newJUnitCase(file, &issue))
+		}
+		suite.Tests = len(suite.Cases)
+		suite.Failures = len(suite.Cases)
+		doc.Suites = append(doc.Suites, suite)
+	}
+
+	if len(doc.Suites) == 0 {
+		doc.Suites = []junitTestSuite{{
+			Name:  tool.Name,
+			Tests: 1,
+			Cases: []junitTestCase{{Name: "no issues found", ClassName: tool.Name}},
+		}}
+	}
+	for _, suite := range doc.Suites {
+		doc.Tests += suite.Tests
+		doc.Failures += suite.Failures
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// newJUnitCase converts an issue into a failing test case
+func newJUnitCase(file string, issue *generators.Issue) junitTestCase {
+	name := ruleID(issue)
+	if issue.Line > 0 {
+		name = fmt.Sprintf("%s (line %d)", name, issue.Line)
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s\n", issue.Description)
+	if location := issue.Location(); location != "" {
+		fmt.Fprintf(&text, "Location: %s\n", location)
+	}
+	if issue.Suggestion != "" {
+		fmt.Fprintf(&text, "Suggestion: %s\n", issue.Suggestion)
+	}
+	fmt.Fprintf(&text, "Confidence: %.0f%%\n", issue.Confidence*100)
+
+	return junitTestCase{
+		Name:      name,
+		ClassName: file,
+		Failure: &junitFailure{
+			Message: issue.Description,
+			Type:    issue.Severity,
+			Text:    text.String(),
+		},
+	}
+}
@@ -0,0 +1,77 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nohdol/claude-auto/internal/generators"
+)
+
+// severityIcons mark severities in Markdown tables
+var severityIcons = map[string]string{
+	"critical": "🔴",
+	"high":     "🟠",
+	"medium":   "🟡",
+	"low":      "⚪",
+}
+
+// renderMarkdown writes the analysis as a Markdown report
+func renderMarkdown(w io.Writer, info *generators.ProjectInfo) error {
+	var b strings.Builder
+
+	b.WriteString("# Project Analysis Report\n\n")
+	fmt.Fprintf(&b, "- **Type:** %s\n- **Language:** %s\n- **Framework:** %s\n", info.Type, info.Language, info.Framework)
+	if len(info.Changed) > 0 || len(info.Importers) > 0 {
+		fmt.Fprintf(&b, "- **Changed files:** %d (+%d importers)\n", len(info.Changed), len(info.Importers))
+	}
+	if len(info.StaticChecks) > 0 {
+		fmt.Fprintf(&b, "- **Static checks:** %s\n", strings.Join(info.StaticChecks, ", "))
+	}
+
+	counts := make(map[string]int)
+	for _, issue := range info.Issues {
+		counts[issue.Severity]++
+	}
+	b.WriteString("\n## Summary\n\n| Severity | Issues |\n|----------|--------|\n")
+	for _, severity := range severities {
+		fmt.Fprintf(&b, "| %s %s | %d |\n", severityIcons[severity], severity, counts[severity])
+	}
+
+	if len(info.Issues) > 0 {
+		b.WriteString("\n## Issues\n\n| Severity | Type | Location | Rule | Description | Suggestion |\n")
+		b.WriteString("|----------|------|----------|------|-------------|------------|\n")
+		for _, issue := range info.Issues {
+			location := issue.Location()
+			if location != "" {
+				location = "`" + location + "`"
+			}
+			fmt.Fprintf(&b, "| %s %s | %s | %s | %s | %s | %s |\n",
+				severityIcons[issue.Severity], issue.Severity, issue.Type, location,
+				issue.RuleID, markdownCell(issue.Description), markdownCell(issue.Suggestion))
+		}
+	}
+
+	if len(info.Improvements) > 0 {
+		b.WriteString("\n## Suggested Improvements\n\n")
+		for _, improvement := range info.Improvements {
+			fmt.Fprintf(&b, "- %s\n", improvement)
+		}
+	}
+
+	if len(info.Warnings) > 0 {
+		b.WriteString("\n## Warnings\n\n")
+		for _, warning := range info.Warnings {
+			fmt.Fprintf(&b, "- %s\n", warning)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell escapes text for a single Markdown table cell
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(strings.TrimSpace(text), "\n", "<br>")
+}
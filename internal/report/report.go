@@ -0,0 +1,180 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nohdol/claude-auto/internal/detect"
+	"github.com/nohdol/claude-auto/internal/generators"
+)
+
+// Format selects how an analysis is rendered
+type Format string
+
+const (
+	FormatText     Format = "text"     // Human-readable terminal output
+	FormatJSON     Format = "json"     // The analysis as a JSON document
+	FormatSARIF    Format = "sarif"    // SARIF 2.1.0 for code scanning dashboards
+	FormatJUnit    Format = "junit"    // JUnit XML for CI test reports
+	FormatMarkdown Format = "markdown" // Markdown for pull request comments
+)
+
+// Formats lists the supported formats
+var Formats = []Format{FormatText, FormatJSON, FormatSARIF, FormatJUnit, FormatMarkdown}
+
+// severities lists issue severities from most to least severe
+var severities = []string{"critical", "high", "medium", "low"}
+
+// ParseFormat returns the format named by s
+func ParseFormat(s string) (Format, error) {
+	for _, format := range Formats {
+		if strings.EqualFold(s, string(format)) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown format %q (text, json, sarif, junit, markdown)", s)
+}
+
+// Tool describes the program that produced the analysis
+type Tool struct {
+	Name    string
+	Version string
+	URI     string
+}
+
+// Render writes the analysis in the given format
+func Render(w io.Writer, format Format, info *generators.ProjectInfo, tool Tool) error {
+	switch format {
+	case FormatText:
+		return renderText(w, info)
+	case FormatJSON:
+		return renderJSON(w, info)
+	case FormatSARIF:
+		return renderSARIF(w, info, tool)
+	case FormatJUnit:
+		return renderJUnit(w, info, tool)
+	case FormatMarkdown:
+		return renderMarkdown(w, info)
+	}
+	return fmt.Errorf("unknown format: %s", format)
+}
+
+// ParseSeverity validates a severity threshold; "none" and "" never match
+func ParseSeverity(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || s == "none" {
+		return "", nil
+	}
+	for _, severity := range severities {
+		if s == severity {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("unknown severity %q (critical, high, medium, low, none)", s)
+}
+
+// AtLeast returns the issues at or above the severity threshold
+func AtLeast(issues []generators.Issue, threshold string) []generators.Issue {
+	if threshold == "" {
+		return nil
+	}
+
+	var matched []generators.Issue
+	for _, issue := range issues {
+		if severityRank(issue.Severity) <= severityRank(threshold) {
+			matched = append(matched, issue)
+		}
+	}
+	return matched
+}
+
+// severityRank orders severities from 0 (critical) up; unknown severities rank last
+func severityRank(severity string) int {
+	for i, s := range severities {
+		if s == severity {
+			return i
+		}
+	}
+	return len(severities)
+}
+
+// ruleID returns the rule of an issue, falling back to its type
+func ruleID(issue *generators.Issue) string {
+	if issue.RuleID != "" {
+		return issue.RuleID
+	}
+	return issue.Type
+}
+
+// jsonReport is the JSON form of an analysis
+type jsonReport struct {
+	Path         string             `json:"path"`
+	Type         string             `json:"type"`
+	Language     string             `json:"language"`
+	Framework    string             `json:"framework"`
+	Issues       []generators.Issue `json:"issues"`
+	Improvements []string           `json:"improvements"`
+	Warnings     []string           `json:"warnings"`
+	Changed      []string           `json:"changed,omitempty"`
+	Importers    []string           `json:"importers,omitempty"`
+	CachedFiles  int                `json:"cached_files"`
+	StaticChecks []string           `json:"static_checks,omitempty"`
+	Components   []detect.Component `json:"components,omitempty"`
+	Packages     []jsonPackage      `json:"packages,omitempty"`
+	Dependencies []string           `json:"dependencies,omitempty"`
+}
+
+// jsonPackage summarizes a workspace package in the JSON report
+type jsonPackage struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Type      string `json:"type"`
+	Language  string `json:"language"`
+	Framework string `json:"framework"`
+	Issues    int    `json:"issues"`
+}
+
+// renderJSON writes the analysis as an indented JSON document
+func renderJSON(w io.Writer, info *generators.ProjectInfo) error {
+	doc := jsonReport{
+		Path:         info.Path,
+		Type:         info.Type,
+		Language:     info.Language,
+		Framework:    info.Framework,
+		Issues:       info.Issues,
+		Improvements: info.Improvements,
+		Warnings:     info.Warnings,
+		Changed:      info.Changed,
+		Importers:    info.Importers,
+		CachedFiles:  info.CachedFiles,
+		StaticChecks: info.StaticChecks,
+		Components:   info.Components,
+		Dependencies: info.Dependencies,
+	}
+	for _, pkg := range info.Packages {
+		doc.Packages = append(doc.Packages, jsonPackage{
+			Name:      pkg.Package,
+			Path:      pkg.Dir,
+			Type:      pkg.Type,
+			Language:  pkg.Language,
+			Framework: pkg.Framework,
+			Issues:    len(pkg.Issues),
+		})
+	}
+	// Empty lists stay lists for consumers
+	if doc.Issues == nil {
+		doc.Issues = []generators.Issue{}
+	}
+	if doc.Improvements == nil {
+		doc.Improvements = []string{}
+	}
+	if doc.Warnings == nil {
+		doc.Warnings = []string{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
@@ -0,0 +1,220 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nohdol/claude-auto/internal/generators"
+)
+
+var testTool = Tool{Name: "claude-auto", Version: "1.2.3", URI: "https://example.com/claude-auto"}
+
+// testInfo is an analysis with issues sharing a rule, a security issue and an
+// issue without a location
+func testInfo(t *testing.T) *generators.ProjectInfo {
+	return &generators.ProjectInfo{
+		Path:     t.TempDir(),
+		Type:     "api",
+		Language: "go",
+		Issues: []generators.Issue{
+			{Type: "security", Severity: "critical", File: "db/query.go", Line: 12, EndLine: 14, RuleID: "sql-injection", Description: "Query built from input. Use parameters.", Suggestion: "Use placeholders", Confidence: 0.9, Source: "claude"},
+			{Type: "bug", Severity: "medium", File: "main.go", Line: 3, Description: "Error ignored | twice", Confidence: 0.5},
+			{Type: "security", Severity: "high", File: "db/user.go", Line: 7, RuleID: "sql-injection", Description: "Another query", Confidence: 0.7},
+			{Type: "quality", Severity: "low", Description: "No README", Confidence: 0.4},
+		},
+		Improvements: []string{"Add tests"},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, format := range Formats {
+		if got, err := ParseFormat(strings.ToUpper(string(format))); err != nil || got != format {
+			t.Errorf("ParseFormat(%q) = %q, %v", strings.ToUpper(string(format)), got, err)
+		}
+	}
+	if _, err := ParseFormat("html"); err == nil {
+		t.Error("ParseFormat accepted html")
+	}
+}
+
+func TestAtLeast(t *testing.T) {
+	issues := testInfo(t).Issues
+
+	tests := []struct {
+		threshold string
+		want      int
+	}{
+		{"none", 0},
+		{"critical", 1},
+		{"high", 2},
+		{"medium", 3},
+		{"LOW", 4},
+	}
+
+	for _, tt := range tests {
+		threshold, err := ParseSeverity(tt.threshold)
+		if err != nil {
+			t.Fatalf("ParseSeverity(%q): %v", tt.threshold, err)
+		}
+		if got := AtLeast(issues, threshold); len(got) != tt.want {
+			t.Errorf("AtLeast(%q) = %d issues, want %d", tt.threshold, len(got), tt.want)
+		}
+	}
+	if _, err := ParseSeverity("urgent"); err == nil {
+		t.Error("ParseSeverity accepted urgent")
+	}
+}
+
+func TestRenderSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, FormatSARIF, testInfo(t), testTool); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("SARIF version %q with %d runs", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+
+	if run.Tool.Driver.Name != "claude-auto" || run.Tool.Driver.Version != "1.2.3" {
+		t.Errorf("driver = %+v", run.Tool.Driver)
+	}
+	if base := run.OriginalURIBaseIDs[srcRoot].URI; !strings.HasPrefix(base, "file://") || !strings.HasSuffix(base, "/") {
+		t.Errorf("%s base = %q, want a file URI of a directory", srcRoot, base)
+	}
+
+	// Issues sharing a rule ID share a rule; issues without one use their type
+	var ruleIDs []string
+	for _, rule := range run.Tool.Driver.Rules {
+		ruleIDs = append(ruleIDs, rule.ID)
+	}
+	if want := []string{"sql-injection", "bug", "quality"}; !reflect.DeepEqual(ruleIDs, want) {
+		t.Errorf("rules = %q, want %q", ruleIDs, want)
+	}
+	rule := run.Tool.Driver.Rules[0]
+	if rule.ShortDescription.Text != "Query built from input." || rule.Properties["security-severity"] != "9.5" {
+		t.Errorf("security rule = %+v", rule)
+	}
+
+	if len(run.Results) != 4 {
+		t.Fatalf("%d results, want 4", len(run.Results))
+	}
+	first := run.Results[0]
+	if first.Level != "error" || first.RuleIndex != 0 || !strings.Contains(first.Message.Text, "Suggestion: Use placeholders") {
+		t.Errorf("first result = %+v", first)
+	}
+	location := first.Locations[0].PhysicalLocation
+	if location.ArtifactLocation.URI != "db/query.go" || location.ArtifactLocation.URIBaseID != srcRoot ||
+		location.Region == nil || location.Region.StartLine != 12 || location.Region.EndLine != 14 {
+		t.Errorf("first location = %+v", location)
+	}
+	if third := run.Results[2]; third.RuleIndex != 0 || third.Level != "error" {
+		t.Errorf("second sql-injection result = %+v", third)
+	}
+	if last := run.Results[3]; last.Level != "note" || len(last.Locations) != 0 {
+		t.Errorf("project-wide result = %+v", last)
+	}
+}
+
+func TestRenderSARIFWithoutIssues(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, FormatSARIF, &generators.ProjectInfo{Path: t.TempDir()}, testTool); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	// Code scanning rejects null lists
+	if !strings.Contains(buf.String(), `"results": []`) || !strings.Contains(buf.String(), `"rules": []`) {
+		t.Errorf("empty SARIF log lacks empty lists:\n%s", buf.String())
+	}
+}
+
+func TestRenderJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, FormatJUnit, testInfo(t), testTool); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	var doc junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JUnit XML: %v", err)
+	}
+	if doc.Tests != 4 || doc.Failures != 4 {
+		t.Errorf("testsuites tests=%d failures=%d, want 4 and 4", doc.Tests, doc.Failures)
+	}
+
+	// One suite per file, sorted, with project-wide issues under (project)
+	var suites []string
+	for _, suite := range doc.Suites {
+		suites = append(suites, suite.Name)
+	}
+	if want := []string{"(project)", "db/query.go", "db/user.go", "main.go"}; !reflect.DeepEqual(suites, want) {
+		t.Errorf("suites = %q, want %q", suites, want)
+	}
+
+	query := doc.Suites[1].Cases[0]
+	if query.Name != "sql-injection (line 12)" || query.ClassName != "db/query.go" {
+		t.Errorf("test case = %+v", query)
+	}
+	if query.Failure == nil || query.Failure.Type != "critical" ||
+		!strings.Contains(query.Failure.Text, "Location: db/query.go:12-14") ||
+		!strings.Contains(query.Failure.Text, "Confidence: 90%") {
+		t.Errorf("failure = %+v", query.Failure)
+	}
+}
+
+func TestRenderJUnitWithoutIssues(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, FormatJUnit, &generators.ProjectInfo{}, testTool); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	var doc junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JUnit XML: %v", err)
+	}
+	if doc.Tests != 1 || doc.Failures != 0 || doc.Suites[0].Cases[0].Failure != nil {
+		t.Errorf("clean analysis = %+v, want one passing case", doc)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, FormatMarkdown, testInfo(t), testTool); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	for _, want := range []string{
+		"| 🔴 critical | 1 |",
+		"`db/query.go:12-14`",
+		`Error ignored \| twice`,
+		"## Suggested Improvements\n\n- Add tests",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Markdown is missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, FormatJSON, &generators.ProjectInfo{Type: "cli"}, testTool); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	for _, key := range []string{"issues", "improvements", "warnings"} {
+		if list, ok := doc[key].([]interface{}); !ok || len(list) != 0 {
+			t.Errorf("%s = %v, want an empty list", key, doc[key])
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/nohdol/claude-auto/internal/generators"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	// srcRoot is the base of every artifact location
+	srcRoot = "%SRCROOT%"
+)
+
+// sarifLevels maps issue severities to SARIF result levels
+var sarifLevels = map[string]string{
+	"critical": "error",
+	"high":     "error",
+	"medium":   "warning",
+	"low":      "note",
+}
+
+// securitySeverities are the CVSS-like scores code scanning uses to rank security issues
+var securitySeverities = map[string]string{
+	"critical": "9.5",
+	"high":     "8.0",
+	"medium":   "5.5",
+	"low":      "2.0",
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                   `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactURI `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult               `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	ShortDescription     sarifMessage           `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration     `json:"defaultConfiguration"`
+	Properties           map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string                 `json:"ruleId"`
+	RuleIndex  int                    `json:"ruleIndex"`
+	Level      string                 `json:"level"`
+	Message    sarifMessage           `json:"message"`
+	Locations  []sarifLocation        `json:"locations,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifArtifactURI struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+// renderSARIF writes the issues as a SARIF 2.1.0 log with one rule per rule ID
+func renderSARIF(w io.Writer, info *generators.ProjectInfo, tool Tool) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           tool.Name,
+			Version:        tool.Version,
+			InformationURI: tool.URI,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	if root, err := filepath.Abs(info.Path); err == nil {
+		rootURI := url.URL{Scheme: "file", Path: filepath.ToSlash(root) + "/"}
+		run.OriginalURIBaseIDs = map[string]sarifArtifactURI{srcRoot: {URI: rootURI.String()}}
+	}
+
+	ruleIndex := make(map[string]int)
+	for _, issue := range info.Issues {
+		issue := issue
+		id := ruleID(&issue)
+
+		index, ok := ruleIndex[id]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[id] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, newSARIFRule(id, &issue))
+		}
+
+		run.Results = append(run.Results, newSARIFResult(id, index, &issue))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+// newSARIFRule describes a rule from the first issue reported under it
+func newSARIFRule(id string, issue *generators.Issue) sarifRule {
+	rule := sarifRule{
+		ID:                   id,
+		ShortDescription:     sarifMessage{Text: firstSentence(issue.Description)},
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevels[issue.Severity]},
+		Properties:           map[string]interface{}{"tags": []string{issue.Type}},
+	}
+	if issue.Type == "security" {
+		rule.Properties["security-severity"] = securitySeverities[issue.Severity]
+	}
+	return rule
+}
+
+// newSARIFResult converts an issue into a result of rule id
+func newSARIFResult(id string, index int, issue *generators.Issue) sarifResult {
+	message := issue.Description
+	if issue.Suggestion != "" {
+		message += "\n\nSuggestion: " + issue.Suggestion
+	}
+
+	result := sarifResult{
+		RuleID:    id,
+		RuleIndex: index,
+		Level:     sarifLevels[issue.Severity],
+		Message:   sarifMessage{Text: message},
+		Properties: map[string]interface{}{
+			"severity":   issue.Severity,
+			"confidence": issue.Confidence,
+		},
+	}
+	if issue.Source != "" {
+		result.Properties["source"] = issue.Source
+	}
+
+	if issue.File != "" {
+		location := sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: issue.File, URIBaseID: srcRoot},
+		}
+		if issue.Line > 0 {
+			location.Region = &sarifRegion{StartLine: issue.Line, EndLine: issue.EndLine}
+		}
+		result.Locations = []sarifLocation{{PhysicalLocation: location}}
+	}
+	return result
+}
+
+// firstSentence shortens a description to its first sentence or line
+func firstSentence(text string) string {
+	if i := strings.IndexByte(text, '\n'); i != -1 {
+		text = text[:i]
+	}
+	if i := strings.Index(text, ". "); i != -1 {
+		text = text[:i+1]
+	}
+	return text
+}
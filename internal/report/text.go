@@ -0,0 +1,86 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nohdol/claude-auto/internal/generators"
+)
+
+// renderText writes the analysis for the terminal
+func renderText(w io.Writer, info *generators.ProjectInfo) error {
+	fmt.Fprintln(w, "\n📊 Project Analysis Report")
+	fmt.Fprintln(w, "="+strings.Repeat("=", 50))
+	fmt.Fprintf(w, "Project Type: %s\n", info.Type)
+	fmt.Fprintf(w, "Language: %s\n", info.Language)
+	fmt.Fprintf(w, "Framework: %s\n", info.Framework)
+	if len(info.Changed) > 0 || len(info.Importers) > 0 {
+		fmt.Fprintf(w, "Changed Files: %d (+%d importers)\n", len(info.Changed), len(info.Importers))
+	}
+	if len(info.Components) > 0 {
+		fmt.Fprintln(w, "Components:")
+		for _, c := range info.Components {
+			fmt.Fprintf(w, "  - %-9s %s", c.Kind, c.Name)
+			if c.Version != "" {
+				fmt.Fprintf(w, " %s", c.Version)
+			}
+			fmt.Fprintf(w, " (%s, %.0f%%)\n", c.Evidence, c.Confidence*100)
+		}
+	}
+	if len(info.Packages) > 0 {
+		fmt.Fprintln(w, "Packages:")
+		for _, pkg := range info.Packages {
+			fmt.Fprintf(w, "  - %s (%s): %s %s %s, %d issue(s)\n", pkg.Package, pkg.Dir, pkg.Type, pkg.Language, pkg.Framework, len(pkg.Issues))
+		}
+	}
+	if info.CachedFiles > 0 {
+		fmt.Fprintf(w, "Cached Files: %d\n", info.CachedFiles)
+	}
+	if len(info.StaticChecks) > 0 {
+		fmt.Fprintf(w, "Static Checks: %s\n", strings.Join(info.StaticChecks, ", "))
+	}
+
+	if len(info.Issues) > 0 {
+		fmt.Fprintln(w, "\n🐛 Issues Found:")
+		for _, issue := range info.Issues {
+			emoji := "⚠️"
+			if issue.Severity == "critical" {
+				emoji = "🔴"
+			} else if issue.Severity == "high" {
+				emoji = "🟠"
+			}
+			if issue.Source == generators.SourceStatic {
+				fmt.Fprintf(w, "%s [%s, static] %s\n", emoji, issue.Type, issue.Description)
+			} else {
+				fmt.Fprintf(w, "%s [%s] %s\n", emoji, issue.Type, issue.Description)
+			}
+			if location := issue.Location(); location != "" {
+				fmt.Fprintf(w, "   📍 %s", location)
+				if issue.RuleID != "" {
+					fmt.Fprintf(w, " (%s)", issue.RuleID)
+				}
+				fmt.Fprintf(w, " — confidence %.0f%%\n", issue.Confidence*100)
+			}
+			if issue.Suggestion != "" {
+				fmt.Fprintf(w, "   💡 %s\n", issue.Suggestion)
+			}
+		}
+	}
+
+	if len(info.Improvements) > 0 {
+		fmt.Fprintln(w, "\n💡 Suggested Improvements:")
+		for _, improvement := range info.Improvements {
+			fmt.Fprintf(w, "  - %s\n", improvement)
+		}
+	}
+
+	if len(info.Warnings) > 0 {
+		fmt.Fprintln(w, "\n⚠️  Analysis Warnings:")
+		for _, warning := range info.Warnings {
+			fmt.Fprintf(w, "  - %s\n", warning)
+		}
+	}
+
+	return nil
+}
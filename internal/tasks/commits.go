@@ -0,0 +1,259 @@
+package tasks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nohdol/claude-auto/internal/git"
+	"github.com/nohdol/claude-auto/pkg/types"
+)
+
+// TrailerTaskID is the commit trailer attributing a commit to a task
+const TrailerTaskID = git.TrailerTaskID
+
+// ContextScope is the context key listing the files a task is meant to change,
+// comma-separated and relative to the project directory
+const ContextScope = "scope"
+
+// SetGitManager enables committing task changes as tasks complete.
+// Atomic commits each file, small commits each task and medium commits each batch.
+func (pe *ParallelExecutor) SetGitManager(gm *git.GitManager, commitSize types.CommitSize) {
+	pe.gitManager = gm
+	pe.commitSize = commitSize
+}
+
+// SetDiscardFailed enables restoring the files a task changed in the project
+// directory when it fails, so a failed task leaves nothing to commit. Tasks
+// sharing the directory may change files at the same time; a task with a
+// scope only has the files of its scope restored.
+func (pe *ParallelExecutor) SetDiscardFailed(enabled bool) {
+	pe.discardFailed = enabled
+}
+
+// SetAutoCommit turns committing task changes off while the git manager is
+// still used to track and discard the changes of tasks
+func (pe *ParallelExecutor) SetAutoCommit(enabled bool) {
+	pe.noCommits = !enabled
+}
+
+// discardTask restores the files a failed task changed since before,
+// reporting whether any file was restored
+func (pe *ParallelExecutor) discardTask(task *types.Task, before git.Snapshot) bool {
+	if !pe.discardFailed || pe.gitManager == nil || before == nil {
+		return false
+	}
+	after := pe.takeSnapshot(pe.gitManager)
+	if after == nil {
+		return false
+	}
+
+	files := git.DiffSnapshots(before, after).Files()
+	if scope := task.Context[ContextScope]; scope != "" {
+		inScope := make(map[string]bool)
+		for _, file := range strings.Split(scope, ",") {
+			inScope[file] = true
+		}
+		scoped := files[:0]
+		for _, file := range files {
+			if inScope[file] {
+				scoped = append(scoped, file)
+			}
+		}
+		files = scoped
+	}
+	if len(files) == 0 {
+		return false
+	}
+
+	pe.commitMu.Lock()
+	defer pe.commitMu.Unlock()
+
+	if err := pe.gitManager.RestoreFiles(files); err != nil {
+		pe.logger.Warn().Err(err).Str("task_id", task.ID).Msg("Failed to discard changes of failed task")
+		return false
+	}
+	pe.logger.Info().
+		Str("task_id", task.ID).
+		Strs("files", files).
+		Msg("Discarded changes of failed task")
+	return true
+}
+
+// takeSnapshot snapshots the worktree of gm, returning nil when changes are not tracked
+func (pe *ParallelExecutor) takeSnapshot(gm *git.GitManager) git.Snapshot {
+	if gm == nil {
+		return nil
+	}
+
+	snapshot, err := gm.TakeSnapshot()
+	if err != nil {
+		pe.logger.Warn().Err(err).Msg("Failed to snapshot worktree")
+		return nil
+	}
+	return snapshot
+}
+
+// recordChanges attaches the files changed in the worktree of gm since before to the task
+// and returns them with the snapshot taken after the task
+func (pe *ParallelExecutor) recordChanges(task *types.Task, gm *git.GitManager, before git.Snapshot) (*types.ChangeSet, git.Snapshot) {
+	after := pe.takeSnapshot(gm)
+	if before == nil || after == nil {
+		return nil, nil
+	}
+
+	changes := git.DiffSnapshots(before, after)
+	pe.taskManager.SetTaskChanges(task.ID, changes)
+	return changes, after
+}
+
+// commitTask commits the changes of a completed task according to the commit size.
+// Tasks sharing the project directory may change the same files, so only files
+// that differ from what was last committed are included.
+func (pe *ParallelExecutor) commitTask(task *types.Task, changes *types.ChangeSet, after git.Snapshot) {
+	if pe.gitManager == nil || pe.noCommits || changes.IsEmpty() || after == nil {
+		return
+	}
+
+	pe.commitMu.Lock()
+	if pe.commitSize == types.CommitSizeMedium {
+		pe.pending = append(pe.pending, pendingCommit{task: task, changes: changes, after: after})
+		pe.commitMu.Unlock()
+		return
+	}
+	files := pe.uncommittedFiles(changes, after)
+	pe.commitMu.Unlock()
+
+	messages := pe.commitMessages(pe.gitManager, task, files)
+
+	// A merge in progress must not pick up the commit
+	pe.integrateMu.Lock()
+	defer pe.integrateMu.Unlock()
+	pe.commitMu.Lock()
+	defer pe.commitMu.Unlock()
+	pe.markCommitted(pe.commitFiles(pe.gitManager, task, files, messages), after)
+}
+
+// commitMessages writes the messages of the commits of a task's files, one per
+// file for atomic commits. They are written before the commit lock is taken,
+// since Claude may take minutes to write them.
+func (pe *ParallelExecutor) commitMessages(gm *git.GitManager, task *types.Task, files []string) []string {
+	if pe.commitSize != types.CommitSizeAtomic {
+		return []string{gm.CommitMessage(files, task.Type)}
+	}
+
+	messages := make([]string, len(files))
+	for i, file := range files {
+		messages[i] = gm.CommitMessage([]string{file}, task.Type)
+	}
+	return messages
+}
+
+// commitFiles commits the files of a task with the messages of commitMessages,
+// one by one for atomic commits and together otherwise, returning the files
+// that were committed
+func (pe *ParallelExecutor) commitFiles(gm *git.GitManager, task *types.Task, files, messages []string) []string {
+	trailer := git.Trailer{Key: TrailerTaskID, Value: task.ID}
+
+	if pe.commitSize != types.CommitSizeAtomic {
+		if err := gm.CommitWithTrailers(files, task.Type, messages[0], trailer); err != nil {
+			pe.logger.Warn().Err(err).Str("task_id", task.ID).Msg("Failed to commit task changes")
+			return nil
+		}
+		return files
+	}
+
+	var committed []string
+	for i, file := range files {
+		if err := gm.CommitWithTrailers([]string{file}, task.Type, messages[i], trailer); err != nil {
+			pe.logger.Warn().Err(err).Str("task_id", task.ID).Str("file", file).Msg("Failed to commit file")
+			continue
+		}
+		committed = append(committed, file)
+	}
+	return committed
+}
+
+// commitPending commits the changes of all tasks completed in a batch together
+func (pe *ParallelExecutor) commitPending(batch int) {
+	pe.commitMu.Lock()
+	pending := pe.pending
+	pe.pending = nil
+	if len(pending) == 0 {
+		pe.commitMu.Unlock()
+		return
+	}
+
+	var files []string
+	var trailers []git.Trailer
+	taskType := pending[0].task.Type
+	seen := make(map[string]bool)
+
+	for _, p := range pending {
+		for _, file := range pe.uncommittedFiles(p.changes, p.after) {
+			if !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+		trailers = append(trailers, git.Trailer{Key: TrailerTaskID, Value: p.task.ID})
+		if p.task.Type != taskType {
+			taskType = ""
+		}
+	}
+
+	pe.commitMu.Unlock()
+
+	// The latest snapshot reflects the final content of every file in the batch
+	after := pending[len(pending)-1].after
+	message := fmt.Sprintf("feat: complete phase %d (%d tasks)", batch, len(pending))
+	if taskType != "" {
+		message = pe.gitManager.CommitMessage(files, taskType)
+	}
+
+	pe.integrateMu.Lock()
+	defer pe.integrateMu.Unlock()
+	pe.commitMu.Lock()
+	defer pe.commitMu.Unlock()
+
+	if err := pe.gitManager.CommitWithTrailers(files, taskType, message, trailers...); err != nil {
+		pe.logger.Warn().Err(err).Int("batch", batch).Msg("Failed to commit batch changes")
+	} else {
+		pe.markCommitted(files, after)
+	}
+}
+
+// uncommittedFiles returns the changed files whose content differs from the last commit
+func (pe *ParallelExecutor) uncommittedFiles(changes *types.ChangeSet, after git.Snapshot) []string {
+	var files []string
+	for _, file := range changes.Files() {
+		committed, wasCommitted := pe.committed[file]
+		current, exists := after[file]
+		if wasCommitted != exists || committed != current {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// markCommitted records the content of committed files
+func (pe *ParallelExecutor) markCommitted(files []string, after git.Snapshot) {
+	if pe.committed == nil {
+		pe.committed = make(git.Snapshot)
+	}
+	for _, file := range files {
+		if hash, exists := after[file]; exists {
+			pe.committed[file] = hash
+		} else {
+			delete(pe.committed, file)
+		}
+	}
+}
+
+// pendingCommit is a completed task waiting for its batch commit
+type pendingCommit struct {
+	task    *types.Task
+	changes *types.ChangeSet
+	after   git.Snapshot
+}
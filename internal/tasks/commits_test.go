@@ -0,0 +1,46 @@
+package tasks
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/nohdol/claude-auto/internal/git"
+	"github.com/nohdol/claude-auto/pkg/types"
+	"github.com/rs/zerolog"
+)
+
+func TestUncommittedFiles(t *testing.T) {
+	hash := func(s string) plumbing.Hash { return plumbing.ComputeHash(plumbing.BlobObject, []byte(s)) }
+
+	pe := NewParallelExecutor(NewTaskManager(zerolog.Nop()), nil, 1, zerolog.Nop())
+	pe.committed = git.Snapshot{
+		"same.go":    hash("same"),
+		"changed.go": hash("old"),
+		"removed.go": hash("removed"),
+	}
+	after := git.Snapshot{
+		"same.go":    hash("same"),
+		"changed.go": hash("new"),
+		"added.go":   hash("added"),
+	}
+	changes := &types.ChangeSet{
+		Added:    []string{"added.go"},
+		Modified: []string{"same.go", "changed.go"},
+		Deleted:  []string{"removed.go"},
+	}
+
+	want := []string{"added.go", "changed.go", "removed.go"}
+	if got := pe.uncommittedFiles(changes, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("uncommittedFiles = %q, want %q", got, want)
+	}
+
+	// Once committed, the same changes have nothing left to commit
+	pe.markCommitted(want, after)
+	if got := pe.uncommittedFiles(changes, after); len(got) != 0 {
+		t.Errorf("uncommittedFiles after markCommitted = %q, want none", got)
+	}
+	if _, exists := pe.committed["removed.go"]; exists {
+		t.Error("markCommitted kept a deleted file")
+	}
+}
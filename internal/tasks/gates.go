@@ -0,0 +1,169 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nohdol/claude-auto/internal/core"
+	"github.com/nohdol/claude-auto/internal/validation"
+	"github.com/nohdol/claude-auto/pkg/types"
+)
+
+// ContextFixFor is the context key linking a fix task to the task it repairs
+const ContextFixFor = "fix_for"
+
+// SetValidator enables validation gates after each task and at the end of a run.
+// Failing gates spawn up to maxFixAttempts fix tasks that receive the error output.
+func (pe *ParallelExecutor) SetValidator(v *validation.Validator, maxFixAttempts int) {
+	pe.validator = v
+	pe.maxFixAttempts = maxFixAttempts
+}
+
+// runGates validates the project after a task with v.
+// A task may not break a gate that passed before it ran; gates that were
+// already failing are left to the end-of-run validation.
+func (pe *ParallelExecutor) runGates(ctx context.Context, task *types.Task, options *core.ClaudeOptions, v *validation.Validator) error {
+	baseline := pe.getBaseline()
+
+	regressions := func(result *types.ValidationResult) []types.Check {
+		var failed []types.Check
+		for _, check := range validation.FailedChecks(result) {
+			if baseline == nil || checkPassed(baseline, check.Name) {
+				failed = append(failed, check)
+			}
+		}
+		return failed
+	}
+
+	result := pe.fixUntilPassing(ctx, task, task.Type, options, v, v.Validate(ctx), regressions)
+	pe.taskManager.SetTaskValidation(task.ID, result)
+
+	// A failed task must not lower the bar for the tasks after it
+	if failed := regressions(result); len(failed) > 0 {
+		var names []string
+		for _, check := range failed {
+			names = append(names, check.Name)
+		}
+		return fmt.Errorf("validation gates failed: %s", strings.Join(names, ", "))
+	}
+
+	pe.setBaseline(result)
+	return nil
+}
+
+// validateRun runs all gates at the end of a run, fixing failures where possible
+func (pe *ParallelExecutor) validateRun(ctx context.Context) *types.ValidationResult {
+	pe.logger.Info().Msg("Running end-of-run validation")
+
+	options := pe.buildClaudeOptions(&types.Task{Type: types.TaskTypeTesting})
+	result := pe.fixUntilPassing(ctx, nil, types.TaskTypeTesting, options, pe.validator, pe.validator.Validate(ctx), validation.FailedChecks)
+
+	pe.logger.Info().
+		Bool("passed", result.Passed).
+		Int("checks", len(result.Checks)).
+		Msg("End-of-run validation finished")
+
+	return result
+}
+
+// fixUntilPassing spawns fix tasks while the gate selector reports failures
+func (pe *ParallelExecutor) fixUntilPassing(
+	ctx context.Context,
+	origin *types.Task,
+	taskType types.TaskType,
+	options *core.ClaudeOptions,
+	v *validation.Validator,
+	result *types.ValidationResult,
+	gate func(*types.ValidationResult) []types.Check,
+) *types.ValidationResult {
+	autoFixed := make(map[string]bool)
+
+	for attempt := 1; attempt <= pe.maxFixAttempts; attempt++ {
+		failed := gate(result)
+		if len(failed) == 0 {
+			break
+		}
+
+		originPrompt := ""
+		priority := 0
+		if origin != nil {
+			originPrompt = origin.Prompt
+			priority = origin.Priority
+		}
+
+		fixTask := pe.taskManager.CreateTask(taskType, priority, validation.BuildFixPrompt(originPrompt, failed))
+		if origin != nil {
+			pe.taskManager.SetTaskContext(fixTask.ID, ContextFixFor, origin.ID)
+		}
+		pe.taskManager.UpdateTaskStatus(fixTask.ID, types.TaskStatusInProgress)
+
+		pe.logger.Info().
+			Str("task_id", fixTask.ID).
+			Int("attempt", attempt).
+			Int("failed_checks", len(failed)).
+			Msg("Spawning fix task for failed gates")
+
+		before := pe.takeSnapshot(pe.gitManager)
+		response, err := pe.claudeExecutor.Execute(ctx, fixTask.Prompt, options)
+		if err != nil {
+			// A crashed fix leaves no finished changes to validate or commit
+			pe.taskManager.SetTaskError(fixTask.ID, err)
+			pe.discardTask(fixTask, before)
+			break
+		}
+		changes, after := pe.recordChanges(fixTask, pe.gitManager, before)
+
+		// Fixes for a task's gates are committed with the task itself
+		if origin == nil {
+			pe.commitTask(fixTask, changes, after)
+		}
+		pe.taskManager.SetTaskResult(fixTask.ID, response.Output)
+
+		next := v.Validate(ctx)
+		for _, check := range failed {
+			if checkPassed(next, check.Name) {
+				autoFixed[check.Name] = true
+			}
+		}
+		result = next
+
+		if len(gate(result)) == 0 {
+			pe.taskManager.UpdateTaskStatus(fixTask.ID, types.TaskStatusCompleted)
+		} else {
+			pe.taskManager.SetTaskError(fixTask.ID, fmt.Errorf("gates still failing after fix"))
+		}
+	}
+
+	for i := range result.Checks {
+		if autoFixed[result.Checks[i].Name] && result.Checks[i].Passed {
+			result.Checks[i].AutoFixed = true
+		}
+	}
+
+	return result
+}
+
+// getBaseline returns the last known validation result
+func (pe *ParallelExecutor) getBaseline() *types.ValidationResult {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+	return pe.baseline
+}
+
+// setBaseline records the last known validation result
+func (pe *ParallelExecutor) setBaseline(result *types.ValidationResult) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+	pe.baseline = result
+}
+
+// checkPassed checks if the named check passed in a result
+func checkPassed(result *types.ValidationResult, name string) bool {
+	for _, check := range result.Checks {
+		if check.Name == name {
+			return check.Passed
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package tasks
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nohdol/claude-auto/internal/core"
+	"github.com/nohdol/claude-auto/internal/validation"
+	"github.com/nohdol/claude-auto/pkg/types"
+	"github.com/rs/zerolog"
+)
+
+// newGoProject writes a Go module whose build passes and returns its validator
+func newGoProject(t *testing.T) (string, *validation.Validator) {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module example.com/gates\n\ngo 1.21\n",
+		"main.go": "package main\n\nfunc main() {}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir, validation.NewValidator(dir, time.Minute, zerolog.Nop())
+}
+
+// breakBuild replaces main.go with code that does not compile
+func breakBuild(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() { undefined() }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunGatesRejectsRegression(t *testing.T) {
+	dir, v := newGoProject(t)
+	ctx := context.Background()
+
+	tm := NewTaskManager(zerolog.Nop())
+	pe := NewParallelExecutor(tm, nil, 1, zerolog.Nop())
+	// No fix attempts, so Claude is never run
+	pe.SetValidator(v, 0)
+	pe.setBaseline(v.Validate(ctx))
+
+	task := tm.CreateTask(types.TaskTypeBackend, 1, "add a handler")
+	breakBuild(t, dir)
+
+	err := pe.runGates(ctx, task, &core.ClaudeOptions{}, v)
+	if err == nil || !strings.Contains(err.Error(), "go build") {
+		t.Fatalf("runGates after breaking the build = %v, want a go build failure", err)
+	}
+	if task.Validation == nil || task.Validation.Passed {
+		t.Errorf("task validation = %+v, want the failed result", task.Validation)
+	}
+	// The failed task does not become the new baseline
+	if !checkPassed(pe.getBaseline(), "go build") {
+		t.Error("a failed task lowered the baseline")
+	}
+}
+
+func TestRunGatesIgnoresFailingBaseline(t *testing.T) {
+	dir, v := newGoProject(t)
+	ctx := context.Background()
+
+	tm := NewTaskManager(zerolog.Nop())
+	pe := NewParallelExecutor(tm, nil, 1, zerolog.Nop())
+	pe.SetValidator(v, 0)
+
+	// Gates already failing before the task are left to the end of the run
+	breakBuild(t, dir)
+	pe.setBaseline(v.Validate(ctx))
+
+	task := tm.CreateTask(types.TaskTypeBackend, 1, "add a handler")
+	if err := pe.runGates(ctx, task, &core.ClaudeOptions{}, v); err != nil {
+		t.Fatalf("runGates with an already failing build = %v, want nil", err)
+	}
+}
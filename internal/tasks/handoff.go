@@ -0,0 +1,274 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nohdol/claude-auto/internal/core"
+	"github.com/nohdol/claude-auto/pkg/types"
+)
+
+// Context keys holding the structured handoff data of a completed task. The
+// files of a handoff are the changes recorded for the task.
+const (
+	ContextHandoffInterfaces = "handoff.interfaces"
+	ContextHandoffNotes      = "handoff.notes"
+)
+
+const (
+	// handoffBudget is the maximum size of the upstream section added to a prompt
+	handoffBudget = 4000
+	// summarizeThreshold is the output size above which Claude summarizes the result
+	summarizeThreshold = 8000
+	// maxHandoffItems limits each handoff list
+	maxHandoffItems = 30
+)
+
+// Handoff is the structured summary of a task result passed downstream
+type Handoff struct {
+	TaskID     string         `json:"-"`
+	TaskType   types.TaskType `json:"-"`
+	Files      []string       `json:"-"`
+	Interfaces []string       `json:"interfaces"`
+	Notes      []string       `json:"notes"`
+}
+
+var (
+	endpointPattern   = regexp.MustCompile(`\b(GET|POST|PUT|PATCH|DELETE)\s+(/[\w/:{}.\-]*)`)
+	interfacePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^func\s+(\([^)]*\)\s*)?[A-Z]\w*\(.*`),
+		regexp.MustCompile(`^type\s+[A-Z]\w*\s+.*`),
+		regexp.MustCompile(`^export\s+(default\s+)?(async\s+)?(function|const|class|interface|type)\s+\w+.*`),
+		regexp.MustCompile(`^(async\s+)?def\s+[a-z]\w*\(.*`),
+		regexp.MustCompile(`^class\s+[A-Z]\w*.*`),
+	}
+	notePrefixes = []string{"note:", "notes:", "참고", "주의", "todo", "환경 변수", "env:"}
+)
+
+// buildHandoff extracts the handoff of a task, summarizing large outputs with Claude
+// run in the task's working directory
+func (pe *ParallelExecutor) buildHandoff(ctx context.Context, task *types.Task, output, workDir string) *Handoff {
+	// Review results are read by the caller, never by downstream tasks
+	if len(output) > summarizeThreshold && task.Type != types.TaskTypeReview {
+		handoff, err := pe.summarizeOutput(ctx, task, output, workDir)
+		if err == nil {
+			return handoff
+		}
+		pe.logger.Warn().
+			Err(err).
+			Str("task_id", task.ID).
+			Msg("Summarization pass failed, falling back to local extraction")
+	}
+
+	return extractHandoff(output)
+}
+
+// summarizeOutput asks Claude for a structured summary of a large task result
+func (pe *ParallelExecutor) summarizeOutput(ctx context.Context, task *types.Task, output, workDir string) (*Handoff, error) {
+	if len(output) > summarizeThreshold*4 {
+		// Cut on a rune boundary so Korean output stays valid UTF-8
+		cut := summarizeThreshold * 4
+		for cut > 0 && !utf8.RuneStart(output[cut]) {
+			cut--
+		}
+		output = output[:cut]
+	}
+
+	prompt := fmt.Sprintf(`다음은 "%s" 작업의 실행 결과입니다. 후속 작업에 전달할 요약을 작성해주세요.
+
+반드시 다음 JSON 형식으로만 응답해주세요:
+{
+    "interfaces": ["공개 인터페이스 (API 엔드포인트, 함수 시그니처, 타입 등)"],
+    "notes": ["후속 작업이 알아야 할 사항 (환경 변수, 제약 조건 등)"]
+}
+
+실행 결과:
+%s`, task.ID, output)
+
+	response, err := pe.claudeExecutor.Execute(ctx, prompt, &core.ClaudeOptions{
+		Role:    "technical-writer",
+		WorkDir: workDir,
+		// The result is in the prompt, so Claude needs no tools; the = form keeps
+		// the variadic flag from taking the prompt as a tool name
+		AdditionalFlags: []string{"--tools="},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jsonStr := extractJSONObject(response.Output)
+	if jsonStr == "" {
+		return nil, fmt.Errorf("no JSON found in summary")
+	}
+
+	var handoff Handoff
+	if err := json.Unmarshal([]byte(jsonStr), &handoff); err != nil {
+		return nil, fmt.Errorf("failed to parse summary: %w", err)
+	}
+
+	handoff.Interfaces = limitItems(dedupe(handoff.Interfaces))
+	handoff.Notes = limitItems(dedupe(handoff.Notes))
+	return &handoff, nil
+}
+
+// extractHandoff scrapes public interfaces and notes from a task result
+func extractHandoff(output string) *Handoff {
+	handoff := &Handoff{}
+
+	for _, rawLine := range strings.Split(output, "\n") {
+		line := strings.TrimSpace(strings.Trim(strings.TrimSpace(rawLine), "-*`"))
+		if line == "" {
+			continue
+		}
+
+		for _, match := range endpointPattern.FindAllString(line, -1) {
+			handoff.Interfaces = append(handoff.Interfaces, match)
+		}
+		for _, pattern := range interfacePatterns {
+			if pattern.MatchString(line) {
+				handoff.Interfaces = append(handoff.Interfaces, strings.TrimSuffix(line, "{"))
+				break
+			}
+		}
+
+		lower := strings.ToLower(line)
+		for _, prefix := range notePrefixes {
+			if strings.HasPrefix(lower, prefix) {
+				handoff.Notes = append(handoff.Notes, line)
+				break
+			}
+		}
+	}
+
+	handoff.Interfaces = limitItems(dedupe(handoff.Interfaces))
+	handoff.Notes = limitItems(dedupe(handoff.Notes))
+	return handoff
+}
+
+// storeHandoff stores the handoff in the task context
+func (pe *ParallelExecutor) storeHandoff(task *types.Task, handoff *Handoff) {
+	values := map[string][]string{
+		ContextHandoffInterfaces: handoff.Interfaces,
+		ContextHandoffNotes:      handoff.Notes,
+	}
+
+	for key, items := range values {
+		if err := pe.taskManager.SetTaskContext(task.ID, key, strings.Join(items, "\n")); err != nil {
+			pe.logger.Warn().Err(err).Str("task_id", task.ID).Msg("Failed to store handoff")
+		}
+	}
+}
+
+// buildPrompt adds the handoff of each completed dependency to the task prompt
+func (pe *ParallelExecutor) buildPrompt(task *types.Task) string {
+	var handoffs []*Handoff
+	for _, depID := range task.Dependencies {
+		if handoff, exists := pe.taskManager.GetHandoff(depID); exists {
+			handoffs = append(handoffs, handoff)
+		}
+	}
+	if len(handoffs) == 0 {
+		return task.Prompt
+	}
+
+	// Split the budget evenly between dependencies
+	perDep := handoffBudget / len(handoffs)
+
+	var section strings.Builder
+	section.WriteString("\n\n선행 작업 결과 (이미 완료됨, 아래 내용과 일치하도록 구현하세요):\n")
+	for _, handoff := range handoffs {
+		section.WriteString(formatHandoff(handoff, perDep))
+	}
+
+	return task.Prompt + section.String()
+}
+
+// formatHandoff renders the handoff of a dependency within a size budget
+func formatHandoff(handoff *Handoff, budget int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n### %s (%s)\n", handoff.TaskID, handoff.TaskType)
+
+	sections := []struct {
+		title string
+		items []string
+	}{
+		{"공개 인터페이스", handoff.Interfaces},
+		{"생성/수정된 파일", handoff.Files},
+		{"참고 사항", handoff.Notes},
+	}
+
+	for _, sec := range sections {
+		if len(sec.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "%s:\n", sec.title)
+		for _, item := range sec.items {
+			line := fmt.Sprintf("- %s\n", item)
+			if b.Len()+len(line) > budget {
+				b.WriteString("- ...\n")
+				return b.String()
+			}
+			b.WriteString(line)
+		}
+	}
+
+	return b.String()
+}
+
+// splitHandoff splits a handoff list stored in a task context
+func splitHandoff(value string) []string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, "\n")
+}
+
+// extractJSONObject returns the first balanced JSON object in the output
+func extractJSONObject(output string) string {
+	startIdx := strings.Index(output, "{")
+	if startIdx == -1 {
+		return ""
+	}
+
+	braceCount := 0
+	for i := startIdx; i < len(output); i++ {
+		switch output[i] {
+		case '{':
+			braceCount++
+		case '}':
+			braceCount--
+			if braceCount == 0 {
+				return output[startIdx : i+1]
+			}
+		}
+	}
+	return ""
+}
+
+// dedupe removes duplicates while keeping the first occurrence order
+func dedupe(items []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		result = append(result, item)
+	}
+	return result
+}
+
+// limitItems caps the length of a handoff list
+func limitItems(items []string) []string {
+	if len(items) > maxHandoffItems {
+		items = items[:maxHandoffItems]
+	}
+	return items
+}
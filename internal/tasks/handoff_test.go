@@ -0,0 +1,138 @@
+package tasks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nohdol/claude-auto/pkg/types"
+	"github.com/rs/zerolog"
+)
+
+func TestExtractHandoff(t *testing.T) {
+	output := strings.Join([]string{
+		"I created internal/auth/handler.go and updated go.mod.",
+		"- `POST /api/login` returns a token",
+		"func NewHandler(store Store) *Handler {",
+		"type Session struct {",
+		"func helper() {}",
+		"Note: JWT_SECRET must be set",
+		"참고: 마이그레이션을 먼저 실행해야 합니다",
+		"POST /api/login is rate limited",
+	}, "\n")
+
+	handoff := extractHandoff(output)
+
+	wantInterfaces := []string{
+		"POST /api/login",
+		"func NewHandler(store Store) *Handler",
+		"type Session struct",
+	}
+	if !reflect.DeepEqual(handoff.Interfaces, wantInterfaces) {
+		t.Errorf("Interfaces = %q, want %q", handoff.Interfaces, wantInterfaces)
+	}
+	wantNotes := []string{"Note: JWT_SECRET must be set", "참고: 마이그레이션을 먼저 실행해야 합니다"}
+	if !reflect.DeepEqual(handoff.Notes, wantNotes) {
+		t.Errorf("Notes = %q, want %q", handoff.Notes, wantNotes)
+	}
+	// Files come from the recorded changes, never from the prose
+	if len(handoff.Files) != 0 {
+		t.Errorf("Files = %q, want none", handoff.Files)
+	}
+}
+
+func TestGetHandoffUsesRecordedChanges(t *testing.T) {
+	tm := NewTaskManager(zerolog.Nop())
+	task := tm.CreateTask(types.TaskTypeBackend, 1, "add login")
+	tm.SetTaskChanges(task.ID, &types.ChangeSet{
+		Added:    []string{"auth/handler.go"},
+		Modified: []string{"go.mod"},
+		Deleted:  []string{"auth/old.go"},
+	})
+	tm.SetTaskContext(task.ID, ContextHandoffInterfaces, "POST /api/login\nfunc NewHandler()")
+
+	if _, exists := tm.GetHandoff(task.ID); exists {
+		t.Fatal("GetHandoff returned the handoff of a task that has not completed")
+	}
+	tm.UpdateTaskStatus(task.ID, types.TaskStatusCompleted)
+
+	handoff, exists := tm.GetHandoff(task.ID)
+	if !exists {
+		t.Fatal("GetHandoff found no handoff for a completed task")
+	}
+	wantFiles := []string{"auth/handler.go", "go.mod", "auth/old.go"}
+	if !reflect.DeepEqual(handoff.Files, wantFiles) {
+		t.Errorf("Files = %q, want %q", handoff.Files, wantFiles)
+	}
+	wantInterfaces := []string{"POST /api/login", "func NewHandler()"}
+	if !reflect.DeepEqual(handoff.Interfaces, wantInterfaces) {
+		t.Errorf("Interfaces = %q, want %q", handoff.Interfaces, wantInterfaces)
+	}
+	if handoff.TaskID != task.ID || handoff.TaskType != types.TaskTypeBackend {
+		t.Errorf("handoff is for %s (%s), want %s (%s)", handoff.TaskID, handoff.TaskType, task.ID, types.TaskTypeBackend)
+	}
+}
+
+func TestBuildPrompt(t *testing.T) {
+	tm := NewTaskManager(zerolog.Nop())
+	api := tm.CreateTask(types.TaskTypeBackend, 1, "add login API")
+	ui := tm.CreateTask(types.TaskTypeFrontend, 2, "add login page")
+	if err := tm.AddDependency(ui.ID, api.ID); err != nil {
+		t.Fatal(err)
+	}
+	pe := NewParallelExecutor(tm, nil, 1, zerolog.Nop())
+
+	// Incomplete dependencies hand nothing off
+	if got := pe.buildPrompt(ui); got != ui.Prompt {
+		t.Errorf("buildPrompt before the dependency completed = %q, want the task prompt", got)
+	}
+
+	tm.SetTaskChanges(api.ID, &types.ChangeSet{Added: []string{"api/login.go"}})
+	tm.SetTaskContext(api.ID, ContextHandoffInterfaces, "POST /api/login")
+	tm.UpdateTaskStatus(api.ID, types.TaskStatusCompleted)
+
+	got := pe.buildPrompt(ui)
+	for _, want := range []string{ui.Prompt, api.ID, "POST /api/login", "api/login.go"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("buildPrompt = %q, missing %q", got, want)
+		}
+	}
+}
+
+func TestFormatHandoffBudget(t *testing.T) {
+	handoff := &Handoff{TaskID: "task-1", TaskType: types.TaskTypeBackend}
+	for i := 0; i < maxHandoffItems; i++ {
+		handoff.Interfaces = append(handoff.Interfaces, strings.Repeat("x", 50))
+	}
+
+	const budget = 300
+	got := formatHandoff(handoff, budget)
+	if len(got) > budget+len("- ...\n") {
+		t.Errorf("formatHandoff is %d bytes, over the budget of %d", len(got), budget)
+	}
+	if !strings.HasSuffix(got, "- ...\n") {
+		t.Errorf("formatHandoff = %q, want a truncation marker", got)
+	}
+}
+
+func TestExtractJSONObject(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"plain", `{"notes":["a"]}`, `{"notes":["a"]}`},
+		{"surrounded", "요약입니다:\n```json\n{\"a\":{\"b\":1}}\n```\n끝", `{"a":{"b":1}}`},
+		{"first of two", `{"a":1} {"b":2}`, `{"a":1}`},
+		{"unbalanced", `{"a":{"b":1}`, ""},
+		{"none", "no json here", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractJSONObject(tt.output); got != tt.want {
+				t.Errorf("extractJSONObject(%q) = %q, want %q", tt.output, got, tt.want)
+			}
+		})
+	}
+}
@@ -3,10 +3,13 @@ package tasks
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/nohdol/claude-auto/internal/core"
+	"github.com/nohdol/claude-auto/internal/git"
+	"github.com/nohdol/claude-auto/internal/validation"
 	"github.com/nohdol/claude-auto/pkg/types"
 	"github.com/rs/zerolog"
 	"golang.org/x/sync/errgroup"
@@ -20,6 +23,22 @@ type ParallelExecutor struct {
 	logger         zerolog.Logger
 	mu             sync.RWMutex
 	activeWorkers  int
+	validator      *validation.Validator
+	maxFixAttempts int
+	baseline       *types.ValidationResult
+	gitManager     *git.GitManager
+	commitSize     types.CommitSize
+	workDir        string
+	worktrees      bool
+	discardFailed  bool
+	noCommits      bool
+	// Commits are serialized; committed tracks the content of committed files
+	commitMu  sync.Mutex
+	committed git.Snapshot
+	pending   []pendingCommit
+	// integrateMu serializes merges into the project directory, which may wait
+	// on Claude; it is taken before commitMu
+	integrateMu sync.Mutex
 }
 
 // NewParallelExecutor creates a new parallel executor
@@ -58,6 +77,25 @@ func (pe *ParallelExecutor) ExecuteTasks(ctx context.Context) (*types.ExecutionR
 		Tasks:      orderedTasks,
 	}
 
+	// Record which gates pass before any task runs
+	if pe.validator != nil {
+		pe.setBaseline(pe.validator.Validate(ctx))
+	}
+
+	// Changes present before the run are not attributed to any task
+	pe.committed = pe.takeSnapshot(pe.gitManager)
+
+	// Task branches need a commit to start from
+	if pe.isolated() {
+		if err := pe.gitManager.EnsureInitialCommit(); err != nil {
+			pe.logger.Warn().Err(err).Msg("Worktrees unavailable, tasks share the project directory")
+			pe.worktrees = false
+		}
+	}
+	if pe.maxWorkers > 1 && pe.batchWorkers() == 1 {
+		pe.logger.Warn().Msg("Tasks share the project directory and are committed, running them one at a time")
+	}
+
 	// Execute batches
 	for i, batch := range batches {
 		pe.logger.Info().
@@ -73,10 +111,20 @@ func (pe *ParallelExecutor) ExecuteTasks(ctx context.Context) (*types.ExecutionR
 			// Continue with other batches even if one fails
 		}
 
+		// Medium commits group the tasks of a batch
+		pe.commitPending(i + 1)
+
 		// Update report
 		pe.updateReport(report)
 	}
 
+	// Validate the whole project once all tasks ran
+	if pe.validator != nil {
+		report.Validation = pe.validateRun(ctx)
+		pe.commitPending(len(batches) + 1)
+		pe.updateReport(report)
+	}
+
 	report.EndTime = time.Now()
 	report.Duration = report.EndTime.Sub(report.StartTime)
 
@@ -85,11 +133,11 @@ func (pe *ParallelExecutor) ExecuteTasks(ctx context.Context) (*types.ExecutionR
 
 // executeBatch executes a batch of tasks in parallel
 func (pe *ParallelExecutor) executeBatch(ctx context.Context, batch []*types.Task) error {
-	// Create error group with context
-	g, ctx := errgroup.WithContext(ctx)
+	// A failing task does not cancel the rest of its batch
+	var g errgroup.Group
 
 	// Create semaphore for worker limit
-	sem := make(chan struct{}, pe.maxWorkers)
+	sem := make(chan struct{}, pe.batchWorkers())
 
 	for _, task := range batch {
 		task := task // Capture loop variable
@@ -112,6 +160,16 @@ func (pe *ParallelExecutor) executeBatch(ctx context.Context, batch []*types.Tas
 	return g.Wait()
 }
 
+// batchWorkers returns how many tasks of a batch run at once. Tasks sharing the
+// project directory cannot tell their changes apart, so they run one at a time
+// when each task is committed with its own message and Task-Id trailer.
+func (pe *ParallelExecutor) batchWorkers() int {
+	if pe.gitManager != nil && !pe.noCommits && !pe.isolated() {
+		return 1
+	}
+	return pe.maxWorkers
+}
+
 // executeTask executes a single task
 func (pe *ParallelExecutor) executeTask(ctx context.Context, task *types.Task) error {
 	pe.logger.Info().
@@ -127,8 +185,27 @@ func (pe *ParallelExecutor) executeTask(ctx context.Context, task *types.Task) e
 	// Build Claude options based on task type
 	options := pe.buildClaudeOptions(task)
 
-	// Execute with Claude
-	response, err := pe.claudeExecutor.Execute(ctx, task.Prompt, options)
+	// Run the task in its own worktree when tasks are isolated
+	var ws *workspace
+	if pe.isolated() {
+		var err error
+		if ws, err = pe.openWorkspace(task); err != nil {
+			pe.logger.Warn().Err(err).Str("task_id", task.ID).Msg("Failed to create worktree, using project directory")
+		} else {
+			defer pe.closeWorkspace(ws)
+			options.WorkDir = taskDir(ws.dir, task)
+		}
+	}
+
+	// Snapshot the worktree to track the files this task changes
+	gm := pe.gitManager
+	if ws != nil {
+		gm = ws.gitManager
+	}
+	before := pe.takeSnapshot(gm)
+
+	// Execute with Claude, handing off the results of completed dependencies
+	response, err := pe.claudeExecutor.Execute(ctx, pe.buildPrompt(task), options)
 	if err != nil {
 		pe.logger.Error().
 			Err(err).
@@ -137,6 +214,9 @@ func (pe *ParallelExecutor) executeTask(ctx context.Context, task *types.Task) e
 
 		// Update task with error
 		pe.taskManager.SetTaskError(task.ID, err)
+		if ws == nil {
+			pe.discardTask(task, before)
+		}
 		return err
 	}
 
@@ -145,11 +225,77 @@ func (pe *ParallelExecutor) executeTask(ctx context.Context, task *types.Task) e
 		return fmt.Errorf("failed to set task result: %w", err)
 	}
 
+	// Record the structured handoff for downstream tasks
+	pe.storeHandoff(task, pe.buildHandoff(ctx, task, response.Output, options.WorkDir))
+
+	// A task whose changes are discarded on failure is validated in its worktree,
+	// so a failing task never reaches the project directory
+	validatedInWorkspace := ws != nil && pe.discardFailed && pe.validator != nil
+	if validatedInWorkspace {
+		if err := pe.runGates(ctx, task, options, pe.workspaceValidator(ws)); err != nil {
+			pe.logger.Error().
+				Err(err).
+				Str("task_id", task.ID).
+				Msg("Task failed validation, discarding its worktree")
+
+			pe.taskManager.SetTaskError(task.ID, err)
+			return err
+		}
+	}
+
+	// Merge the task branch back before validating the integrated project
+	validatedSerially := false
+	if ws != nil {
+		var err error
+		if validatedSerially, err = pe.integrate(ctx, task, ws, before, options); err != nil {
+			pe.logger.Error().
+				Err(err).
+				Str("task_id", task.ID).
+				Msg("Failed to integrate task")
+
+			pe.taskManager.SetTaskError(task.ID, err)
+			return err
+		}
+		// Fixes for its gates are made in the project directory
+		options.WorkDir = taskDir(pe.workDir, task)
+		before = pe.takeSnapshot(pe.gitManager)
+	}
+
+	// Only complete the task when its validation gates pass
+	if pe.validator != nil && !validatedInWorkspace && !validatedSerially {
+		if err := pe.runGates(ctx, task, options, pe.validator); err != nil {
+			pe.logger.Error().
+				Err(err).
+				Str("task_id", task.ID).
+				Msg("Task failed validation")
+
+			pe.taskManager.SetTaskError(task.ID, err)
+			if ws == nil && pe.discardTask(task, before) {
+				// The failed validation saw the discarded changes
+				pe.setBaseline(pe.validator.Validate(ctx))
+			}
+			return err
+		}
+	}
+
+	// Record what the task changed in the project directory, including fixes for
+	// its gates, before downstream tasks can read its handoff
+	var changes *types.ChangeSet
+	after := pe.takeSnapshot(pe.gitManager)
+	if before != nil && after != nil {
+		changes = git.DiffSnapshots(before, after)
+		if ws == nil {
+			pe.taskManager.SetTaskChanges(task.ID, changes)
+		}
+	}
+
 	// Update status to completed
 	if err := pe.taskManager.UpdateTaskStatus(task.ID, types.TaskStatusCompleted); err != nil {
 		return fmt.Errorf("failed to update task status: %w", err)
 	}
 
+	pe.commitTask(task, changes, after)
+
 	pe.logger.Info().
 		Str("task_id", task.ID).
 		Dur("duration", response.Duration).
@@ -160,7 +306,7 @@ func (pe *ParallelExecutor) executeTask(ctx context.Context, task *types.Task) e
 
 // buildClaudeOptions builds Claude execution options based on task type
 func (pe *ParallelExecutor) buildClaudeOptions(task *types.Task) *core.ClaudeOptions {
-	options := &core.ClaudeOptions{}
+	options := &core.ClaudeOptions{WorkDir: taskDir(pe.workDir, task)}
 
 	// Set role based on task type
 	switch task.Type {
@@ -182,11 +328,25 @@ func (pe *ParallelExecutor) buildClaudeOptions(task *types.Task) *core.ClaudeOpt
 	case types.TaskTypeDevOps:
 		options.Role = "devops-engineer"
 		options.SystemPrompt = "You are a DevOps engineer specializing in CI/CD and infrastructure automation."
+	case types.TaskTypeReview:
+		options.Role = "code-reviewer"
+		options.SystemPrompt = "You are an expert code reviewer and software architect."
+	case types.TaskTypeFix:
+		options.Role = "bug-fixer"
+		options.SystemPrompt = "You are an expert software engineer who fixes reported issues with minimal, focused changes."
 	}
 
 	return options
 }
 
+// taskDir returns the directory a task runs in: its package below base, or base
+func taskDir(base string, task *types.Task) string {
+	if task.WorkDir == "" {
+		return base
+	}
+	return filepath.Join(base, task.WorkDir)
+}
+
 // buildDependencyGraph builds a dependency graph from tasks
 func (pe *ParallelExecutor) buildDependencyGraph(tasks []*types.Task) map[string][]string {
 	graph := make(map[string][]string)
@@ -263,6 +423,20 @@ func (pe *ParallelExecutor) updateReport(report *types.ExecutionReport) {
 	failedTasks := pe.taskManager.GetTasksByStatus(types.TaskStatusFailed)
 	skippedTasks := pe.taskManager.GetTasksByStatus(types.TaskStatusSkipped)
 
+	// Include fix tasks spawned during the run
+	if allTasks := pe.taskManager.GetAllTasks(); len(allTasks) > len(report.Tasks) {
+		known := make(map[string]bool)
+		for _, task := range report.Tasks {
+			known[task.ID] = true
+		}
+		for _, task := range allTasks {
+			if !known[task.ID] {
+				report.Tasks = append(report.Tasks, task)
+			}
+		}
+		report.TotalTasks = len(report.Tasks)
+	}
+
 	report.CompletedTasks = len(completedTasks)
 	report.FailedTasks = len(failedTasks)
 	report.SkippedTasks = len(skippedTasks)
@@ -0,0 +1,44 @@
+package tasks
+
+import (
+	"testing"
+
+	"github.com/nohdol/claude-auto/internal/git"
+	"github.com/nohdol/claude-auto/pkg/types"
+	"github.com/rs/zerolog"
+)
+
+func TestBatchWorkers(t *testing.T) {
+	gm, err := git.NewGitManager(t.TempDir(), types.CommitSizeSmall, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		git        bool
+		autoCommit bool
+		worktrees  bool
+		want       int
+	}{
+		{"no git", false, true, false, 3},
+		{"shared directory with commits", true, true, false, 1},
+		{"shared directory without commits", true, false, false, 3},
+		{"worktrees with commits", true, true, true, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pe := NewParallelExecutor(NewTaskManager(zerolog.Nop()), nil, 3, zerolog.Nop())
+			if tt.git {
+				pe.SetGitManager(gm, types.CommitSizeSmall)
+			}
+			pe.SetAutoCommit(tt.autoCommit)
+			pe.SetWorktrees(tt.worktrees)
+
+			if got := pe.batchWorkers(); got != tt.want {
+				t.Errorf("batchWorkers() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
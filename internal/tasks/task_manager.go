@@ -1,7 +1,9 @@
 package tasks
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -71,6 +73,111 @@ func (tm *TaskManager) AddDependency(taskID, dependsOnID string) error {
 	return nil
 }
 
+// DeclareArtifacts declares the artifacts a task produces and consumes
+func (tm *TaskManager) DeclareArtifacts(taskID string, produces, consumes []string) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	task, exists := tm.tasks[taskID]
+	if !exists {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+
+	declared := make(map[string]bool)
+	for _, artifact := range append(append([]string{}, task.Produces...), task.Consumes...) {
+		declared[artifact] = true
+	}
+
+	var errs []error
+	for _, list := range []struct {
+		verb      string
+		artifacts []string
+	}{{"produces", produces}, {"consumes", consumes}} {
+		for _, artifact := range list.artifacts {
+			switch {
+			case strings.TrimSpace(artifact) == "":
+				errs = append(errs, fmt.Errorf("task %s %s an artifact with no name", taskID, list.verb))
+			case declared[artifact]:
+				errs = append(errs, fmt.Errorf("task %s declares %s more than once", taskID, artifact))
+			default:
+				declared[artifact] = true
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	task.Produces = append(task.Produces, produces...)
+	task.Consumes = append(task.Consumes, consumes...)
+	return nil
+}
+
+// ResolveArtifactDependencies wires dependencies from artifact declarations.
+// Every task that consumes an artifact depends on all tasks producing it.
+// Artifacts listed in available already exist and need no producer.
+func (tm *TaskManager) ResolveArtifactDependencies(available []string) error {
+	tm.mu.RLock()
+	producers := make(map[string][]string)
+	for id, task := range tm.tasks {
+		for _, artifact := range task.Produces {
+			producers[artifact] = append(producers[artifact], id)
+		}
+	}
+
+	existing := make(map[string]bool)
+	for _, artifact := range available {
+		existing[artifact] = true
+	}
+
+	type edge struct{ taskID, dependsOnID string }
+	var edges []edge
+	var errs []error
+	for id, task := range tm.tasks {
+		for _, artifact := range task.Consumes {
+			ids, produced := producers[artifact]
+			if !produced {
+				if !existing[artifact] {
+					errs = append(errs, fmt.Errorf("task %s consumes %s but no task produces it", id, artifact))
+				}
+				continue
+			}
+			for _, producerID := range ids {
+				if producerID != id {
+					edges = append(edges, edge{taskID: id, dependsOnID: producerID})
+				}
+			}
+		}
+	}
+	tm.mu.RUnlock()
+
+	for _, e := range edges {
+		if tm.hasDependency(e.taskID, e.dependsOnID) {
+			continue
+		}
+		if err := tm.AddDependency(e.taskID, e.dependsOnID); err != nil {
+			errs = append(errs, fmt.Errorf("task %s -> %s: %w", e.taskID, e.dependsOnID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// hasDependency checks if a task already depends on another task
+func (tm *TaskManager) hasDependency(taskID, dependsOnID string) bool {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	if task, exists := tm.tasks[taskID]; exists {
+		for _, dep := range task.Dependencies {
+			if dep == dependsOnID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // GetTask retrieves a task by ID
 func (tm *TaskManager) GetTask(taskID string) (*types.Task, bool) {
 	tm.mu.RLock()
@@ -80,6 +187,25 @@ func (tm *TaskManager) GetTask(taskID string) (*types.Task, bool) {
 	return task, exists
 }
 
+// GetHandoff returns the handoff of a completed task. It is copied under the
+// lock because other workers keep updating task contexts.
+func (tm *TaskManager) GetHandoff(taskID string) (*Handoff, bool) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	task, exists := tm.tasks[taskID]
+	if !exists || task.Status != types.TaskStatusCompleted {
+		return nil, false
+	}
+	return &Handoff{
+		TaskID:     task.ID,
+		TaskType:   task.Type,
+		Files:      limitItems(task.Changes.Files()),
+		Interfaces: splitHandoff(task.Context[ContextHandoffInterfaces]),
+		Notes:      splitHandoff(task.Context[ContextHandoffNotes]),
+	}, true
+}
+
 // GetAllTasks returns all tasks
 func (tm *TaskManager) GetAllTasks() []*types.Task {
 	tm.mu.RLock()
@@ -153,6 +279,51 @@ func (tm *TaskManager) SetTaskResult(taskID string, result string) error {
 	return nil
 }
 
+// SetTaskContext sets a context value of a task
+func (tm *TaskManager) SetTaskContext(taskID, key, value string) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	task, exists := tm.tasks[taskID]
+	if !exists {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+
+	if task.Context == nil {
+		task.Context = make(map[string]string)
+	}
+	task.Context[key] = value
+	return nil
+}
+
+// SetTaskValidation records the validation result of a task
+func (tm *TaskManager) SetTaskValidation(taskID string, result *types.ValidationResult) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	task, exists := tm.tasks[taskID]
+	if !exists {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+
+	task.Validation = result
+	return nil
+}
+
+// SetTaskChanges records the files a task added, modified and deleted
+func (tm *TaskManager) SetTaskChanges(taskID string, changes *types.ChangeSet) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	task, exists := tm.tasks[taskID]
+	if !exists {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+
+	task.Changes = changes
+	return nil
+}
+
 // SetTaskError sets an error for a task
 func (tm *TaskManager) SetTaskError(taskID string, err error) error {
 	tm.mu.Lock()
@@ -0,0 +1,141 @@
+package tasks
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/nohdol/claude-auto/pkg/types"
+	"github.com/rs/zerolog"
+)
+
+func TestDeclareArtifacts(t *testing.T) {
+	tests := []struct {
+		name     string
+		produces []string
+		consumes []string
+		wantErr  string
+	}{
+		{"valid", []string{"api/routes"}, []string{"openapi.yaml"}, ""},
+		{"empty name", []string{" "}, nil, "no name"},
+		{"duplicate produce", []string{"api/routes", "api/routes"}, nil, "more than once"},
+		{"consumes what it produces", []string{"api/routes"}, []string{"api/routes"}, "more than once"},
+		{"already declared", []string{"existing"}, nil, "more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := NewTaskManager(zerolog.Nop())
+			task := tm.CreateTask(types.TaskTypeBackend, 1, "build")
+			if err := tm.DeclareArtifacts(task.ID, []string{"existing"}, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			err := tm.DeclareArtifacts(task.ID, tt.produces, tt.consumes)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("DeclareArtifacts: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("DeclareArtifacts = %v, want an error containing %q", err, tt.wantErr)
+			}
+			// A rejected declaration records nothing
+			if !reflect.DeepEqual(task.Produces, []string{"existing"}) || len(task.Consumes) != 0 {
+				t.Errorf("rejected declaration recorded produces %q, consumes %q", task.Produces, task.Consumes)
+			}
+		})
+	}
+
+	tm := NewTaskManager(zerolog.Nop())
+	if err := tm.DeclareArtifacts("task-404", []string{"x"}, nil); err == nil {
+		t.Error("DeclareArtifacts for an unknown task succeeded")
+	}
+}
+
+func TestResolveArtifactDependencies(t *testing.T) {
+	tm := NewTaskManager(zerolog.Nop())
+	schema := tm.CreateTask(types.TaskTypeDatabase, 1, "schema")
+	api := tm.CreateTask(types.TaskTypeBackend, 2, "api")
+	auth := tm.CreateTask(types.TaskTypeBackend, 2, "auth")
+	tests := tm.CreateTask(types.TaskTypeTesting, 4, "tests")
+
+	declare := func(task *types.Task, produces, consumes []string) {
+		t.Helper()
+		if err := tm.DeclareArtifacts(task.ID, produces, consumes); err != nil {
+			t.Fatal(err)
+		}
+	}
+	declare(schema, []string{"db/schema.sql"}, []string{"project-skeleton"})
+	declare(api, []string{"api/routes"}, []string{"db/schema.sql"})
+	declare(auth, []string{"api/routes"}, []string{"db/schema.sql"})
+	declare(tests, nil, []string{"api/routes"})
+
+	if err := tm.ResolveArtifactDependencies([]string{"project-skeleton"}); err != nil {
+		t.Fatalf("ResolveArtifactDependencies: %v", err)
+	}
+
+	want := map[*types.Task][]string{
+		schema: nil,
+		api:    {schema.ID},
+		auth:   {schema.ID},
+		tests:  {api.ID, auth.ID},
+	}
+	for task, deps := range want {
+		got := append([]string(nil), task.Dependencies...)
+		sort.Strings(got)
+		if len(got) == 0 {
+			got = nil
+		}
+		if !reflect.DeepEqual(got, deps) {
+			t.Errorf("%s depends on %q, want %q", task.Prompt, got, deps)
+		}
+	}
+
+	order, err := tm.GetExecutionOrder()
+	if err != nil {
+		t.Fatalf("GetExecutionOrder: %v", err)
+	}
+	position := make(map[string]int)
+	for i, task := range order {
+		position[task.ID] = i
+	}
+	for _, task := range order {
+		for _, dep := range task.Dependencies {
+			if position[dep] > position[task.ID] {
+				t.Errorf("%s runs before its dependency %s", task.ID, dep)
+			}
+		}
+	}
+
+	// Resolving again adds no duplicate edges
+	if err := tm.ResolveArtifactDependencies([]string{"project-skeleton"}); err != nil {
+		t.Fatalf("second ResolveArtifactDependencies: %v", err)
+	}
+	if len(tests.Dependencies) != 2 {
+		t.Errorf("tests depends on %q after resolving twice", tests.Dependencies)
+	}
+}
+
+func TestResolveArtifactDependenciesErrors(t *testing.T) {
+	tm := NewTaskManager(zerolog.Nop())
+	first := tm.CreateTask(types.TaskTypeBackend, 1, "first")
+	second := tm.CreateTask(types.TaskTypeBackend, 1, "second")
+	orphan := tm.CreateTask(types.TaskTypeFrontend, 1, "orphan")
+
+	tm.DeclareArtifacts(first.ID, []string{"a"}, []string{"b"})
+	tm.DeclareArtifacts(second.ID, []string{"b"}, []string{"a"})
+	tm.DeclareArtifacts(orphan.ID, nil, []string{"missing"})
+
+	err := tm.ResolveArtifactDependencies(nil)
+	if err == nil {
+		t.Fatal("ResolveArtifactDependencies with a cycle and a missing producer succeeded")
+	}
+	for _, want := range []string{"no task produces it", "cycle"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("ResolveArtifactDependencies = %v, missing %q", err, want)
+		}
+	}
+}
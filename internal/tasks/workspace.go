@@ -0,0 +1,291 @@
+package tasks
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nohdol/claude-auto/internal/core"
+	"github.com/nohdol/claude-auto/internal/git"
+	"github.com/nohdol/claude-auto/internal/validation"
+	"github.com/nohdol/claude-auto/pkg/types"
+)
+
+// ContextMergeFor is the context key linking a merge-resolution task to the task it merges
+const ContextMergeFor = "merge_for"
+
+// taskBranchPrefix prefixes the branches tasks run on in their worktrees
+const taskBranchPrefix = "claude-auto/task/"
+
+// workspace is the git worktree a task runs in when tasks are isolated
+type workspace struct {
+	dir        string
+	branch     string
+	gitManager *git.GitManager
+}
+
+// SetWorkDir sets the project directory Claude runs in
+func (pe *ParallelExecutor) SetWorkDir(dir string) {
+	pe.workDir = dir
+}
+
+// SetWorktrees enables running each task in its own git worktree.
+// Tasks branch from the current integration point and are merged back when they
+// complete, so parallel tasks never edit the same directory. It needs a git manager
+// and more than one worker; commits follow the commit size on each task branch,
+// with medium commits falling back to one per task.
+func (pe *ParallelExecutor) SetWorktrees(enabled bool) {
+	pe.worktrees = enabled
+}
+
+// isolated reports whether tasks run in their own worktrees
+func (pe *ParallelExecutor) isolated() bool {
+	return pe.worktrees && pe.gitManager != nil && pe.maxWorkers > 1
+}
+
+// openWorkspace creates a worktree for a task on a new branch from HEAD
+func (pe *ParallelExecutor) openWorkspace(task *types.Task) (*workspace, error) {
+	dir, err := os.MkdirTemp("", "claude-auto-"+task.ID+"-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+
+	branch := taskBranchPrefix + task.ID
+
+	// HEAD must not move while the task branches from it
+	pe.commitMu.Lock()
+	gm, err := pe.gitManager.AddWorktree(dir, branch, "")
+	pe.commitMu.Unlock()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	// Installed dependencies are not tracked, so the worktree shares the project's
+	if modules := filepath.Join(pe.gitManager.GetProjectDir(), "node_modules"); isDir(modules) {
+		if err := os.Symlink(modules, filepath.Join(dir, "node_modules")); err != nil {
+			pe.logger.Debug().Err(err).Str("task_id", task.ID).Msg("Failed to link node_modules into worktree")
+		}
+	}
+
+	return &workspace{dir: dir, branch: branch, gitManager: gm}, nil
+}
+
+// workspaceValidator returns a validator running the gates in a task worktree
+func (pe *ParallelExecutor) workspaceValidator(ws *workspace) *validation.Validator {
+	rel, err := filepath.Rel(pe.gitManager.GetProjectDir(), pe.validator.Dir())
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = "."
+	}
+	return pe.validator.WithDir(filepath.Join(ws.dir, rel))
+}
+
+// isDir reports whether path is a directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// closeWorkspace removes a task worktree and its branch
+func (pe *ParallelExecutor) closeWorkspace(ws *workspace) {
+	pe.commitMu.Lock()
+	defer pe.commitMu.Unlock()
+
+	if err := pe.gitManager.RemoveWorktree(ws.dir, ws.branch); err != nil {
+		pe.logger.Warn().Err(err).Str("branch", ws.branch).Msg("Failed to remove worktree")
+	}
+	os.RemoveAll(ws.dir)
+}
+
+// integrate commits a task's changes on its branch and merges the branch back.
+// Conflicts are handed to a merge-resolution task; when that fails the merge is
+// aborted and the task runs again serially in the project directory. It reports
+// whether the validation gates already ran on a serial re-execution.
+func (pe *ParallelExecutor) integrate(ctx context.Context, task *types.Task, ws *workspace, before git.Snapshot, options *core.ClaudeOptions) (bool, error) {
+	changes, _ := pe.recordChanges(task, ws.gitManager, before)
+	if changes.IsEmpty() {
+		return false, nil
+	}
+
+	files := changes.Files()
+	if len(pe.commitFiles(ws.gitManager, task, files, pe.commitMessages(ws.gitManager, task, files))) == 0 {
+		return false, fmt.Errorf("failed to commit task changes on %s", ws.branch)
+	}
+
+	// Only one task merges at a time; the commit lock is held for git operations
+	// alone, so other tasks open and close worktrees while Claude resolves a merge
+	pe.integrateMu.Lock()
+	defer pe.integrateMu.Unlock()
+
+	pe.commitMu.Lock()
+	result, err := pe.gitManager.MergeBranch(ws.branch, false)
+	pe.commitMu.Unlock()
+	if err == nil {
+		pe.markMerged(changes)
+		return false, nil
+	}
+
+	if err != git.ErrMergeConflict {
+		// Nothing is in progress, e.g. local changes would be overwritten
+		pe.logger.Warn().Err(err).Str("task_id", task.ID).Msg("Failed to merge task branch")
+		return pe.reexecute(ctx, task, options)
+	}
+
+	pe.logger.Warn().
+		Str("task_id", task.ID).
+		Strs("conflicts", result.Conflicts).
+		Msg("Merge conflict, spawning merge-resolution task")
+
+	if pe.resolveConflicts(ctx, task, result, options) {
+		pe.markMerged(changes)
+		return false, nil
+	}
+
+	pe.commitMu.Lock()
+	err = pe.gitManager.AbortMerge()
+	pe.commitMu.Unlock()
+	if err != nil {
+		return false, err
+	}
+	return pe.reexecute(ctx, task, options)
+}
+
+// resolveConflicts runs a task resolving the conflicts of a merge in progress
+// and concludes the merge when no conflict markers remain
+func (pe *ParallelExecutor) resolveConflicts(ctx context.Context, task *types.Task, result *git.MergeResult, options *core.ClaudeOptions) bool {
+	mergeTask := pe.taskManager.CreateTask(task.Type, task.Priority, buildMergePrompt(task, result))
+	pe.taskManager.SetTaskContext(mergeTask.ID, ContextMergeFor, task.ID)
+	pe.taskManager.UpdateTaskStatus(mergeTask.ID, types.TaskStatusInProgress)
+
+	mergeOptions := *options
+	mergeOptions.WorkDir = pe.gitManager.GetProjectDir()
+
+	response, err := pe.claudeExecutor.Execute(ctx, mergeTask.Prompt, &mergeOptions)
+	if err != nil {
+		// A failed run may have resolved only some conflicts; the caller aborts
+		pe.taskManager.SetTaskError(mergeTask.ID, err)
+		return false
+	}
+	pe.taskManager.SetTaskResult(mergeTask.ID, response.Output)
+
+	if unresolved := pe.unresolvedFiles(result.Conflicts); len(unresolved) > 0 {
+		pe.taskManager.SetTaskError(mergeTask.ID, fmt.Errorf("conflict markers remain in %s", strings.Join(unresolved, ", ")))
+		return false
+	}
+
+	pe.commitMu.Lock()
+	err = pe.gitManager.ContinueMerge(result.Conflicts)
+	pe.commitMu.Unlock()
+	if err != nil {
+		pe.taskManager.SetTaskError(mergeTask.ID, err)
+		return false
+	}
+
+	pe.taskManager.UpdateTaskStatus(mergeTask.ID, types.TaskStatusCompleted)
+	return true
+}
+
+// reexecute runs a task again in the project directory, validates it and commits
+// its changes. The caller holds the integration lock, so no other task merges
+// meanwhile. It reports whether the gates ran.
+func (pe *ParallelExecutor) reexecute(ctx context.Context, task *types.Task, options *core.ClaudeOptions) (bool, error) {
+	pe.logger.Warn().Str("task_id", task.ID).Msg("Re-executing task serially")
+
+	serialOptions := *options
+	serialOptions.WorkDir = taskDir(pe.gitManager.GetProjectDir(), task)
+
+	before := pe.takeSnapshot(pe.gitManager)
+	response, err := pe.claudeExecutor.Execute(ctx, pe.buildPrompt(task), &serialOptions)
+	if err != nil {
+		// Only this run changes the project directory while the integration
+		// lock is held, so its partial changes are thrown away
+		if before != nil {
+			pe.gitManager.DiscardSince(before, "the failed re-execution")
+		}
+		return false, fmt.Errorf("serial re-execution failed: %w", err)
+	}
+	pe.taskManager.SetTaskResult(task.ID, response.Output)
+
+	// The serial run never went through the worktree's validation
+	validated := pe.validator != nil
+	if validated {
+		if err := pe.runGates(ctx, task, &serialOptions, pe.validator); err != nil {
+			if pe.discardTask(task, before) {
+				pe.setBaseline(pe.validator.Validate(ctx))
+			}
+			return true, err
+		}
+	}
+
+	changes, after := pe.recordChanges(task, pe.gitManager, before)
+	if changes.IsEmpty() {
+		return validated, nil
+	}
+
+	pe.commitMu.Lock()
+	files := pe.uncommittedFiles(changes, after)
+	pe.commitMu.Unlock()
+	messages := pe.commitMessages(pe.gitManager, task, files)
+
+	pe.commitMu.Lock()
+	defer pe.commitMu.Unlock()
+	pe.markCommitted(pe.commitFiles(pe.gitManager, task, files, messages), after)
+	return validated, nil
+}
+
+// markMerged records the content of files merged into the project directory
+func (pe *ParallelExecutor) markMerged(changes *types.ChangeSet) {
+	if after := pe.takeSnapshot(pe.gitManager); after != nil {
+		pe.commitMu.Lock()
+		defer pe.commitMu.Unlock()
+		pe.markCommitted(changes.Files(), after)
+	}
+}
+
+// unresolvedFiles returns the files that still contain conflict markers
+func (pe *ParallelExecutor) unresolvedFiles(files []string) []string {
+	var unresolved []string
+	for _, file := range files {
+		if hasConflictMarkers(filepath.Join(pe.gitManager.GetProjectDir(), filepath.FromSlash(file))) {
+			unresolved = append(unresolved, file)
+		}
+	}
+	return unresolved
+}
+
+// hasConflictMarkers checks if a file contains merge conflict markers
+func hasConflictMarkers(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false // Deleted while resolving
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "<<<<<<< ") || strings.HasPrefix(line, ">>>>>>> ") {
+			return true
+		}
+	}
+	return false
+}
+
+// buildMergePrompt builds the prompt for a task resolving merge conflicts
+func buildMergePrompt(task *types.Task, result *git.MergeResult) string {
+	var b strings.Builder
+	b.WriteString("병렬로 실행된 작업의 브랜치를 병합하는 중 충돌이 발생했습니다.\n")
+	b.WriteString("다음 파일의 충돌 표시(<<<<<<<, =======, >>>>>>>)를 제거하고 양쪽 변경 사항이 모두 반영되도록 해결해주세요.\n")
+	b.WriteString("git 명령은 실행하지 마세요.\n\n")
+
+	for _, file := range result.Conflicts {
+		fmt.Fprintf(&b, "- %s\n", file)
+	}
+
+	fmt.Fprintf(&b, "\n병합 중인 작업 (%s):\n%s\n", result.Branch, task.Prompt)
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package templates
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/nohdol/claude-auto/pkg/types"
+)
+
+// Preset represents a vetted stack with a deterministic file skeleton
+type Preset struct {
+	Name         string
+	Description  string
+	Type         string // web|api|cli|mobile
+	Architecture types.ProjectArchitecture
+	HasFrontend  bool
+	HasBackend   bool
+	HasDatabase  bool
+	Files        []FileTemplate
+	// PromptFragments are appended to task prompts of the matching type so
+	// Claude follows the conventions of the skeleton instead of inventing new ones
+	PromptFragments map[types.TaskType]string
+}
+
+// FileTemplate represents a single file of a preset skeleton.
+// Both Path and Content are rendered with text/template.
+type FileTemplate struct {
+	Path       string
+	Content    string
+	Executable bool
+}
+
+// TemplateData is the data passed to every file template.
+// ProjectName and Description are free text; Slug is the project name reduced
+// to lowercase letters, digits and dashes for package names, database names
+// and other identifiers. Templates quote free text with the json function.
+type TemplateData struct {
+	ProjectName string
+	Description string
+	Slug        string
+	ModulePath  string
+}
+
+var registry = make(map[string]*Preset)
+
+// register adds a preset to the registry
+func register(p *Preset) {
+	registry[p.Name] = p
+}
+
+// Get returns the preset with the given name
+func Get(name string) (*Preset, bool) {
+	p, exists := registry[strings.ToLower(strings.TrimSpace(name))]
+	return p, exists
+}
+
+// List returns all registered presets sorted by name
+func List() []*Preset {
+	presets := make([]*Preset, 0, len(registry))
+	for _, p := range registry {
+		presets = append(presets, p)
+	}
+	sort.Slice(presets, func(i, j int) bool {
+		return presets[i].Name < presets[j].Name
+	})
+	return presets
+}
+
+// Names returns the names of all registered presets
+func Names() []string {
+	var names []string
+	for _, p := range List() {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// PromptFragment returns the preset-specific prompt fragment for a task type
+func (p *Preset) PromptFragment(taskType types.TaskType) string {
+	if p == nil || p.PromptFragments == nil {
+		return ""
+	}
+	return p.PromptFragments[taskType]
+}
+
+// ApplyTo pins the architecture and component flags of an idea to the preset
+func (p *Preset) ApplyTo(idea *types.ProcessedIdea) {
+	idea.Type = p.Type
+	idea.Architecture = p.Architecture
+	idea.HasFrontend = p.HasFrontend
+	idea.HasBackend = p.HasBackend
+	idea.HasDatabase = p.HasDatabase
+}
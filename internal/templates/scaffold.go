@@ -0,0 +1,114 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Scaffold lays down the preset skeleton in projectDir.
+// Existing files are never overwritten; the paths that were written are
+// returned relative to projectDir in a deterministic order.
+func Scaffold(projectDir string, p *Preset, data TemplateData) ([]string, error) {
+	if data.Slug == "" {
+		data.Slug = Slug(data.ProjectName)
+	}
+	if data.Slug == "" {
+		data.Slug = Slug(filepath.Base(projectDir))
+	}
+	if data.Slug == "" {
+		data.Slug = "app"
+	}
+	if data.ModulePath == "" {
+		data.ModulePath = data.Slug
+	}
+
+	files := make([]FileTemplate, len(p.Files))
+	copy(files, p.Files)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	var written []string
+	for _, file := range files {
+		relPath, err := render(file.Path, file.Path, data)
+		if err != nil {
+			return written, err
+		}
+		relPath = filepath.Clean(filepath.FromSlash(strings.TrimSpace(relPath)))
+		if !filepath.IsLocal(relPath) {
+			return written, fmt.Errorf("template %s renders to %s outside the project", file.Path, relPath)
+		}
+
+		fullPath := filepath.Join(projectDir, relPath)
+		if _, err := os.Stat(fullPath); err == nil {
+			continue // Keep whatever the user already has
+		}
+
+		content, err := render(file.Path, file.Content, data)
+		if err != nil {
+			return written, err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return written, fmt.Errorf("failed to create directory for %s: %w", relPath, err)
+		}
+
+		mode := os.FileMode(0644)
+		if file.Executable {
+			mode = 0755
+		}
+		if err := os.WriteFile(fullPath, []byte(content), mode); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", relPath, err)
+		}
+
+		written = append(written, relPath)
+	}
+
+	return written, nil
+}
+
+// Slug reduces a name to lowercase letters, digits and single dashes.
+// It returns "" when the name has none of them.
+func Slug(name string) string {
+	var b strings.Builder
+	for _, ch := range strings.ToLower(name) {
+		switch {
+		case (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9'):
+			b.WriteRune(ch)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteRune('-')
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
+
+// funcs are the functions available to file templates
+var funcs = template.FuncMap{
+	// json quotes a value as a JSON literal, which is also a valid JavaScript,
+	// TypeScript and Python string literal
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// render executes a single template string with the given data
+func render(name, text string, data TemplateData) (string, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,295 @@
+package validation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nohdol/claude-auto/pkg/types"
+	"github.com/rs/zerolog"
+)
+
+// CheckKind identifies what a validation gate verifies
+type CheckKind string
+
+const (
+	CheckKindBuild     CheckKind = "build"
+	CheckKindLint      CheckKind = "lint"
+	CheckKindTypeCheck CheckKind = "typecheck"
+	CheckKindTest      CheckKind = "test"
+)
+
+// maxMessageSize limits the command output kept in a Check message
+const maxMessageSize = 4000
+
+// CheckCommand represents a command run as a validation gate
+type CheckCommand struct {
+	Kind CheckKind
+	Name string
+	Args []string
+}
+
+// ShellCheck returns a check running a command line through the shell
+func ShellCheck(kind CheckKind, name, command string) CheckCommand {
+	if runtime.GOOS == "windows" {
+		return CheckCommand{Kind: kind, Name: name, Args: []string{"cmd", "/C", command}}
+	}
+	return CheckCommand{Kind: kind, Name: name, Args: []string{"sh", "-c", command}}
+}
+
+// String returns the command line of the check
+func (c CheckCommand) String() string {
+	return strings.Join(c.Args, " ")
+}
+
+// Validator detects and runs the build, lint, type-check and test gates of a project
+type Validator struct {
+	projectDir string
+	timeout    time.Duration
+	logger     zerolog.Logger
+	// Gates share the project directory, so runs are serialized
+	mu sync.Mutex
+}
+
+// NewValidator creates a new validator for a project directory
+func NewValidator(projectDir string, timeout time.Duration, logger zerolog.Logger) *Validator {
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+
+	return &Validator{
+		projectDir: projectDir,
+		timeout:    timeout,
+		logger:     logger,
+	}
+}
+
+// Dir returns the directory the checks run in
+func (v *Validator) Dir() string {
+	return v.projectDir
+}
+
+// WithDir returns a validator running the checks in another directory, such
+// as a worktree of the project
+func (v *Validator) WithDir(dir string) *Validator {
+	return NewValidator(dir, v.timeout, v.logger)
+}
+
+// DetectChecks detects the validation commands available for the project
+func (v *Validator) DetectChecks() []CheckCommand {
+	var checks []CheckCommand
+
+	if v.exists("go.mod") && v.hasTool("go") {
+		checks = append(checks,
+			CheckCommand{Kind: CheckKindBuild, Name: "go build", Args: []string{"go", "build", "./..."}},
+			CheckCommand{Kind: CheckKindLint, Name: "go vet", Args: []string{"go", "vet", "./..."}},
+			CheckCommand{Kind: CheckKindTest, Name: "go test", Args: []string{"go", "test", "./..."}},
+		)
+	}
+
+	if v.exists("package.json") && v.hasTool("npm") {
+		scripts := v.packageScripts()
+		if _, ok := scripts["build"]; ok {
+			checks = append(checks, CheckCommand{Kind: CheckKindBuild, Name: "npm run build", Args: []string{"npm", "run", "build"}})
+		}
+		if _, ok := scripts["lint"]; ok {
+			checks = append(checks, CheckCommand{Kind: CheckKindLint, Name: "npm run lint", Args: []string{"npm", "run", "lint"}})
+		}
+		if v.exists("tsconfig.json") && v.exists(filepath.Join("node_modules", ".bin", "tsc")) {
+			checks = append(checks, CheckCommand{Kind: CheckKindTypeCheck, Name: "tsc", Args: []string{"npx", "--no-install", "tsc", "--noEmit"}})
+		}
+		if test, ok := scripts["test"]; ok && !strings.Contains(test, "no test specified") {
+			checks = append(checks, CheckCommand{Kind: CheckKindTest, Name: "npm test", Args: []string{"npm", "test"}})
+		}
+	}
+
+	if v.exists("requirements.txt") || v.exists("pyproject.toml") {
+		if v.hasTool("ruff") {
+			checks = append(checks, CheckCommand{Kind: CheckKindLint, Name: "ruff", Args: []string{"ruff", "check", "."}})
+		}
+		if v.hasTool("mypy") {
+			checks = append(checks, CheckCommand{Kind: CheckKindTypeCheck, Name: "mypy", Args: []string{"mypy", "."}})
+		}
+		if v.hasTool("pytest") {
+			checks = append(checks, CheckCommand{Kind: CheckKindTest, Name: "pytest", Args: []string{"pytest", "-q"}})
+		}
+	}
+
+	if v.exists("Cargo.toml") && v.hasTool("cargo") {
+		checks = append(checks,
+			CheckCommand{Kind: CheckKindBuild, Name: "cargo build", Args: []string{"cargo", "build"}},
+			CheckCommand{Kind: CheckKindLint, Name: "cargo clippy", Args: []string{"cargo", "clippy"}},
+			CheckCommand{Kind: CheckKindTest, Name: "cargo test", Args: []string{"cargo", "test"}},
+		)
+	}
+
+	return checks
+}
+
+// Validate runs all detected gates and records a Check for each
+func (v *Validator) Validate(ctx context.Context) *types.ValidationResult {
+	return v.Run(ctx, v.DetectChecks())
+}
+
+// Run runs the given gates and records a Check for each
+func (v *Validator) Run(ctx context.Context, checks []CheckCommand) *types.ValidationResult {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	result := &types.ValidationResult{
+		Passed: true,
+		Checks: []types.Check{},
+		Errors: []string{},
+	}
+
+	for _, check := range checks {
+		output, err := v.runCommand(ctx, check)
+
+		c := types.Check{
+			Name:       check.Name,
+			Passed:     err == nil,
+			Message:    tail(output, maxMessageSize),
+			CanAutoFix: true, // Failing gates are handed to a fix task
+		}
+		if err != nil {
+			c.FailingTests = FailingTests(output)
+			result.Passed = false
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", check.Name, err))
+		}
+		result.Checks = append(result.Checks, c)
+
+		v.logger.Debug().
+			Str("check", check.Name).
+			Bool("passed", c.Passed).
+			Msg("Validation check finished")
+	}
+
+	return result
+}
+
+// runCommand runs a single gate command in the project directory
+func (v *Validator) runCommand(ctx context.Context, check CheckCommand) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, check.Args[0], check.Args[1:]...)
+	cmd.Dir = v.projectDir
+	cmd.Env = append(os.Environ(), "CI=true")
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err := cmd.Run()
+	return output.String(), err
+}
+
+// exists checks if a file exists relative to the project directory
+func (v *Validator) exists(name string) bool {
+	_, err := os.Stat(filepath.Join(v.projectDir, name))
+	return err == nil
+}
+
+// hasTool checks if a command is available on PATH
+func (v *Validator) hasTool(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// packageScripts reads the scripts section of package.json
+func (v *Validator) packageScripts() map[string]string {
+	content, err := os.ReadFile(filepath.Join(v.projectDir, "package.json"))
+	if err != nil {
+		return nil
+	}
+
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil
+	}
+	return pkg.Scripts
+}
+
+// FailedChecks returns the checks that did not pass
+func FailedChecks(result *types.ValidationResult) []types.Check {
+	var failed []types.Check
+	for _, check := range result.Checks {
+		if !check.Passed {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
+
+// failureMarkers start the lines test runners print for a failing test
+var failureMarkers = []string{"--- FAIL: ", "FAIL\t", "FAILED ", "✕ ", "● ", "---- "}
+
+// failureSuffixes start what runners print after the name of a failing test:
+// timings, build failures, pytest messages and cargo output headers. Names
+// may contain spaces, so they are cut there rather than at the first space.
+var failureSuffixes = []string{"\t", " (", " [", " - ", " stdout ---"}
+
+// FailingTests returns the failing tests reported in the output of a check,
+// without timings so runs compare equal
+func FailingTests(output string) []string {
+	var found []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		for _, marker := range failureMarkers {
+			if !strings.HasPrefix(line, marker) {
+				continue
+			}
+			name := strings.TrimPrefix(line, marker)
+			for _, suffix := range failureSuffixes {
+				if i := strings.Index(name, suffix); i != -1 {
+					name = name[:i]
+				}
+			}
+			if name = strings.TrimSpace(name); name != "" {
+				found = append(found, name)
+			}
+			break
+		}
+	}
+	return found
+}
+
+// BuildFixPrompt builds the prompt for a task fixing failed gates
+func BuildFixPrompt(original string, failed []types.Check) string {
+	var b strings.Builder
+	b.WriteString("이전 작업 후 다음 검증 단계가 실패했습니다. 오류를 수정해주세요.\n")
+	b.WriteString("테스트를 삭제하거나 검증을 우회하지 말고 원인을 수정하세요.\n")
+
+	for _, check := range failed {
+		fmt.Fprintf(&b, "\n### %s\n```\n%s\n```\n", check.Name, check.Message)
+	}
+
+	if original != "" {
+		fmt.Fprintf(&b, "\n원래 작업:\n%s\n", original)
+	}
+	return b.String()
+}
+
+// tail returns the last n bytes of the output, starting on a rune boundary
+func tail(output string, n int) string {
+	output = strings.TrimSpace(output)
+	if len(output) <= n {
+		return output
+	}
+	start := len(output) - n
+	for start < len(output) && !utf8.RuneStart(output[start]) {
+		start++
+	}
+	return "..." + output[start:]
+}
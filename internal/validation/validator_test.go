@@ -0,0 +1,195 @@
+package validation
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog"
+)
+
+func TestTail(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		n      int
+		want   string
+	}{
+		{"short", "  ok  \n", 10, "ok"},
+		{"ascii", "0123456789", 4, "...6789"},
+		// Each Korean rune is three bytes; the cut moves forward to a rune start
+		{"rune boundary", "실패했습니다", 4, "...다"},
+		{"on a rune start", "실패했습니다", 6, "...니다"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tail(tt.output, tt.n)
+			if got != tt.want {
+				t.Errorf("tail(%q, %d) = %q, want %q", tt.output, tt.n, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("tail(%q, %d) = %q is not valid UTF-8", tt.output, tt.n, got)
+			}
+		})
+	}
+
+	long := strings.Repeat("오류 ", 2000)
+	for n := maxMessageSize; n < maxMessageSize+3; n++ {
+		if got := tail(long, n); !utf8.ValidString(got) || len(got) > n+len("...") {
+			t.Errorf("tail of %d bytes is %d bytes or invalid UTF-8", n, len(got))
+		}
+	}
+}
+
+// writeProject writes files into a new project directory
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// checkNames returns the names of checks
+func checkNames(checks []CheckCommand) []string {
+	var names []string
+	for _, check := range checks {
+		names = append(names, check.Name)
+	}
+	return names
+}
+
+func TestDetectChecks(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{"no manifest", map[string]string{"main.c": "int main() {}"}, nil},
+		{"go module", map[string]string{"go.mod": "module example.com/x\n"}, []string{"go build", "go vet", "go test"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewValidator(writeProject(t, tt.files), time.Minute, zerolog.Nop())
+			if got := checkNames(v.DetectChecks()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectChecks = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectChecksPackageScripts(t *testing.T) {
+	if _, err := exec.LookPath("npm"); err != nil {
+		t.Skip("npm is not installed")
+	}
+
+	dir := writeProject(t, map[string]string{
+		"package.json":  `{"scripts":{"build":"tsc","lint":"eslint .","test":"echo \"Error: no test specified\" && exit 1"}}`,
+		"tsconfig.json": "{}",
+	})
+	v := NewValidator(dir, time.Minute, zerolog.Nop())
+
+	// tsc is only run when installed, and the npm init placeholder test is skipped
+	want := []string{"npm run build", "npm run lint"}
+	if got := checkNames(v.DetectChecks()); !reflect.DeepEqual(got, want) {
+		t.Errorf("DetectChecks = %q, want %q", got, want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("checks run through sh")
+	}
+
+	v := NewValidator(t.TempDir(), time.Minute, zerolog.Nop())
+	result := v.Run(context.Background(), []CheckCommand{
+		ShellCheck(CheckKindBuild, "build", "echo built"),
+		ShellCheck(CheckKindTest, "test", "echo '--- FAIL: TestLogin (0.01s)'; exit 1"),
+	})
+
+	if result.Passed {
+		t.Fatal("Run passed with a failing check")
+	}
+	if len(result.Checks) != 2 || !result.Checks[0].Passed || result.Checks[1].Passed {
+		t.Fatalf("Run checks = %+v", result.Checks)
+	}
+	if result.Checks[0].Message != "built" {
+		t.Errorf("passing check message = %q", result.Checks[0].Message)
+	}
+	if got := result.Checks[1].FailingTests; !reflect.DeepEqual(got, []string{"TestLogin"}) {
+		t.Errorf("FailingTests = %q, want TestLogin", got)
+	}
+
+	failed := FailedChecks(result)
+	if len(failed) != 1 || failed[0].Name != "test" {
+		t.Errorf("FailedChecks = %+v", failed)
+	}
+
+	prompt := BuildFixPrompt("로그인 추가", failed)
+	for _, want := range []string{"### test", "--- FAIL: TestLogin", "원래 작업:\n로그인 추가"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("BuildFixPrompt is missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestFailingTests(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{
+			name:   "go",
+			output: "=== RUN   TestAdd\n    --- FAIL: TestAdd/negative (0.00s)\n--- FAIL: TestAdd (0.01s)\nFAIL\nFAIL\texample.com/calc\t0.012s\nFAIL\texample.com/cmd [build failed]\n",
+			want:   []string{"TestAdd/negative", "TestAdd", "example.com/calc", "example.com/cmd"},
+		},
+		{
+			name:   "pytest",
+			output: "FAILED tests/test_calc.py::test_add - AssertionError: 3 != 4\n1 failed, 2 passed",
+			want:   []string{"tests/test_calc.py::test_add"},
+		},
+		{
+			name:   "jest",
+			output: "  ✕ adds numbers (3 ms)\n  ✓ subtracts\n  ● Calculator › adds numbers\n",
+			want:   []string{"adds numbers", "Calculator › adds numbers"},
+		},
+		{
+			name:   "cargo",
+			output: "failures:\n\n---- tests::adds stdout ----\n",
+			want:   []string{"tests::adds"},
+		},
+		{
+			name:   "passing",
+			output: "ok  \texample.com/calc\t0.010s\nPASS",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FailingTests(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FailingTests = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -14,6 +14,9 @@ const (
 	TaskTypeTesting       TaskType = "testing"
 	TaskTypeDocumentation TaskType = "documentation"
 	TaskTypeDevOps        TaskType = "devops"
+	TaskTypeReview        TaskType = "review"
+	TaskTypeFix           TaskType = "fix"
+	TaskTypeRefactor      TaskType = "refactor"
 )
 
 // TaskStatus represents the status of a task
@@ -35,14 +38,44 @@ type Task struct {
 	Prompt       string            `json:"prompt"`
 	Context      map[string]string `json:"context"`
 	Dependencies []string          `json:"dependencies"`
+	Produces     []string          `json:"produces,omitempty"` // artifacts this task creates
+	Consumes     []string          `json:"consumes,omitempty"` // artifacts this task needs
 	Status       TaskStatus        `json:"status"`
 	Result       string            `json:"result"`
 	Error        error             `json:"error,omitempty"`
 	RetryCount   int               `json:"retry_count"`
+	Validation   *ValidationResult `json:"validation,omitempty"`
+	Changes      *ChangeSet        `json:"changes,omitempty"`
+	WorkDir      string            `json:"work_dir,omitempty"` // directory relative to the project, for tasks of one package
 	CreatedAt    time.Time         `json:"created_at"`
 	CompletedAt  *time.Time        `json:"completed_at,omitempty"`
 }
 
+// ChangeSet represents the files a task added, modified and deleted,
+// relative to the project directory
+type ChangeSet struct {
+	Added    []string `json:"added"`
+	Modified []string `json:"modified"`
+	Deleted  []string `json:"deleted"`
+}
+
+// Files returns every changed path
+func (cs *ChangeSet) Files() []string {
+	if cs == nil {
+		return nil
+	}
+	files := make([]string, 0, len(cs.Added)+len(cs.Modified)+len(cs.Deleted))
+	files = append(files, cs.Added...)
+	files = append(files, cs.Modified...)
+	files = append(files, cs.Deleted...)
+	return files
+}
+
+// IsEmpty reports whether nothing changed
+func (cs *ChangeSet) IsEmpty() bool {
+	return cs == nil || len(cs.Added)+len(cs.Modified)+len(cs.Deleted) == 0
+}
+
 // ProcessedIdea represents a processed project idea
 type ProcessedIdea struct {
 	Name         string            `json:"name"`
@@ -92,14 +125,15 @@ type ProjectPhase struct {
 
 // ExecutionReport represents the result of task execution
 type ExecutionReport struct {
-	TotalTasks      int           `json:"total_tasks"`
-	CompletedTasks  int           `json:"completed_tasks"`
-	FailedTasks     int           `json:"failed_tasks"`
-	SkippedTasks    int           `json:"skipped_tasks"`
-	Duration        time.Duration `json:"duration"`
-	Tasks           []*Task       `json:"tasks"`
-	StartTime       time.Time     `json:"start_time"`
-	EndTime         time.Time     `json:"end_time"`
+	TotalTasks     int               `json:"total_tasks"`
+	CompletedTasks int               `json:"completed_tasks"`
+	FailedTasks    int               `json:"failed_tasks"`
+	SkippedTasks   int               `json:"skipped_tasks"`
+	Duration       time.Duration     `json:"duration"`
+	Tasks          []*Task           `json:"tasks"`
+	StartTime      time.Time         `json:"start_time"`
+	EndTime        time.Time         `json:"end_time"`
+	Validation     *ValidationResult `json:"validation,omitempty"`
 }
 
 // CommitSize represents the size of commits
@@ -125,20 +159,39 @@ type Check struct {
 	Message     string `json:"message"`
 	CanAutoFix  bool   `json:"can_auto_fix"`
 	AutoFixed   bool   `json:"auto_fixed"`
+	// FailingTests are the tests a failed check reported, parsed from the full
+	// output before the message was shortened
+	FailingTests []string `json:"failing_tests,omitempty"`
 }
 
 // ProgressDocument represents a progress report
 type ProgressDocument struct {
-	Date            time.Time        `json:"date"`
-	Phase           string           `json:"phase"`
-	CompletedTasks  []TaskSummary    `json:"completed_tasks"`
-	InProgressTasks []TaskSummary    `json:"in_progress_tasks"`
-	Progress        float64          `json:"progress"`
-	LinesOfCode     int              `json:"lines_of_code"`
-	CommitCount     int              `json:"commit_count"`
-	TestCoverage    float64          `json:"test_coverage"`
-	APIKeys         []APIKeyStatus   `json:"api_keys"`
-	NextSteps       []string         `json:"next_steps"`
+	Date              time.Time      `json:"date"`
+	Phase             string         `json:"phase"`
+	CompletedTasks    []TaskSummary  `json:"completed_tasks"`
+	InProgressTasks   []TaskSummary  `json:"in_progress_tasks"`
+	Progress          float64        `json:"progress"`
+	LinesOfCode       int            `json:"lines_of_code"`
+	LinesByLanguage   map[string]int `json:"lines_by_language,omitempty"`
+	CommitCount       int            `json:"commit_count"`
+	TestCoverage      float64        `json:"test_coverage"`
+	HasPrevious       bool           `json:"has_previous"`
+	LinesOfCodeDelta  int            `json:"lines_of_code_delta"`
+	CommitCountDelta  int            `json:"commit_count_delta"`
+	TestCoverageDelta float64        `json:"test_coverage_delta"`
+	APIKeys           []APIKeyStatus `json:"api_keys"`
+	NextSteps         []string       `json:"next_steps"`
+}
+
+// ProjectMetrics represents a point-in-time measurement of a project
+type ProjectMetrics struct {
+	Timestamp       time.Time      `json:"timestamp"`
+	LinesOfCode     int            `json:"lines_of_code"`
+	LinesByLanguage map[string]int `json:"lines_by_language"`
+	CommitCount     int            `json:"commit_count"`
+	TestCoverage    float64        `json:"test_coverage"`
+	CoverageSources []string       `json:"coverage_sources,omitempty"`
+	BuildTime       time.Duration  `json:"build_time,omitempty"`
 }
 
 // TaskSummary represents a summary of a task for reporting